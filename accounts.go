@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+)
+
+// accountSession 账号会话，每个账号使用独立的浏览器（cookies、代理）和页面池
+type accountSession struct {
+	name    string
+	browser *browser.Supervisor // 账号浏览器的守护，浏览器崩溃时自动重启
+	pages   *browser.PagePool   // 页面池，限制同时打开的页面数量并复用页面
+	cookies cookies.Cookier     // 账号的 cookies 存储，动作成功后保存刷新过的 cookies
+
+	ready chan struct{} // 浏览器启动完成（无论成功与否）后关闭
+	err   error
+}
+
+// AccountInfo 账号信息
+type AccountInfo struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	Proxy   string `json:"proxy,omitempty"`
+	// CookiesPath cookies 文件路径，memory 后端时为空
+	CookiesPath string `json:"cookies_path,omitempty"`
+	// Running 账号的浏览器是否已经启动，浏览器在账号第一次执行动作时启动
+	Running bool                     `json:"running"`
+	Browser *browser.SupervisorStats `json:"browser,omitempty"`
+	// Session 会话健康状态：最近一次检查到的登录状态、登录 cookie 的过期时间和告警
+	Session *SessionHealth `json:"session,omitempty"`
+}
+
+// AccountsListResponse 账号列表响应
+type AccountsListResponse struct {
+	Accounts       []AccountInfo `json:"accounts"`
+	DefaultAccount string        `json:"default_account"`
+	Count          int           `json:"count"`
+}
+
+type accountKey struct{}
+
+// withAccount 把请求中的账号放入 context，动作在该账号的浏览器中执行
+// 参数已经由 binding 校验，为空时使用默认账号
+func withAccount(ctx context.Context, account string) context.Context {
+	if account == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, accountKey{}, account)
+}
+
+// accountFromContext context 中指定的账号，没有指定时返回默认账号
+func accountFromContext(ctx context.Context) string {
+	if account, _ := ctx.Value(accountKey{}).(string); account != "" {
+		return account
+	}
+	return configs.GetDefaultAccount()
+}
+
+// session 返回 context 中指定账号的会话，账号的浏览器还没有启动时先启动浏览器
+func (s *XiaohongshuService) session(ctx context.Context) (*accountSession, error) {
+	name := accountFromContext(ctx)
+	if !configs.HasAccount(name) {
+		return nil, errors.Errorf("账号 %s 不存在", name)
+	}
+
+	s.sessionsMu.Lock()
+	sess, ok := s.sessions[name]
+	if !ok {
+		sess = &accountSession{name: name, ready: make(chan struct{})}
+		s.sessions[name] = sess
+	}
+	s.sessionsMu.Unlock()
+
+	if !ok {
+		sess.err = s.launchSession(sess)
+		if sess.err != nil {
+			// 启动失败时移除会话，下一次请求重新启动
+			s.sessionsMu.Lock()
+			delete(s.sessions, name)
+			s.sessionsMu.Unlock()
+		}
+		close(sess.ready)
+	}
+
+	select {
+	case <-sess.ready:
+		return sess, sess.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// launchSession 启动账号的浏览器和页面池
+func (s *XiaohongshuService) launchSession(sess *accountSession) error {
+	store, err := configs.NewCookieStore(sess.name)
+	if err != nil {
+		return err
+	}
+
+	sup, err := browser.NewSupervisor(configs.IsHeadless(), func() {
+		// 浏览器重启后关闭页面池中旧浏览器上的空闲页面
+		sess.pages.CloseIdle()
+	},
+		browser.WithConfig(configs.GetBrowserConfig(sess.name)),
+		browser.WithCookies(store))
+	if err != nil {
+		return errors.Wrapf(err, "启动账号 %s 的浏览器失败", sess.name)
+	}
+
+	sess.browser = sup
+	sess.cookies = store
+	sess.pages = browser.NewPagePool(sup, configs.GetMaxPages())
+
+	go sup.Watch(browser.DefaultHealthCheckInterval)
+
+	logrus.Infof("账号 %s 的浏览器已启动", sess.name)
+	return nil
+}
+
+// runningSessions 浏览器已经启动的账号会话
+func (s *XiaohongshuService) runningSessions() map[string]*accountSession {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	running := make(map[string]*accountSession, len(s.sessions))
+	for name, sess := range s.sessions {
+		select {
+		case <-sess.ready:
+			if sess.err == nil {
+				running[name] = sess
+			}
+		default:
+		}
+	}
+	return running
+}
+
+// ListAccounts 列出所有账号
+func (s *XiaohongshuService) ListAccounts() *AccountsListResponse {
+	running := s.runningSessions()
+	health := s.SessionHealth()
+	defaultAccount := configs.GetDefaultAccount()
+
+	names := configs.AccountNames()
+	accounts := make([]AccountInfo, 0, len(names))
+	for _, name := range names {
+		info := AccountInfo{
+			Name:        name,
+			Default:     name == defaultAccount,
+			CookiesPath: configs.GetCookiesPath(name),
+		}
+		if u, err := configs.GetBrowserConfig(name).ProxyURL(); err == nil && u != nil {
+			info.Proxy = u.Redacted()
+		}
+		if sess, ok := running[name]; ok {
+			stats := sess.browser.Stats()
+			info.Running = true
+			info.Browser = &stats
+		}
+		if h, ok := health[name]; ok {
+			info.Session = &h
+		}
+		accounts = append(accounts, info)
+	}
+
+	return &AccountsListResponse{
+		Accounts:       accounts,
+		DefaultAccount: defaultAccount,
+		Count:          len(accounts),
+	}
+}
+
+// SwitchDefaultAccount 切换默认账号，之后没有指定账号的请求都在该账号下执行
+func (s *XiaohongshuService) SwitchDefaultAccount(name string) (*AccountsListResponse, error) {
+	if err := configs.SetDefaultAccount(name); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("默认账号已切换为 %s", name)
+	return s.ListAccounts(), nil
+}
+
+// closeSessions 关闭所有账号的页面池和浏览器
+func (s *XiaohongshuService) closeSessions() {
+	var wg sync.WaitGroup
+	for _, sess := range s.runningSessions() {
+		wg.Add(1)
+		go func(sess *accountSession) {
+			defer wg.Done()
+			sess.pages.Close()
+			sess.browser.Close()
+		}(sess)
+	}
+	wg.Wait()
+}
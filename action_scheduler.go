@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ActionKind 动作类型，决定调度时的互斥方式
+type ActionKind int
+
+const (
+	// ActionRead 只读动作（浏览、搜索、获取详情），同一账号下可以并行执行
+	ActionRead ActionKind = iota
+	// ActionWrite 写动作（发布、评论、点赞、收藏），同一账号下独占执行
+	ActionWrite
+)
+
+func (k ActionKind) String() string {
+	if k == ActionWrite {
+		return "write"
+	}
+	return "read"
+}
+
+// QueueObserver 排队位置回调，position 为前面还在等待或执行的动作数量
+type QueueObserver func(position int)
+
+type queueObserverKey struct{}
+
+// WithQueueObserver 在 context 中设置排队位置回调，动作需要排队时会在位置变化时回调
+func WithQueueObserver(ctx context.Context, fn QueueObserver) context.Context {
+	return context.WithValue(ctx, queueObserverKey{}, fn)
+}
+
+func queueObserverFromContext(ctx context.Context) QueueObserver {
+	fn, _ := ctx.Value(queueObserverKey{}).(QueueObserver)
+	return fn
+}
+
+// SchedulerStats 调度器统计信息
+type SchedulerStats struct {
+	ReadConcurrency int                     `json:"read_concurrency"`
+	Accounts        map[string]AccountStats `json:"accounts"`
+}
+
+// AccountStats 单个账号的调度状态
+type AccountStats struct {
+	Readers int  `json:"readers"`
+	Writing bool `json:"writing"`
+	Queued  int  `json:"queued"`
+}
+
+// actionWaiter 排队中的动作
+type actionWaiter struct {
+	kind     ActionKind
+	ready    chan struct{}
+	observer QueueObserver
+	position int
+}
+
+// accountQueue 单个账号的执行状态和等待队列
+type accountQueue struct {
+	readers int
+	writing bool
+	waiters []*actionWaiter
+}
+
+// ActionScheduler 浏览器动作调度器
+// 同一账号的写动作独占执行（不与任何其他动作交错），读动作最多 readConcurrency 个并行；
+// 等待中的动作严格按到达顺序执行，避免写动作被持续到达的读动作饿死
+type ActionScheduler struct {
+	mu              sync.Mutex
+	readConcurrency int
+	accounts        map[string]*accountQueue
+}
+
+// NewActionScheduler 创建动作调度器，readConcurrency 为同一账号读动作的并行上限
+func NewActionScheduler(readConcurrency int) *ActionScheduler {
+	if readConcurrency <= 0 {
+		readConcurrency = 1
+	}
+
+	return &ActionScheduler{
+		readConcurrency: readConcurrency,
+		accounts:        make(map[string]*accountQueue),
+	}
+}
+
+// Acquire 为账号申请执行一个动作，需要排队时阻塞直到轮到该动作或 ctx 结束
+// 返回的 release 必须在动作结束后调用
+func (s *ActionScheduler) Acquire(ctx context.Context, account string, kind ActionKind) (func(), error) {
+	s.mu.Lock()
+	q := s.queue(account)
+
+	if len(q.waiters) == 0 && q.canRun(kind, s.readConcurrency) {
+		q.start(kind)
+		s.mu.Unlock()
+		return s.releaseFunc(account, kind), nil
+	}
+
+	w := &actionWaiter{
+		kind:     kind,
+		ready:    make(chan struct{}),
+		observer: queueObserverFromContext(ctx),
+	}
+	q.waiters = append(q.waiters, w)
+	w.position = q.ahead(len(q.waiters) - 1)
+	position := w.position
+	s.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"account":  account,
+		"kind":     kind,
+		"position": position,
+	}).Info("动作排队等待执行")
+
+	if w.observer != nil {
+		w.observer(position)
+	}
+
+	select {
+	case <-w.ready:
+		return s.releaseFunc(account, kind), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消和调度同时发生，动作已经开始，直接释放
+			s.mu.Unlock()
+			s.releaseFunc(account, kind)()
+		default:
+			q.remove(w)
+			notify := s.dispatch(q)
+			s.mu.Unlock()
+			notify()
+		}
+		return nil, errors.Wrap(ctx.Err(), "排队等待执行时取消")
+	}
+}
+
+// Stats 返回调度器统计信息
+func (s *ActionScheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SchedulerStats{
+		ReadConcurrency: s.readConcurrency,
+		Accounts:        make(map[string]AccountStats, len(s.accounts)),
+	}
+	for account, q := range s.accounts {
+		stats.Accounts[account] = AccountStats{
+			Readers: q.readers,
+			Writing: q.writing,
+			Queued:  len(q.waiters),
+		}
+	}
+	return stats
+}
+
+func (s *ActionScheduler) queue(account string) *accountQueue {
+	q, ok := s.accounts[account]
+	if !ok {
+		q = &accountQueue{}
+		s.accounts[account] = q
+	}
+	return q
+}
+
+func (s *ActionScheduler) releaseFunc(account string, kind ActionKind) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			q := s.queue(account)
+			if kind == ActionWrite {
+				q.writing = false
+			} else {
+				q.readers--
+			}
+			notify := s.dispatch(q)
+			s.mu.Unlock()
+
+			notify()
+		})
+	}
+}
+
+// dispatch 按顺序启动队首可以执行的动作，返回的函数用于在释放锁之后通知等待者新的排队位置
+func (s *ActionScheduler) dispatch(q *accountQueue) func() {
+	for len(q.waiters) > 0 && q.canRun(q.waiters[0].kind, s.readConcurrency) {
+		w := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		q.start(w.kind)
+		close(w.ready)
+	}
+
+	var updates []func()
+	for i, w := range q.waiters {
+		position := q.ahead(i)
+		if position == w.position {
+			continue
+		}
+		w.position = position
+		if observer := w.observer; observer != nil {
+			updates = append(updates, func() { observer(position) })
+		}
+	}
+
+	return func() {
+		for _, update := range updates {
+			update()
+		}
+	}
+}
+
+func (q *accountQueue) canRun(kind ActionKind, readConcurrency int) bool {
+	if q.writing {
+		return false
+	}
+	if kind == ActionWrite {
+		return q.readers == 0
+	}
+	return q.readers < readConcurrency
+}
+
+func (q *accountQueue) start(kind ActionKind) {
+	if kind == ActionWrite {
+		q.writing = true
+	} else {
+		q.readers++
+	}
+}
+
+// ahead 第 i 个等待者前面的动作数量：正在执行的动作加上排在它前面的等待者
+func (q *accountQueue) ahead(i int) int {
+	running := q.readers
+	if q.writing {
+		running++
+	}
+	return running + i
+}
+
+func (q *accountQueue) remove(w *actionWaiter) {
+	for i, waiter := range q.waiters {
+		if waiter == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return
+		}
+	}
+}
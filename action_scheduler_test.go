@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireResult 异步申请执行的结果
+type acquireResult struct {
+	release func()
+	err     error
+}
+
+// acquireAsync 在后台申请执行动作，动作开始执行或取消时把结果发送到返回的 channel
+// 返回前等待动作进入执行或排队，保证多个动作按调用顺序排队
+func acquireAsync(t *testing.T, ctx context.Context, s *ActionScheduler, account string, kind ActionKind) <-chan acquireResult {
+	t.Helper()
+
+	before := s.Stats().Accounts[account]
+	ch := make(chan acquireResult, 1)
+	go func() {
+		release, err := s.Acquire(ctx, account, kind)
+		ch <- acquireResult{release: release, err: err}
+	}()
+
+	require.Eventually(t, func() bool {
+		after := s.Stats().Accounts[account]
+		return after.Queued > before.Queued || after.Readers > before.Readers || (after.Writing && !before.Writing)
+	}, 5*time.Second, time.Millisecond, "动作没有开始执行或排队")
+	return ch
+}
+
+// mustAcquire 立即获得执行权，需要排队时测试失败
+func mustAcquire(t *testing.T, s *ActionScheduler, account string, kind ActionKind) func() {
+	t.Helper()
+
+	ch := acquireAsync(t, context.Background(), s, account, kind)
+	result := waitFor(t, ch, "动作没有立即执行")
+	require.NoError(t, result.err)
+	return result.release
+}
+
+// assertQueued 动作仍在排队
+func assertQueued(t *testing.T, ch <-chan acquireResult) {
+	t.Helper()
+
+	select {
+	case <-ch:
+		t.Fatal("动作不应该开始执行")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// waitStarted 等待排队的动作开始执行，返回它的 release
+func waitStarted(t *testing.T, ch <-chan acquireResult) func() {
+	t.Helper()
+
+	result := waitFor(t, ch, "动作没有开始执行")
+	require.NoError(t, result.err)
+	return result.release
+}
+
+func TestActionSchedulerWriteExcludesReadsAndWrites(t *testing.T) {
+	s := NewActionScheduler(3)
+
+	release := mustAcquire(t, s, "a", ActionWrite)
+	read := acquireAsync(t, context.Background(), s, "a", ActionRead)
+	write := acquireAsync(t, context.Background(), s, "a", ActionWrite)
+	assertQueued(t, read)
+	assertQueued(t, write)
+
+	release()
+	releaseRead := waitStarted(t, read)
+	// 读动作执行期间写动作仍然等待
+	assertQueued(t, write)
+
+	releaseRead()
+	waitStarted(t, write)()
+
+	assert.Equal(t, AccountStats{}, s.Stats().Accounts["a"])
+}
+
+func TestActionSchedulerReadConcurrency(t *testing.T) {
+	s := NewActionScheduler(2)
+
+	release1 := mustAcquire(t, s, "a", ActionRead)
+	release2 := mustAcquire(t, s, "a", ActionRead)
+	third := acquireAsync(t, context.Background(), s, "a", ActionRead)
+	assertQueued(t, third)
+	assert.Equal(t, AccountStats{Readers: 2, Queued: 1}, s.Stats().Accounts["a"])
+
+	release1()
+	release3 := waitStarted(t, third)
+
+	release2()
+	release3()
+	assert.Equal(t, AccountStats{}, s.Stats().Accounts["a"])
+}
+
+func TestActionSchedulerFIFO(t *testing.T) {
+	s := NewActionScheduler(2)
+
+	releaseRead := mustAcquire(t, s, "a", ActionRead)
+	write := acquireAsync(t, context.Background(), s, "a", ActionWrite)
+	// 读并行数还没有用完，但前面有写动作在等，后到的读动作排在写动作后面
+	read := acquireAsync(t, context.Background(), s, "a", ActionRead)
+	assertQueued(t, write)
+	assertQueued(t, read)
+
+	releaseRead()
+	releaseWrite := waitStarted(t, write)
+	assertQueued(t, read)
+
+	releaseWrite()
+	waitStarted(t, read)()
+}
+
+func TestActionSchedulerCancelWhileQueued(t *testing.T) {
+	s := NewActionScheduler(1)
+
+	release := mustAcquire(t, s, "a", ActionWrite)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := acquireAsync(t, ctx, s, "a", ActionRead)
+
+	positions := make(chan int, 4)
+	observed := WithQueueObserver(context.Background(), func(position int) { positions <- position })
+	second := acquireAsync(t, observed, s, "a", ActionRead)
+	assert.Equal(t, 2, waitFor(t, positions, "没有收到排队位置"))
+
+	cancel()
+	result := waitFor(t, first, "取消后没有返回")
+	assert.ErrorIs(t, result.err, context.Canceled)
+
+	// 前面的等待者取消后，后面的等待者位置前移
+	assert.Equal(t, 1, waitFor(t, positions, "取消后没有更新排队位置"))
+	assert.Equal(t, AccountStats{Writing: true, Queued: 1}, s.Stats().Accounts["a"])
+
+	release()
+	waitStarted(t, second)()
+}
+
+func TestActionSchedulerQueueObserver(t *testing.T) {
+	s := NewActionScheduler(1)
+
+	release := mustAcquire(t, s, "a", ActionWrite)
+	read1 := acquireAsync(t, context.Background(), s, "a", ActionRead)
+	read2 := acquireAsync(t, context.Background(), s, "a", ActionRead)
+
+	positions := make(chan int, 4)
+	observed := WithQueueObserver(context.Background(), func(position int) { positions <- position })
+	read3 := acquireAsync(t, observed, s, "a", ActionRead)
+	assert.Equal(t, 3, waitFor(t, positions, "没有收到排队位置"))
+
+	release()
+	release1 := waitStarted(t, read1)
+	assert.Equal(t, 2, waitFor(t, positions, "没有更新排队位置"))
+
+	release1()
+	release2 := waitStarted(t, read2)
+	assert.Equal(t, 1, waitFor(t, positions, "没有更新排队位置"))
+
+	release2()
+	waitStarted(t, read3)()
+	assert.Empty(t, positions, "开始执行时不再回调")
+}
+
+func TestActionSchedulerAccountsIndependent(t *testing.T) {
+	s := NewActionScheduler(1)
+
+	releaseA := mustAcquire(t, s, "a", ActionWrite)
+	releaseB := mustAcquire(t, s, "b", ActionWrite)
+	releaseB()
+	mustAcquire(t, s, "b", ActionRead)()
+	releaseA()
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// verificationPollInterval 等待人工处理验证时检查验证是否已经消失的间隔
+const verificationPollInterval = 2 * time.Second
+
+// ActionStatus 进行中动作的状态
+type ActionStatus string
+
+const (
+	// ActionRunning 正在执行
+	ActionRunning ActionStatus = "running"
+	// ActionNeedsHuman 遇到验证码，暂停等待人工在浏览器中处理
+	ActionNeedsHuman ActionStatus = "needs_human"
+)
+
+// ActionInfo 进行中的动作
+type ActionInfo struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Account   string       `json:"account"`
+	Kind      string       `json:"kind"`
+	Status    ActionStatus `json:"status"`
+	StartedAt time.Time    `json:"started_at"`
+	// Verification 等待人工处理的验证，只在 needs_human 状态下存在
+	Verification *ActionVerification `json:"verification,omitempty"`
+	// LiveViewURL 页面的 DevTools 实时画面地址，可以直接在其中完成验证
+	LiveViewURL   string `json:"live_view_url,omitempty"`
+	ScreenshotURL string `json:"screenshot_url,omitempty"`
+}
+
+// ActionVerification 动作遇到的验证
+type ActionVerification struct {
+	Reason     string    `json:"reason"`
+	URL        string    `json:"url"`
+	DetectedAt time.Time `json:"detected_at"`
+	// Deadline 超过该时间仍未处理时动作以 CAPTCHA_REQUIRED 失败
+	Deadline time.Time `json:"deadline"`
+}
+
+// ActionStatusChangedEvent 动作状态变化通知参数
+type ActionStatusChangedEvent struct {
+	Action    ActionInfo `json:"action"`
+	Timestamp string     `json:"timestamp"`
+}
+
+// trackedAction 记录中的动作，page 为页面池中的原始页面，不受请求 context 影响
+type trackedAction struct {
+	info    ActionInfo
+	session *accountSession
+	page    *rod.Page
+	resume  chan struct{}
+}
+
+// actionTracker 记录进行中的动作，用于查询需要人工处理的动作和定位页面
+type actionTracker struct {
+	mu       sync.Mutex
+	nextID   uint64
+	actions  map[string]*trackedAction
+	byTarget map[proto.TargetTargetID]*trackedAction
+}
+
+func newActionTracker() *actionTracker {
+	return &actionTracker{
+		actions:  make(map[string]*trackedAction),
+		byTarget: make(map[proto.TargetTargetID]*trackedAction),
+	}
+}
+
+// start 记录在账号会话中开始执行的动作
+func (t *actionTracker) start(sess *accountSession, name string, kind ActionKind, page *rod.Page) *trackedAction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	a := &trackedAction{
+		info: ActionInfo{
+			ID:        strconv.FormatUint(t.nextID, 10),
+			Name:      name,
+			Account:   sess.name,
+			Kind:      kind.String(),
+			Status:    ActionRunning,
+			StartedAt: time.Now(),
+		},
+		session: sess,
+		page:    page,
+		resume:  make(chan struct{}, 1),
+	}
+	t.actions[a.info.ID] = a
+	t.byTarget[page.TargetID] = a
+	return a
+}
+
+// finish 移除结束的动作
+func (t *actionTracker) finish(a *trackedAction) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.actions, a.info.ID)
+	if t.byTarget[a.page.TargetID] == a {
+		delete(t.byTarget, a.page.TargetID)
+	}
+}
+
+// byPage 查找在该页面上执行的动作
+func (t *actionTracker) byPage(targetID proto.TargetTargetID) (*trackedAction, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.byTarget[targetID]
+	return a, ok
+}
+
+// get 按 ID 查找动作
+func (t *actionTracker) get(id string) (*trackedAction, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.actions[id]
+	return a, ok
+}
+
+// list 按开始时间返回进行中的动作，account 不为空时只返回该账号的动作
+func (t *actionTracker) list(account string) []ActionInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := make([]ActionInfo, 0, len(t.actions))
+	for _, a := range t.actions {
+		if account == "" || a.info.Account == account {
+			list = append(list, a.info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedAt.Before(list[j].StartedAt)
+	})
+	return list
+}
+
+// setStatus 更新动作状态，返回更新后的快照；verification 为 nil 时清除验证信息
+func (t *actionTracker) setStatus(a *trackedAction, status ActionStatus, verification *ActionVerification, liveViewURL string) ActionInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a.info.Status = status
+	a.info.Verification = verification
+	a.info.LiveViewURL = liveViewURL
+	a.info.ScreenshotURL = ""
+	if status == ActionNeedsHuman {
+		a.info.ScreenshotURL = "/api/v1/actions/" + a.info.ID + "/screenshot"
+	}
+	return a.info
+}
+
+// requestResume 通知等待人工处理的动作继续执行
+func (t *actionTracker) requestResume(id string) (ActionInfo, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.actions[id]
+	if !ok {
+		return ActionInfo{}, errors.Errorf("动作 %s 不存在或已经结束", id)
+	}
+	if a.info.Status != ActionNeedsHuman {
+		return ActionInfo{}, errors.Errorf("动作 %s 没有在等待人工处理", id)
+	}
+
+	select {
+	case a.resume <- struct{}{}:
+	default:
+	}
+	return a.info, nil
+}
+
+// Actions 进行中的动作，needs_human 状态的动作需要人工在浏览器中完成验证
+// account 不为空时只返回该账号的动作
+func (s *XiaohongshuService) Actions(account string) []ActionInfo {
+	return s.actions.list(account)
+}
+
+// ResumeAction 人工处理完验证后通知动作继续执行
+// 验证消失后动作会自动继续，只有自动检测没有识别出验证已完成时才需要调用
+func (s *XiaohongshuService) ResumeAction(id string) (ActionInfo, error) {
+	return s.actions.requestResume(id)
+}
+
+// ActionScreenshot 截取动作页面当前画面的 PNG
+func (s *XiaohongshuService) ActionScreenshot(ctx context.Context, id string) ([]byte, error) {
+	a, ok := s.actions.get(id)
+	if !ok {
+		return nil, errors.Errorf("动作 %s 不存在或已经结束", id)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	data, err := a.page.Context(ctx).Screenshot(false, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "截图失败")
+	}
+	return data, nil
+}
+
+// waitForHuman 验证处理函数：把动作标记为 needs_human 并推送通知，
+// 等待验证从页面上消失、收到人工继续的通知或等待超时
+func (s *XiaohongshuService) waitForHuman(ctx context.Context, page *rod.Page, v xiaohongshu.Verification) error {
+	timeout := configs.GetVerificationTimeout()
+	if timeout <= 0 {
+		return errors.New("未开启人工处理验证")
+	}
+
+	a, ok := s.actions.byPage(page.TargetID)
+	if !ok {
+		return errors.New("页面上没有进行中的动作")
+	}
+
+	// 丢弃上一次验证期间多余的继续通知
+	select {
+	case <-a.resume:
+	default:
+	}
+
+	now := time.Now()
+	info := s.actions.setStatus(a, ActionNeedsHuman, &ActionVerification{
+		Reason:     v.Reason,
+		URL:        v.URL,
+		DetectedAt: now,
+		Deadline:   now.Add(timeout),
+	}, a.session.browser.DevToolsURL(page.TargetID))
+	s.emitActionStatus(info)
+
+	logrus.WithFields(logrus.Fields{
+		"action":    info.Name,
+		"id":        info.ID,
+		"live_view": info.LiveViewURL,
+	}).Warnf("动作需要人工处理验证，最多等待 %s", timeout)
+
+	defer func() {
+		s.emitActionStatus(s.actions.setStatus(a, ActionRunning, nil, ""))
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(verificationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return errors.Errorf("等待人工处理验证超过 %s", timeout)
+		case <-a.resume:
+			return nil
+		case <-ticker.C:
+			current, err := xiaohongshu.DetectVerification(page)
+			if err != nil {
+				logrus.WithError(err).Debug("检查验证状态失败")
+				continue
+			}
+			if current == nil {
+				return nil
+			}
+		}
+	}
+}
+
+// emitActionStatus 推送动作状态变化通知
+func (s *XiaohongshuService) emitActionStatus(info ActionInfo) {
+	s.emit(notificationActionStatusChanged, &ActionStatusChangedEvent{
+		Action:    info,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
@@ -15,15 +15,45 @@ import (
 // AppServer 应用服务器结构体，封装所有服务和处理器
 type AppServer struct {
 	xiaohongshuService *XiaohongshuService
+	mcpSessions        *MCPSessionManager
+	batchPolicy        BatchPolicy
+	tools              *MCPToolRegistry
+	prompts            []*MCPPrompt
+	recentResources    recentResources
+	resourceWatcher    resourceWatcher
 	router             *gin.Engine
 	httpServer         *http.Server
 }
 
 // NewAppServer 创建新的应用服务器实例
 func NewAppServer(xiaohongshuService *XiaohongshuService) *AppServer {
-	return &AppServer{
+	s := &AppServer{
 		xiaohongshuService: xiaohongshuService,
+		mcpSessions:        NewMCPSessionManager(),
+		batchPolicy:        BatchSequential,
 	}
+
+	registerValidators()
+
+	s.tools = NewMCPToolRegistry()
+	s.registerTools()
+	s.registerPrompts()
+
+	// 服务层事件（登录状态变化、发布完成等）通过 SSE 推送给所有 MCP 会话
+	xiaohongshuService.SetEventHandler(s.notify)
+
+	return s
+}
+
+// SetBatchPolicy 设置 JSON-RPC 批量请求的执行策略
+func (s *AppServer) SetBatchPolicy(policy BatchPolicy) {
+	s.batchPolicy = policy
+}
+
+// notify 向所有 MCP 会话推送通知
+func (s *AppServer) notify(method string, params any) {
+	logrus.WithField("method", method).Debug("推送 MCP 通知")
+	s.mcpSessions.Broadcast(method, params)
 }
 
 // Start 启动服务器
@@ -55,12 +85,19 @@ func (s *AppServer) Start(port string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// 结束所有 MCP 会话，断开 GET SSE 长连接，否则 HTTP 服务器要等到超时才能关闭
+	s.mcpSessions.Close()
+	s.stopResourceWatcher()
+
 	// 关闭 HTTP 服务器
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		logrus.Errorf("服务器关闭失败: %v", err)
 		return err
 	}
 
+	// 关闭页面池和浏览器
+	s.xiaohongshuService.Close()
+
 	logrus.Infof("服务器已关闭")
 	return nil
 }
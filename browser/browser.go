@@ -1,27 +1,242 @@
 package browser
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/go-rod/stealth"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/xpzouying/headless_browser"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 	"github.com/xpzouying/xiaohongshu-mcp/cookies"
 )
 
-func NewBrowser(headless bool) *headless_browser.Browser {
+// browserCloseTimeout 关闭浏览器的超时时间，超时后直接结束浏览器进程
+const browserCloseTimeout = 5 * time.Second
+
+// Browser 启用了 stealth 的浏览器实例
+// 代理、User-Agent 和语言在启动浏览器时设置，时区、语言和视口在每个新页面上设置
+type Browser struct {
+	browser    *rod.Browser
+	launcher   *launcher.Launcher
+	relay      *proxyRelay
+	config     configs.BrowserConfig
+	controlURL string
+}
+
+// launchOptions 启动浏览器的选项
+type launchOptions struct {
+	config  configs.BrowserConfig
+	cookies cookies.Cookier
+}
+
+// Option 浏览器选项
+type Option func(*launchOptions)
+
+// WithConfig 使用指定的浏览器参数，为空的字段使用默认值
+func WithConfig(c configs.BrowserConfig) Option {
+	return func(o *launchOptions) {
+		o.config = o.config.Merge(c)
+	}
+}
+
+// WithCookies 启动时从指定的存储加载 cookies，不指定时不加载
+func WithCookies(c cookies.Cookier) Option {
+	return func(o *launchOptions) {
+		o.cookies = c
+	}
+}
+
+// Launch 启动浏览器并加载本地保存的 cookies
+func Launch(headless bool, options ...Option) (*Browser, error) {
+	opts := launchOptions{
+		config: configs.GetBrowserConfig(""),
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	cfg := opts.config
+
+	width, height, err := cfg.ViewportSize()
+	if err != nil {
+		return nil, err
+	}
+
+	l := launcher.New().
+		Headless(headless).
+		Set("no-sandbox").
+		Set("user-agent", cfg.UserAgent).
+		Set("lang", cfg.Locale).
+		Env(append(os.Environ(), "TZ="+cfg.Timezone)...)
+
+	proxyServer, relay, err := setupProxy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if proxyServer != "" {
+		l = l.Proxy(proxyServer)
+		logrus.Infof("浏览器使用代理 %s", redactProxy(cfg.Proxy))
+	}
 
-	opts := []headless_browser.Option{
-		headless_browser.WithHeadless(headless),
+	controlURL, err := l.Launch()
+	if err != nil {
+		if relay != nil {
+			_ = relay.Close()
+		}
+		return nil, errors.Wrap(err, "failed to launch browser")
 	}
 
-	// 加载 cookies
-	cookiePath := cookies.GetCookiesFilePath()
-	cookieLoader := cookies.NewLoadCookie(cookiePath)
+	b := rod.New().
+		ControlURL(controlURL).
+		Trace(true).
+		DefaultDevice(devices.Device{
+			Title:          "xiaohongshu-mcp",
+			UserAgent:      cfg.UserAgent,
+			AcceptLanguage: acceptLanguage(cfg.Locale),
+			Screen: devices.Screen{
+				DevicePixelRatio: 1,
+				Horizontal:       devices.ScreenSize{Width: width, Height: height},
+				Vertical:         devices.ScreenSize{Width: height, Height: width},
+			},
+		}.Landscape())
+	if err := b.Connect(); err != nil {
+		l.Kill()
+		l.Cleanup()
+		if relay != nil {
+			_ = relay.Close()
+		}
+		return nil, errors.Wrap(err, "failed to connect browser")
+	}
 
-	if data, err := cookieLoader.LoadCookies(); err == nil {
-		opts = append(opts, headless_browser.WithCookies(string(data)))
-		logrus.Debugf("loaded cookies from filesuccessfully")
-	} else {
+	if opts.cookies != nil {
+		loadCookies(b, opts.cookies)
+	}
+
+	return &Browser{
+		browser:    b,
+		launcher:   l,
+		relay:      relay,
+		config:     cfg,
+		controlURL: controlURL,
+	}, nil
+}
+
+// loadCookies 加载保存的 cookies
+func loadCookies(b *rod.Browser, cookier cookies.Cookier) {
+	data, err := cookier.LoadCookies()
+	if err != nil {
 		logrus.Warnf("failed to load cookies: %v", err)
+		return
 	}
 
-	return headless_browser.New(opts...)
+	var cks []*proto.NetworkCookie
+	if err := json.Unmarshal(data, &cks); err != nil {
+		logrus.Warnf("failed to unmarshal cookies: %v", err)
+		return
+	}
+
+	if err := b.SetCookies(proto.CookiesToParams(cks)); err != nil {
+		logrus.Warnf("failed to set cookies: %v", err)
+		return
+	}
+	logrus.Debugf("loaded cookies successfully")
+}
+
+// SaveCookies 保存页面所在浏览器的全部 cookies，下次启动浏览器时加载
+func SaveCookies(page *rod.Page, cookier cookies.Cookier) error {
+	cks, err := page.Browser().GetCookies()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cookies")
+	}
+
+	data, err := json.Marshal(cks)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cookies")
+	}
+
+	return cookier.SaveCookies(data)
+}
+
+// Config 浏览器实际使用的参数
+func (b *Browser) Config() configs.BrowserConfig {
+	return b.config
+}
+
+// DevToolsURL 页面的 DevTools 前端地址，打开后可以实时查看和操作页面，无头模式下也可以借此人工处理验证码
+// 浏览器的调试端口只监听本机，地址只能在运行服务的机器上打开
+func (b *Browser) DevToolsURL(targetID proto.TargetTargetID) string {
+	u, err := url.Parse(b.controlURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return fmt.Sprintf("http://%s/devtools/inspector.html?ws=%s/devtools/page/%s", u.Host, u.Host, targetID)
+}
+
+// NewPage 创建启用了 stealth 的页面，并设置时区和语言
+func (b *Browser) NewPage() (*rod.Page, error) {
+	page, err := stealth.Page(b.browser)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create page")
+	}
+
+	if b.config.Timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: b.config.Timezone}).Call(page); err != nil {
+			_ = page.Close()
+			return nil, errors.Wrap(err, "failed to set timezone")
+		}
+	}
+	if b.config.Locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: b.config.Locale}).Call(page); err != nil {
+			_ = page.Close()
+			return nil, errors.Wrap(err, "failed to set locale")
+		}
+	}
+
+	return page, nil
+}
+
+// MustNewPage 与 NewPage 相同，失败时 panic
+func (b *Browser) MustNewPage() *rod.Page {
+	page, err := b.NewPage()
+	if err != nil {
+		panic(err)
+	}
+	return page
+}
+
+// Alive 检查浏览器进程和 CDP 连接是否正常
+func (b *Browser) Alive(ctx context.Context) error {
+	_, err := proto.BrowserGetVersion{}.Call(b.browser.Context(ctx))
+	return err
+}
+
+// Close 关闭浏览器、代理中继并清理启动器的临时目录
+// 浏览器已经失去响应时直接结束浏览器进程
+func (b *Browser) Close() {
+	if err := b.browser.Timeout(browserCloseTimeout).Close(); err != nil {
+		logrus.Warnf("failed to close browser: %v", err)
+		b.launcher.Kill()
+	}
+	b.launcher.Cleanup()
+	if b.relay != nil {
+		_ = b.relay.Close()
+	}
+}
+
+// acceptLanguage 根据语言生成 Accept-Language 请求头，例如 zh-CN 生成 zh-CN,zh;q=0.9
+func acceptLanguage(locale string) string {
+	base, _, ok := strings.Cut(locale, "-")
+	if !ok || base == "" {
+		return locale
+	}
+	return locale + "," + base + ";q=0.9"
 }
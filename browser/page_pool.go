@@ -0,0 +1,304 @@
+package browser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultPageIdleTimeout 空闲页面超过该时长会被关闭
+	DefaultPageIdleTimeout = 2 * time.Minute
+
+	// DefaultPageMaxUses 页面被租用超过该次数后不再复用，避免长时间运行的标签页内存膨胀
+	DefaultPageMaxUses = 20
+
+	// pageResetTimeout 归还页面时重置到空白页的超时时间
+	pageResetTimeout = 5 * time.Second
+)
+
+// ErrPagePoolClosed 页面池已关闭
+var ErrPagePoolClosed = errors.New("page pool closed")
+
+// PagePoolStats 页面池统计信息
+type PagePoolStats struct {
+	MaxPages int    `json:"max_pages"`
+	InUse    int    `json:"in_use"`
+	Idle     int    `json:"idle"`
+	Waiting  int    `json:"waiting"`
+	Created  uint64 `json:"created"`
+	Closed   uint64 `json:"closed"`
+	Leases   uint64 `json:"leases"`
+}
+
+// pooledPage 池中的页面
+type pooledPage struct {
+	page     *rod.Page
+	uses     int
+	lastUsed time.Time
+}
+
+// PagePool 基于共享浏览器的页面池
+// 页面通过 Acquire 租用、Release 归还，同时租用的页面数量不超过 maxPages，空闲页面超时后被关闭
+type PagePool struct {
+	create  func() (*rod.Page, error)
+	destroy func(*rod.Page)
+	reset   func(*rod.Page) error
+
+	maxPages    int
+	idleTimeout time.Duration
+	maxUses     int
+
+	slots chan struct{} // 容量为 maxPages 的信号量
+
+	mu      sync.Mutex
+	idle    []*pooledPage
+	leased  map[*rod.Page]*pooledPage
+	waiting int
+	created uint64
+	closed  uint64
+	leases  uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// PageCreator 创建页面，Browser 和 Supervisor 都实现了该接口
+type PageCreator interface {
+	NewPage() (*rod.Page, error)
+}
+
+// NewPagePool 创建页面池，maxPages 为同时租用页面的上限
+func NewPagePool(b PageCreator, maxPages int) *PagePool {
+	pool := newPagePool(maxPages,
+		b.NewPage,
+		func(page *rod.Page) {
+			if err := page.Close(); err != nil {
+				logrus.Warnf("failed to close page: %v", err)
+			}
+		},
+		func(page *rod.Page) error {
+			return page.Timeout(pageResetTimeout).Navigate("about:blank")
+		},
+	)
+
+	go pool.reapIdle()
+
+	return pool
+}
+
+func newPagePool(maxPages int, create func() (*rod.Page, error), destroy func(*rod.Page), reset func(*rod.Page) error) *PagePool {
+	if maxPages <= 0 {
+		maxPages = 1
+	}
+
+	return &PagePool{
+		create:      create,
+		destroy:     destroy,
+		reset:       reset,
+		maxPages:    maxPages,
+		idleTimeout: DefaultPageIdleTimeout,
+		maxUses:     DefaultPageMaxUses,
+		slots:       make(chan struct{}, maxPages),
+		leased:      make(map[*rod.Page]*pooledPage),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Acquire 租用一个页面，池已满时等待直到有页面归还或 ctx 结束
+// 返回的页面未绑定 ctx，调用方用完后必须调用 Release
+func (p *PagePool) Acquire(ctx context.Context) (*rod.Page, error) {
+	p.mu.Lock()
+	p.waiting++
+	p.mu.Unlock()
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.stop:
+		p.doneWaiting()
+		return nil, ErrPagePoolClosed
+	case <-ctx.Done():
+		p.doneWaiting()
+		return nil, errors.Wrap(ctx.Err(), "等待可用页面超时")
+	}
+	p.doneWaiting()
+
+	p.mu.Lock()
+	var entry *pooledPage
+	if n := len(p.idle); n > 0 {
+		// 优先复用最近归还的页面
+		entry = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if entry == nil {
+		page, err := p.create()
+		if err != nil {
+			<-p.slots
+			return nil, err
+		}
+		entry = &pooledPage{page: page}
+
+		p.mu.Lock()
+		p.created++
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	entry.uses++
+	p.leases++
+	p.leased[entry.page] = entry
+	p.mu.Unlock()
+
+	return entry.page, nil
+}
+
+// Release 归还页面，页面被重置到空白页后放回空闲列表
+// 重置失败或使用次数达到上限的页面会被直接关闭
+func (p *PagePool) Release(page *rod.Page) {
+	p.mu.Lock()
+	entry, ok := p.leased[page]
+	delete(p.leased, page)
+	p.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	defer func() { <-p.slots }()
+
+	if p.isStopped() || entry.uses >= p.maxUses {
+		p.closePage(entry.page)
+		return
+	}
+
+	if err := p.reset(entry.page); err != nil {
+		logrus.Warnf("failed to reset page, closing it: %v", err)
+		p.closePage(entry.page)
+		return
+	}
+
+	entry.lastUsed = time.Now()
+
+	p.mu.Lock()
+	p.idle = append(p.idle, entry)
+	p.mu.Unlock()
+}
+
+// Discard 归还并关闭页面，用于页面已损坏（例如浏览器崩溃）的情况
+func (p *PagePool) Discard(page *rod.Page) {
+	p.mu.Lock()
+	_, ok := p.leased[page]
+	delete(p.leased, page)
+	p.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	p.closePage(page)
+	<-p.slots
+}
+
+// Stats 返回页面池统计信息
+func (p *PagePool) Stats() PagePoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PagePoolStats{
+		MaxPages: p.maxPages,
+		InUse:    len(p.leased),
+		Idle:     len(p.idle),
+		Waiting:  p.waiting,
+		Created:  p.created,
+		Closed:   p.closed,
+		Leases:   p.leases,
+	}
+}
+
+// Close 关闭页面池和所有空闲页面，正在租用的页面在归还时关闭
+func (p *PagePool) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+
+	p.CloseIdle()
+}
+
+// CloseIdle 关闭所有空闲页面，用于浏览器重启后丢弃旧浏览器上的页面
+func (p *PagePool) CloseIdle() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	for _, entry := range idle {
+		p.closePage(entry.page)
+	}
+}
+
+// reapIdle 定期关闭空闲超时的页面
+func (p *PagePool) reapIdle() {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.closeIdleBefore(now.Add(-p.idleTimeout))
+		}
+	}
+}
+
+// closeIdleBefore 关闭最后使用时间早于 deadline 的空闲页面
+func (p *PagePool) closeIdleBefore(deadline time.Time) {
+	p.mu.Lock()
+	var expired []*pooledPage
+	kept := p.idle[:0]
+	for _, entry := range p.idle {
+		if entry.lastUsed.Before(deadline) {
+			expired = append(expired, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	p.idle = kept
+	p.mu.Unlock()
+
+	for _, entry := range expired {
+		p.closePage(entry.page)
+	}
+
+	if len(expired) > 0 {
+		logrus.Debugf("closed %d idle pages", len(expired))
+	}
+}
+
+func (p *PagePool) closePage(page *rod.Page) {
+	p.destroy(page)
+
+	p.mu.Lock()
+	p.closed++
+	p.mu.Unlock()
+}
+
+func (p *PagePool) doneWaiting() {
+	p.mu.Lock()
+	p.waiting--
+	p.mu.Unlock()
+}
+
+func (p *PagePool) isStopped() bool {
+	select {
+	case <-p.stop:
+		return true
+	default:
+		return false
+	}
+}
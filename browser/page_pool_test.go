@@ -0,0 +1,85 @@
+package browser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPagePool(maxPages int) *PagePool {
+	return newPagePool(maxPages,
+		func() (*rod.Page, error) { return &rod.Page{}, nil },
+		func(*rod.Page) {},
+		func(*rod.Page) error { return nil },
+	)
+}
+
+func TestPagePoolReusesReleasedPage(t *testing.T) {
+	pool := newTestPagePool(2)
+
+	page, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	pool.Release(page)
+
+	again, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, page, again)
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Created)
+	assert.Equal(t, uint64(2), stats.Leases)
+	assert.Equal(t, 1, stats.InUse)
+}
+
+func TestPagePoolLimitsConcurrency(t *testing.T) {
+	pool := newTestPagePool(1)
+
+	page, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.Error(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		p, err := pool.Acquire(context.Background())
+		if err == nil {
+			pool.Release(p)
+		}
+		close(acquired)
+	}()
+
+	pool.Release(page)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiting Acquire was not unblocked by Release")
+	}
+}
+
+func TestPagePoolRecyclesPages(t *testing.T) {
+	pool := newTestPagePool(1)
+	pool.maxUses = 2
+
+	first, _ := pool.Acquire(context.Background())
+	pool.Release(first)
+	second, _ := pool.Acquire(context.Background())
+	pool.Release(second)
+	assert.Same(t, first, second)
+	assert.Equal(t, uint64(1), pool.Stats().Closed, "page should be closed after max uses")
+
+	third, _ := pool.Acquire(context.Background())
+	pool.Release(third)
+	pool.closeIdleBefore(time.Now().Add(time.Second))
+
+	stats := pool.Stats()
+	assert.Equal(t, 0, stats.Idle)
+	assert.Equal(t, uint64(2), stats.Closed)
+}
@@ -0,0 +1,362 @@
+package browser
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"golang.org/x/net/proxy"
+)
+
+// proxyDialTimeout 连接上游代理的超时时间
+const proxyDialTimeout = 15 * time.Second
+
+// relayAllowPort 中继允许连接的目标端口，只转发网页使用的 80 和 443 端口，
+// 同一台机器上的其他进程连接中继时也不能借用上游代理访问其他端口的服务
+var relayAllowPort = func(port string) bool {
+	return port == "80" || port == "443"
+}
+
+// redactProxy 隐藏代理地址中的密码，用于日志
+func redactProxy(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// setupProxy 返回传给浏览器 --proxy-server 的地址，未配置代理时返回空字符串
+// 不需要认证的代理直接交给浏览器；需要认证的代理由本地中继转发，返回中继地址
+func setupProxy(cfg configs.BrowserConfig) (string, *proxyRelay, error) {
+	upstream, err := cfg.ProxyURL()
+	if err != nil || upstream == nil {
+		return "", nil, err
+	}
+
+	if upstream.User == nil {
+		return upstream.Scheme + "://" + upstream.Host, nil, nil
+	}
+
+	relay, err := newProxyRelay(upstream)
+	if err != nil {
+		return "", nil, err
+	}
+	logrus.Warnf("带认证的代理通过本地中继 %s 转发，浏览器运行期间本机的其他进程也可以通过该中继使用代理", relay.Addr())
+	return "http://" + relay.Addr(), relay, nil
+}
+
+// proxyRelay 带认证的上游代理的本地中继
+// Chrome 不支持 SOCKS5 认证，HTTP 代理认证也依赖弹窗，因此浏览器连接监听在 127.0.0.1 上不需要认证的中继，
+// 中继再带上认证信息连接上游代理。
+// 中继本身没有认证：浏览器运行期间，本机的其他进程可以连接中继使用上游代理（但拿不到代理的用户名和密码）。
+// 为了尽量缩小暴露范围，中继只监听 127.0.0.1 的随机端口，只接受来自本机回环地址的连接，
+// 只转发到 80 和 443 端口，并随浏览器关闭而关闭。多用户共享的机器上应使用不需要认证的代理或单独的网络命名空间。
+type proxyRelay struct {
+	listener net.Listener
+	upstream *url.URL
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// newProxyRelay 启动本地中继
+func newProxyRelay(upstream *url.URL) (*proxyRelay, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start proxy relay")
+	}
+
+	r := &proxyRelay{
+		listener: listener,
+		upstream: upstream,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	go r.serve()
+	return r, nil
+}
+
+// Addr 中继监听的地址
+func (r *proxyRelay) Addr() string {
+	return r.listener.Addr().String()
+}
+
+// Close 停止监听并断开所有连接
+func (r *proxyRelay) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	for conn := range r.conns {
+		_ = conn.Close()
+	}
+	r.mu.Unlock()
+
+	return r.listener.Close()
+}
+
+func (r *proxyRelay) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		if !isLoopback(conn.RemoteAddr()) {
+			_ = conn.Close()
+			continue
+		}
+		if !r.track(conn) {
+			_ = conn.Close()
+			return
+		}
+		go func() {
+			defer r.untrack(conn)
+			r.handle(conn)
+		}()
+	}
+}
+
+func (r *proxyRelay) track(conn net.Conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return false
+	}
+	r.conns[conn] = struct{}{}
+	return true
+}
+
+func (r *proxyRelay) untrack(conn net.Conn) {
+	_ = conn.Close()
+
+	r.mu.Lock()
+	delete(r.conns, conn)
+	r.mu.Unlock()
+}
+
+// handle 处理浏览器的一个代理连接：CONNECT 建立隧道，普通 HTTP 请求逐个转发
+func (r *proxyRelay) handle(conn net.Conn) {
+	client := bufio.NewReader(conn)
+
+	var (
+		target     net.Conn
+		targetBuf  *bufio.Reader
+		targetHost string
+	)
+	defer func() {
+		if target != nil {
+			_ = target.Close()
+		}
+	}()
+
+	for {
+		req, err := http.ReadRequest(client)
+		if err != nil {
+			return
+		}
+		req.Header.Del("Proxy-Authorization")
+		req.Header.Del("Proxy-Connection")
+
+		if req.Method == http.MethodConnect {
+			if !allowRelayTarget(req.Host, "443") {
+				logrus.Warnf("proxy relay: rejected CONNECT to %s", req.Host)
+				_, _ = io.WriteString(conn, "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n")
+				return
+			}
+
+			tunnel, err := r.dialTunnel(req.Host)
+			if err != nil {
+				logrus.Warnf("proxy relay: failed to connect %s: %v", req.Host, err)
+				_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\nContent-Length: 0\r\n\r\n")
+				return
+			}
+			defer tunnel.Close()
+
+			if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+				return
+			}
+			pipe(&bufferedConn{Conn: conn, r: client}, tunnel)
+			return
+		}
+
+		host := hostWithPort(req.URL.Host, "80")
+		if !allowRelayTarget(host, "80") {
+			logrus.Warnf("proxy relay: rejected request to %s", host)
+			_, _ = io.WriteString(conn, "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n")
+			return
+		}
+		if target == nil || (r.upstream.Scheme == "socks5" && host != targetHost) {
+			if target != nil {
+				_ = target.Close()
+			}
+			target, err = r.dialForward(host)
+			if err != nil {
+				logrus.Warnf("proxy relay: failed to connect %s: %v", host, err)
+				_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\nContent-Length: 0\r\n\r\n")
+				return
+			}
+			targetBuf = bufio.NewReader(target)
+			targetHost = host
+		}
+
+		if r.upstream.Scheme == "socks5" {
+			// 直接连接目标网站，使用 origin-form 请求
+			err = req.Write(target)
+		} else {
+			req.Header.Set("Proxy-Authorization", r.basicAuth())
+			err = req.WriteProxy(target)
+		}
+		if err != nil {
+			return
+		}
+
+		resp, err := http.ReadResponse(targetBuf, req)
+		if err != nil {
+			return
+		}
+		err = resp.Write(conn)
+		resp.Body.Close()
+		if err != nil || resp.Close || req.Close {
+			return
+		}
+	}
+}
+
+// dialTunnel 通过上游代理建立到 addr 的 TCP 隧道
+func (r *proxyRelay) dialTunnel(addr string) (net.Conn, error) {
+	if r.upstream.Scheme == "socks5" {
+		return r.dialSOCKS5(addr)
+	}
+
+	conn, err := net.DialTimeout("tcp", r.upstream.Host, proxyDialTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect upstream proxy")
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{"Proxy-Authorization": {r.basicAuth()}},
+	}
+	_ = conn.SetDeadline(time.Now().Add(proxyDialTimeout))
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to send CONNECT")
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to read CONNECT response")
+	}
+	// CONNECT 成功后连接即为隧道，不能读取响应体
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, errors.Errorf("upstream proxy refused CONNECT: %s", resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// dialForward 建立转发普通 HTTP 请求的连接：HTTP 代理连接上游代理本身，SOCKS5 代理连接目标网站
+func (r *proxyRelay) dialForward(host string) (net.Conn, error) {
+	if r.upstream.Scheme == "socks5" {
+		return r.dialSOCKS5(host)
+	}
+
+	conn, err := net.DialTimeout("tcp", r.upstream.Host, proxyDialTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect upstream proxy")
+	}
+	return conn, nil
+}
+
+func (r *proxyRelay) dialSOCKS5(addr string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if r.upstream.User != nil {
+		password, _ := r.upstream.User.Password()
+		auth = &proxy.Auth{User: r.upstream.User.Username(), Password: password}
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", r.upstream.Host, auth, &net.Dialer{Timeout: proxyDialTimeout})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create socks5 dialer")
+	}
+
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect through socks5 proxy")
+	}
+	return conn, nil
+}
+
+func (r *proxyRelay) basicAuth() string {
+	password, _ := r.upstream.User.Password()
+	credentials := r.upstream.User.Username() + ":" + password
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// bufferedConn 读取时先消费 bufio.Reader 中已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// pipe 双向转发数据，任意一端结束后关闭两端
+func pipe(a, b net.Conn) {
+	var once sync.Once
+	closeBoth := func() {
+		_ = a.Close()
+		_ = b.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(a, b)
+		once.Do(closeBoth)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(b, a)
+		once.Do(closeBoth)
+	}()
+	wg.Wait()
+}
+
+// isLoopback 连接来自本机回环地址
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// allowRelayTarget 中继是否允许连接目标地址，没有端口时使用默认端口
+func allowRelayTarget(host, defaultPort string) bool {
+	_, port, err := net.SplitHostPort(hostWithPort(host, defaultPort))
+	return err == nil && relayAllowPort(port)
+}
+
+// hostWithPort 补全默认端口
+func hostWithPort(host, defaultPort string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), defaultPort)
+}
@@ -0,0 +1,155 @@
+package browser
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// newAuthProxy 需要 Basic 认证的 HTTP 代理，支持 CONNECT 和普通请求转发
+func newAuthProxy(t *testing.T, user, password string) *httptest.Server {
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != want {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		if r.Method != http.MethodConnect {
+			r.RequestURI = ""
+			r.Header.Del("Proxy-Authorization")
+			resp, err := http.DefaultTransport.RoundTrip(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer resp.Body.Close()
+			w.WriteHeader(resp.StatusCode)
+			_, _ = io.Copy(w, resp.Body)
+			return
+		}
+
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		pipe(conn, target)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// allowAllRelayPorts 测试服务器监听在随机端口上，测试期间允许中继连接任意端口
+func allowAllRelayPorts(t *testing.T) {
+	allow := relayAllowPort
+	relayAllowPort = func(string) bool { return true }
+	t.Cleanup(func() { relayAllowPort = allow })
+}
+
+func relayClient(t *testing.T, proxy string) *http.Client {
+	server, relay, err := setupProxy(configs.BrowserConfig{Proxy: proxy})
+	require.NoError(t, err)
+	require.NotNil(t, relay, "带认证的代理需要本地中继")
+	t.Cleanup(func() { _ = relay.Close() })
+
+	proxyURL, err := url.Parse(server)
+	require.NoError(t, err)
+
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func TestProxyRelayHTTPUpstream(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.URL.Path)
+	})
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	secure := httptest.NewTLSServer(handler)
+	defer secure.Close()
+
+	allowAllRelayPorts(t)
+	upstream := newAuthProxy(t, "user", "p@ss")
+	u, _ := url.Parse(upstream.URL)
+	client := relayClient(t, "http://user:p%40ss@"+u.Host)
+
+	for _, target := range []string{plain.URL + "/a", plain.URL + "/b", secure.URL + "/c"} {
+		resp, err := client.Get(target)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, target)
+		assert.Equal(t, "hello "+target[len(target)-2:], string(body))
+	}
+}
+
+func TestProxyRelayWrongCredentials(t *testing.T) {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer secure.Close()
+
+	allowAllRelayPorts(t)
+	upstream := newAuthProxy(t, "user", "secret")
+	u, _ := url.Parse(upstream.URL)
+	client := relayClient(t, "http://user:wrong@"+u.Host)
+
+	_, err := client.Get(secure.URL)
+	assert.Error(t, err)
+}
+
+func TestProxyRelayRejectsOtherPorts(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer secure.Close()
+
+	upstream := newAuthProxy(t, "user", "secret")
+	u, _ := url.Parse(upstream.URL)
+	client := relayClient(t, "http://user:secret@"+u.Host)
+
+	// 测试服务器不在 80 和 443 端口上，中继拒绝转发
+	resp, err := client.Get(plain.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	_, err = client.Get(secure.URL)
+	assert.Error(t, err)
+
+	assert.True(t, allowRelayTarget("www.xiaohongshu.com", "443"))
+	assert.True(t, allowRelayTarget("www.xiaohongshu.com:80", "443"))
+	assert.False(t, allowRelayTarget("127.0.0.1:22", "443"))
+}
+
+func TestSetupProxyWithoutAuth(t *testing.T) {
+	server, relay, err := setupProxy(configs.BrowserConfig{Proxy: "socks5h://127.0.0.1:1080"})
+	require.NoError(t, err)
+	assert.Nil(t, relay)
+	assert.Equal(t, "socks5://127.0.0.1:1080", server)
+
+	server, relay, err = setupProxy(configs.BrowserConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, relay)
+	assert.Empty(t, server)
+
+	_, _, err = setupProxy(configs.BrowserConfig{Proxy: "ftp://127.0.0.1:21"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,240 @@
+package browser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHealthCheckInterval 后台检查浏览器是否存活的间隔
+	DefaultHealthCheckInterval = 30 * time.Second
+
+	// healthCheckTimeout 单次存活检查的超时时间，浏览器在该时间内没有响应视为已经失去响应
+	healthCheckTimeout = 5 * time.Second
+)
+
+// SupervisorStats 浏览器守护统计信息
+type SupervisorStats struct {
+	Alive         bool      `json:"alive"`
+	Generation    uint64    `json:"generation"`
+	Restarts      uint64    `json:"restarts"`
+	StartedAt     time.Time `json:"started_at"`
+	LastRestartAt time.Time `json:"last_restart_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// Supervisor 浏览器守护
+// 持有当前的浏览器实例，浏览器崩溃或 CDP 连接断开时关闭旧实例并重新启动，新实例会重新加载本地保存的 cookies
+type Supervisor struct {
+	launch    func() (*Browser, error)
+	probe     func(ctx context.Context, b *Browser) error
+	close     func(b *Browser)
+	onRestart func()
+
+	mu            sync.RWMutex
+	current       *Browser
+	alive         bool // 最近一次检查的结果
+	generation    uint64
+	restarts      uint64
+	startedAt     time.Time
+	lastRestartAt time.Time
+	lastError     string
+
+	restartMu sync.Mutex // 保证同一时间只有一次重启
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSupervisor 启动浏览器并返回守护，启动参数与 Launch 相同
+// onRestart 在浏览器重启后调用，用于丢弃旧浏览器上的页面
+func NewSupervisor(headless bool, onRestart func(), options ...Option) (*Supervisor, error) {
+	return newSupervisor(
+		func() (*Browser, error) { return Launch(headless, options...) },
+		func(ctx context.Context, b *Browser) error { return b.Alive(ctx) },
+		func(b *Browser) { b.Close() },
+		onRestart,
+	)
+}
+
+func newSupervisor(launch func() (*Browser, error), probe func(context.Context, *Browser) error, close func(*Browser), onRestart func()) (*Supervisor, error) {
+	b, err := launch()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Supervisor{
+		launch:     launch,
+		probe:      probe,
+		close:      close,
+		onRestart:  onRestart,
+		current:    b,
+		alive:      true,
+		generation: 1,
+		startedAt:  time.Now(),
+		stop:       make(chan struct{}),
+	}, nil
+}
+
+// Browser 当前的浏览器实例
+func (s *Supervisor) Browser() *Browser {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Generation 浏览器实例的代数，每次重启加一，用于判断动作执行期间浏览器是否被重启过
+func (s *Supervisor) Generation() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// NewPage 在当前浏览器上创建页面
+func (s *Supervisor) NewPage() (*rod.Page, error) {
+	return s.Browser().NewPage()
+}
+
+// DevToolsURL 当前浏览器上页面的 DevTools 前端地址，见 Browser.DevToolsURL
+func (s *Supervisor) DevToolsURL(targetID proto.TargetTargetID) string {
+	return s.Browser().DevToolsURL(targetID)
+}
+
+// Check 检查浏览器是否存活，已经失去响应时重启浏览器
+// generation 为调用方观察到的代数，浏览器已经在此之后被重启过时不再重启；返回浏览器是否被重启过
+func (s *Supervisor) Check(ctx context.Context, generation uint64) (bool, error) {
+	if s.Generation() != generation {
+		return true, nil
+	}
+
+	err := s.probeCurrent(ctx)
+	if err == nil {
+		s.setAlive(generation, true)
+		return false, nil
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	s.setAlive(generation, false)
+	logrus.Warnf("浏览器已失去响应: %v", err)
+
+	if err := s.restart(generation); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// probeCurrent 检查当前浏览器是否存活
+func (s *Supervisor) probeCurrent(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	return s.probe(ctx, s.Browser())
+}
+
+// setAlive 记录代数为 generation 的浏览器的检查结果，浏览器已经被重启过时忽略
+func (s *Supervisor) setAlive(generation uint64, alive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation == generation {
+		s.alive = alive
+	}
+}
+
+// restart 关闭代数为 generation 的浏览器并重新启动，其他调用方已经重启过时直接返回
+func (s *Supervisor) restart(generation uint64) error {
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+
+	if s.isStopped() {
+		return errors.New("浏览器守护已关闭")
+	}
+	if s.Generation() != generation {
+		return nil
+	}
+
+	old := s.Browser()
+	s.close(old)
+
+	b, err := s.launch()
+	if err != nil {
+		s.mu.Lock()
+		s.lastError = err.Error()
+		s.mu.Unlock()
+		return errors.Wrap(err, "重启浏览器失败")
+	}
+
+	s.mu.Lock()
+	s.current = b
+	s.alive = true
+	s.generation++
+	s.restarts++
+	s.lastRestartAt = time.Now()
+	s.lastError = ""
+	restarts := s.restarts
+	s.mu.Unlock()
+
+	logrus.Warnf("浏览器已重启（第 %d 次），已重新加载 cookies", restarts)
+
+	if s.onRestart != nil {
+		s.onRestart()
+	}
+	return nil
+}
+
+// Watch 定期检查浏览器是否存活并在失去响应时重启，直到 Close
+func (s *Supervisor) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if _, err := s.Check(context.Background(), s.Generation()); err != nil {
+				logrus.Errorf("浏览器存活检查失败: %v", err)
+			}
+		}
+	}
+}
+
+// Stats 浏览器守护统计信息，Alive 为最近一次检查的结果
+func (s *Supervisor) Stats() SupervisorStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return SupervisorStats{
+		Alive:         s.alive,
+		Generation:    s.generation,
+		Restarts:      s.restarts,
+		StartedAt:     s.startedAt,
+		LastRestartAt: s.lastRestartAt,
+		LastError:     s.lastError,
+	}
+}
+
+// Close 停止检查并关闭当前浏览器
+func (s *Supervisor) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	s.close(s.Browser())
+}
+
+func (s *Supervisor) isStopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrowsers 记录测试中启动和关闭的浏览器，dead 中的浏览器存活检查失败
+type fakeBrowsers struct {
+	mu       sync.Mutex
+	launched []*Browser
+	closed   []*Browser
+	dead     map[*Browser]bool
+	failNext bool
+}
+
+func newTestSupervisor(t *testing.T, f *fakeBrowsers, onRestart func()) *Supervisor {
+	f.dead = make(map[*Browser]bool)
+
+	s, err := newSupervisor(
+		func() (*Browser, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.failNext {
+				f.failNext = false
+				return nil, errors.New("launch failed")
+			}
+			b := &Browser{}
+			f.launched = append(f.launched, b)
+			return b, nil
+		},
+		func(_ context.Context, b *Browser) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.dead[b] {
+				return errors.New("websocket closed")
+			}
+			return nil
+		},
+		func(b *Browser) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.closed = append(f.closed, b)
+		},
+		onRestart,
+	)
+	require.NoError(t, err)
+	return s
+}
+
+func (f *fakeBrowsers) kill(b *Browser) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dead[b] = true
+}
+
+func TestSupervisorKeepsHealthyBrowser(t *testing.T) {
+	f := &fakeBrowsers{}
+	s := newTestSupervisor(t, f, nil)
+
+	restarted, err := s.Check(context.Background(), s.Generation())
+	require.NoError(t, err)
+	assert.False(t, restarted)
+	assert.Len(t, f.launched, 1)
+	assert.Equal(t, uint64(0), s.Stats().Restarts)
+}
+
+func TestSupervisorRestartsDeadBrowser(t *testing.T) {
+	f := &fakeBrowsers{}
+	var restarts int32
+	s := newTestSupervisor(t, f, func() { atomic.AddInt32(&restarts, 1) })
+
+	first := s.Browser()
+	generation := s.Generation()
+	f.kill(first)
+
+	restarted, err := s.Check(context.Background(), generation)
+	require.NoError(t, err)
+	assert.True(t, restarted)
+	assert.NotSame(t, first, s.Browser())
+	assert.Equal(t, []*Browser{first}, f.closed)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&restarts))
+
+	stats := s.Stats()
+	assert.True(t, stats.Alive)
+	assert.Equal(t, uint64(1), stats.Restarts)
+	assert.Equal(t, generation+1, stats.Generation)
+
+	// 其他动作以旧代数检查时不会再次重启
+	restarted, err = s.Check(context.Background(), generation)
+	require.NoError(t, err)
+	assert.True(t, restarted)
+	assert.Len(t, f.launched, 2)
+}
+
+func TestSupervisorRestartFailure(t *testing.T) {
+	f := &fakeBrowsers{}
+	s := newTestSupervisor(t, f, nil)
+
+	generation := s.Generation()
+	f.kill(s.Browser())
+	f.failNext = true
+
+	_, err := s.Check(context.Background(), generation)
+	assert.Error(t, err)
+
+	stats := s.Stats()
+	assert.False(t, stats.Alive)
+	assert.Equal(t, "launch failed", stats.LastError)
+
+	// 下一次检查再次尝试重启
+	restarted, err := s.Check(context.Background(), generation)
+	require.NoError(t, err)
+	assert.True(t, restarted)
+	assert.Empty(t, s.Stats().LastError)
+}
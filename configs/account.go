@@ -0,0 +1,94 @@
+package configs
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Username 未配置账号时使用的默认账号名
+	Username = "xiaohongshu-mcp"
+)
+
+// accountNamePattern 账号名只能包含字母、数字、下划线和短横线，账号名会用于 cookies 文件名
+var accountNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var (
+	defaultAccountMu sync.RWMutex
+	defaultAccount   string
+)
+
+// ValidateAccountName 检查账号名是否合法
+func ValidateAccountName(name string) error {
+	if !accountNamePattern.MatchString(name) {
+		return errors.Errorf("账号名 %q 不合法，只能包含字母、数字、下划线和短横线", name)
+	}
+	return nil
+}
+
+// InitDefaultAccount 设置命令行指定的默认账号，优先级高于配置文件中的 default_account
+func InitDefaultAccount(name string) error {
+	if name == "" {
+		return nil
+	}
+	if err := ValidateAccountName(name); err != nil {
+		return err
+	}
+
+	defaultAccountMu.Lock()
+	defaultAccount = name
+	defaultAccountMu.Unlock()
+	return nil
+}
+
+// GetDefaultAccount 请求没有指定账号时使用的账号
+// 优先级从高到低：命令行参数或 SetDefaultAccount、配置文件 default_account、Username
+func GetDefaultAccount() string {
+	defaultAccountMu.RLock()
+	name := defaultAccount
+	defaultAccountMu.RUnlock()
+
+	if name != "" {
+		return name
+	}
+	if file := getFileConfig(); file.DefaultAccount != "" {
+		return file.DefaultAccount
+	}
+	return Username
+}
+
+// SetDefaultAccount 切换默认账号，账号必须已经配置
+func SetDefaultAccount(name string) error {
+	if !HasAccount(name) {
+		return errors.Errorf("账号 %s 不存在", name)
+	}
+
+	defaultAccountMu.Lock()
+	defaultAccount = name
+	defaultAccountMu.Unlock()
+	return nil
+}
+
+// AccountNames 所有账号名，包括配置文件 accounts 中的账号和默认账号，按名称排序
+func AccountNames() []string {
+	names := []string{GetDefaultAccount()}
+	for name := range getFileConfig().Accounts {
+		if name != names[0] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasAccount 账号是否存在
+func HasAccount(name string) bool {
+	if name == GetDefaultAccount() {
+		return true
+	}
+	_, ok := getFileConfig().Accounts[name]
+	return ok
+}
@@ -0,0 +1,26 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	ArtifactsDir = "xiaohongshu_artifacts"
+)
+
+var (
+	artifactsPath = ""
+)
+
+func InitArtifactsPath(path string) {
+	artifactsPath = path
+}
+
+// GetArtifactsPath 动作失败现场的保存目录，未指定时使用系统临时目录。
+func GetArtifactsPath() string {
+	if artifactsPath != "" {
+		return artifactsPath
+	}
+	return filepath.Join(os.TempDir(), ArtifactsDir)
+}
@@ -1,5 +1,14 @@
 package configs
 
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 var (
 	useHeadless = true
 )
@@ -12,3 +21,177 @@ func InitHeadless(h bool) {
 func IsHeadless() bool {
 	return useHeadless
 }
+
+var (
+	maxPages = 3
+)
+
+func InitMaxPages(n int) {
+	maxPages = n
+}
+
+// GetMaxPages 每个账号同时打开的浏览器页面上限，各账号的页面池分别计数。
+func GetMaxPages() int {
+	return maxPages
+}
+
+var (
+	readConcurrency = 2
+)
+
+func InitReadConcurrency(n int) {
+	readConcurrency = n
+}
+
+// GetReadConcurrency 同一账号只读动作的并行上限。
+func GetReadConcurrency() int {
+	return readConcurrency
+}
+
+var (
+	verificationTimeout = 10 * time.Minute
+)
+
+func InitVerificationTimeout(d time.Duration) {
+	verificationTimeout = d
+}
+
+// GetVerificationTimeout 遇到验证码时等待人工处理的最长时间，为 0 时不等待直接失败。
+func GetVerificationTimeout() time.Duration {
+	return verificationTimeout
+}
+
+const (
+	// DefaultUserAgent 默认的浏览器 User-Agent
+	DefaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	// DefaultLocale 默认的浏览器语言
+	DefaultLocale = "zh-CN"
+	// DefaultTimezone 默认的浏览器时区，与 PublishTime 的解析时区一致
+	DefaultTimezone = "Asia/Shanghai"
+	// DefaultViewport 默认的页面视口大小
+	DefaultViewport = "1280x800"
+)
+
+// BrowserConfig 浏览器启动参数，为空的字段使用默认值
+type BrowserConfig struct {
+	// Proxy 代理地址，支持 http://[user:pass@]host:port 和 socks5://[user:pass@]host:port
+	Proxy     string `json:"proxy,omitempty" yaml:"proxy"`
+	UserAgent string `json:"user_agent,omitempty" yaml:"user_agent"`
+	Locale    string `json:"locale,omitempty" yaml:"locale"`
+	Timezone  string `json:"timezone,omitempty" yaml:"timezone"`
+	// Viewport 视口大小，格式为 宽x高，例如 1440x900
+	Viewport string `json:"viewport,omitempty" yaml:"viewport"`
+}
+
+// Merge 用 o 中不为空的字段覆盖当前配置
+func (c BrowserConfig) Merge(o BrowserConfig) BrowserConfig {
+	if o.Proxy != "" {
+		c.Proxy = o.Proxy
+	}
+	if o.UserAgent != "" {
+		c.UserAgent = o.UserAgent
+	}
+	if o.Locale != "" {
+		c.Locale = o.Locale
+	}
+	if o.Timezone != "" {
+		c.Timezone = o.Timezone
+	}
+	if o.Viewport != "" {
+		c.Viewport = o.Viewport
+	}
+	return c
+}
+
+// ViewportSize 解析视口宽高
+func (c BrowserConfig) ViewportSize() (width, height int, err error) {
+	viewport := c.Viewport
+	if viewport == "" {
+		viewport = DefaultViewport
+	}
+
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(viewport)), "x")
+	if ok {
+		width, err = strconv.Atoi(strings.TrimSpace(w))
+		if err == nil {
+			height, err = strconv.Atoi(strings.TrimSpace(h))
+		}
+	}
+	if !ok || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, errors.Errorf("视口大小 %q 格式错误，应为 宽x高，例如 1440x900", c.Viewport)
+	}
+	return width, height, nil
+}
+
+// ProxyURL 解析代理地址，没有协议时按 http 处理，socks5h 按 socks5 处理；未配置代理时返回 nil
+func (c BrowserConfig) ProxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(c.Proxy)
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, errors.New("代理地址格式错误")
+	}
+
+	switch u.Scheme {
+	case "http", "socks5":
+	case "socks5h":
+		u.Scheme = "socks5"
+	default:
+		return nil, errors.Errorf("不支持的代理协议 %s，仅支持 http 和 socks5", u.Scheme)
+	}
+
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, errors.Errorf("代理地址 %s 缺少主机或端口", u.Redacted())
+	}
+	return u, nil
+}
+
+// Validate 检查代理地址、视口和时区是否合法
+func (c BrowserConfig) Validate() error {
+	if _, err := c.ProxyURL(); err != nil {
+		return err
+	}
+	if _, _, err := c.ViewportSize(); err != nil {
+		return err
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return errors.Wrapf(err, "时区 %q 不合法", c.Timezone)
+		}
+	}
+	return nil
+}
+
+var (
+	browserFlags BrowserConfig
+)
+
+// InitBrowserConfig 设置命令行指定的浏览器参数，优先级高于配置文件中的全局浏览器参数
+func InitBrowserConfig(c BrowserConfig) {
+	browserFlags = c
+}
+
+// GetBrowserConfig 账号使用的浏览器参数
+// 优先级从低到高：默认值、配置文件 browser、命令行参数、配置文件 accounts.<账号>.browser
+func GetBrowserConfig(account string) BrowserConfig {
+	c := BrowserConfig{
+		UserAgent: DefaultUserAgent,
+		Locale:    DefaultLocale,
+		Timezone:  DefaultTimezone,
+		Viewport:  DefaultViewport,
+	}
+
+	file := getFileConfig()
+	c = c.Merge(file.Browser)
+	c = c.Merge(browserFlags)
+	if accountConfig, ok := file.Accounts[account]; ok {
+		c = c.Merge(accountConfig.Browser)
+	}
+	return c
+}
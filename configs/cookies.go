@@ -0,0 +1,245 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/cookies"
+)
+
+const (
+	// CookiesBackendFile 明文 JSON 文件，默认后端
+	CookiesBackendFile = "file"
+	// CookiesBackendEncrypted AES-GCM 加密文件
+	CookiesBackendEncrypted = "encrypted"
+	// CookiesBackendMemory 只保存在进程内存中，cookies 通过环境变量或标准输入导入
+	CookiesBackendMemory = "memory"
+)
+
+const (
+	// CookiesKeyEnv 加密后端的密钥环境变量，没有配置 key_file 时使用
+	CookiesKeyEnv = "XHS_COOKIES_KEY"
+	// CookiesEnv 导入默认账号 cookies 的环境变量，其他账号使用 XHS_COOKIES_<账号>
+	CookiesEnv = "XHS_COOKIES"
+)
+
+// CookiesConfig cookies 的存储方式
+type CookiesConfig struct {
+	// Backend 存储后端：file（默认）、encrypted 或 memory
+	Backend string `json:"backend,omitempty" yaml:"backend"`
+	// Dir cookies 目录，每个账号一个子目录，默认见 cookies.DefaultDir
+	Dir string `json:"dir,omitempty" yaml:"dir"`
+	// KeyFile 加密后端的密钥文件，为空时从环境变量 XHS_COOKIES_KEY 读取
+	KeyFile string `json:"key_file,omitempty" yaml:"key_file"`
+}
+
+// Merge 用 o 中不为空的字段覆盖当前配置
+func (c CookiesConfig) Merge(o CookiesConfig) CookiesConfig {
+	if o.Backend != "" {
+		c.Backend = o.Backend
+	}
+	if o.Dir != "" {
+		c.Dir = o.Dir
+	}
+	if o.KeyFile != "" {
+		c.KeyFile = o.KeyFile
+	}
+	return c
+}
+
+// Validate 检查存储后端是否合法
+func (c CookiesConfig) Validate() error {
+	switch c.Backend {
+	case "", CookiesBackendFile, CookiesBackendEncrypted, CookiesBackendMemory:
+		return nil
+	default:
+		return errors.Errorf("不支持的 cookies 存储后端 %q，仅支持 file、encrypted 和 memory", c.Backend)
+	}
+}
+
+var (
+	cookiesFlags CookiesConfig
+	cookiesKey   []byte
+
+	memoryCookiesMu sync.Mutex
+	memoryCookies   = make(map[string]cookies.Cookier)
+)
+
+// InitCookiesConfig 设置命令行指定的 cookies 存储参数，优先级高于配置文件中的 cookies
+// 使用加密后端时读取密钥，密钥缺失时返回错误
+func InitCookiesConfig(c CookiesConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	cookiesFlags = c
+
+	cfg := GetCookiesConfig()
+	if cfg.Backend != CookiesBackendEncrypted {
+		return nil
+	}
+
+	key, err := loadCookiesKey(cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	cookiesKey = key
+	return nil
+}
+
+// loadCookiesKey 读取加密后端的密钥，配置了密钥文件时读取文件，否则读取环境变量
+// 密钥必须是 32 字节的随机数，见 cookies.ParseKey
+func loadCookiesKey(keyFile string) ([]byte, error) {
+	if keyFile != "" {
+		raw, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "读取 cookies 密钥文件失败")
+		}
+		return cookies.ParseKey(raw)
+	}
+
+	if raw := os.Getenv(CookiesKeyEnv); raw != "" {
+		return cookies.ParseKey([]byte(raw))
+	}
+	return nil, errors.Errorf("encrypted 后端需要密钥，请设置环境变量 %s 或配置 key_file", CookiesKeyEnv)
+}
+
+// GetCookiesConfig cookies 的存储方式
+// 优先级从低到高：默认值、配置文件 cookies、命令行参数
+func GetCookiesConfig() CookiesConfig {
+	c := CookiesConfig{
+		Backend: CookiesBackendFile,
+		Dir:     cookies.DefaultDir(),
+	}
+	c = c.Merge(getFileConfig().Cookies)
+	c = c.Merge(cookiesFlags)
+	return c
+}
+
+// GetCookiesPath 账号的 cookies 文件路径，memory 后端返回空字符串
+// 配置文件 accounts.<账号>.cookies 指定时使用指定路径，否则为 cookies 目录下的 <账号>/cookies.json
+func GetCookiesPath(account string) string {
+	cfg := GetCookiesConfig()
+	if cfg.Backend == CookiesBackendMemory {
+		return ""
+	}
+
+	if accountConfig, ok := getFileConfig().Accounts[account]; ok && accountConfig.Cookies != "" {
+		return accountConfig.Cookies
+	}
+
+	name := "cookies.json"
+	if cfg.Backend == CookiesBackendEncrypted {
+		name = "cookies.json.enc"
+	}
+	return filepath.Join(cfg.Dir, account, name)
+}
+
+// legacyCookiesPath 旧版本保存在系统临时目录中的 cookies 文件，账号指定了 cookies 文件时没有旧文件
+func legacyCookiesPath(account string) string {
+	if accountConfig, ok := getFileConfig().Accounts[account]; ok && accountConfig.Cookies != "" {
+		return ""
+	}
+
+	path := cookies.GetCookiesFilePath()
+	if account == Username {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), "cookies-"+account+".json")
+}
+
+// NewCookieStore 账号的 cookies 存储
+// 新位置还没有 cookies 时，把旧版本临时目录中的明文文件迁移过来并删除旧文件；
+// 加密后端还会迁移 file 后端留下的明文文件
+func NewCookieStore(account string) (cookies.Cookier, error) {
+	cfg := GetCookiesConfig()
+	if cfg.Backend == CookiesBackendMemory {
+		memoryCookiesMu.Lock()
+		defer memoryCookiesMu.Unlock()
+
+		store, ok := memoryCookies[account]
+		if !ok {
+			store = cookies.NewMemoryCookie()
+			memoryCookies[account] = store
+		}
+		return store, nil
+	}
+
+	path := GetCookiesPath(account)
+	var (
+		store cookies.Cookier
+		err   error
+	)
+	if cfg.Backend == CookiesBackendEncrypted {
+		store, err = cookies.NewEncryptedCookie(path, cookiesKey)
+	} else {
+		store, err = cookies.NewFileCookie(path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "账号 %s", account)
+	}
+
+	legacyPaths := []string{legacyCookiesPath(account)}
+	if cfg.Backend == CookiesBackendEncrypted {
+		legacyPaths = append(legacyPaths, filepath.Join(cfg.Dir, account, "cookies.json"))
+	}
+	for _, legacy := range legacyPaths {
+		if legacy == "" || legacy == path {
+			continue
+		}
+		if _, err := cookies.Migrate(store, legacy); err != nil {
+			logrus.Warnf("迁移账号 %s 的旧 cookies 失败: %v", account, err)
+		}
+	}
+	return store, nil
+}
+
+// CookiesEnvName 导入账号 cookies 的环境变量名，账号名转为大写，短横线替换为下划线
+func CookiesEnvName(account string) string {
+	return CookiesEnv + "_" + strings.ToUpper(strings.ReplaceAll(account, "-", "_"))
+}
+
+// ImportCookies 导入账号的 cookies，内容为 JSON 数组或其 base64 编码，覆盖已经保存的 cookies
+func ImportCookies(account string, raw []byte) error {
+	store, err := NewCookieStore(account)
+	if err != nil {
+		return err
+	}
+	if err := cookies.Import(store, raw); err != nil {
+		return errors.Wrapf(err, "导入账号 %s 的 cookies 失败", account)
+	}
+	return nil
+}
+
+// ImportCookiesFromEnv 从环境变量导入各账号的 cookies，返回导入的账号
+// 默认账号也可以使用 XHS_COOKIES；账号已经保存过 cookies 时不导入，避免覆盖运行中刷新过的 cookies
+func ImportCookiesFromEnv() ([]string, error) {
+	defaultAccount := GetDefaultAccount()
+
+	var imported []string
+	for _, account := range AccountNames() {
+		raw := os.Getenv(CookiesEnvName(account))
+		if raw == "" && account == defaultAccount {
+			raw = os.Getenv(CookiesEnv)
+		}
+		if raw == "" {
+			continue
+		}
+
+		store, err := NewCookieStore(account)
+		if err != nil {
+			return imported, err
+		}
+		if _, err := store.LoadCookies(); err == nil {
+			continue
+		}
+		if err := cookies.Import(store, []byte(raw)); err != nil {
+			return imported, errors.Wrapf(err, "从环境变量导入账号 %s 的 cookies 失败", account)
+		}
+		imported = append(imported, account)
+	}
+	return imported, nil
+}
@@ -0,0 +1,36 @@
+package configs
+
+import "time"
+
+var (
+	sessionCheckInterval = 30 * time.Minute
+	sessionExpiryWarning = 72 * time.Hour
+	sessionWebhook       = ""
+)
+
+func InitSessionCheckInterval(d time.Duration) {
+	sessionCheckInterval = d
+}
+
+// GetSessionCheckInterval 定期检查账号登录状态的间隔，为 0 时不定期检查。
+func GetSessionCheckInterval() time.Duration {
+	return sessionCheckInterval
+}
+
+func InitSessionExpiryWarning(d time.Duration) {
+	sessionExpiryWarning = d
+}
+
+// GetSessionExpiryWarning 登录 cookie 剩余有效期少于该时长时发出即将过期的告警。
+func GetSessionExpiryWarning() time.Duration {
+	return sessionExpiryWarning
+}
+
+func InitSessionWebhook(url string) {
+	sessionWebhook = url
+}
+
+// GetSessionWebhook 会话告警的 webhook 地址，为空时不发送。
+func GetSessionWebhook() string {
+	return sessionWebhook
+}
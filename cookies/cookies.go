@@ -1,54 +1,137 @@
 package cookies
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// Cookier cookies 的存储后端，保存的内容是浏览器 cookies 的 JSON 数组
 type Cookier interface {
 	LoadCookies() ([]byte, error)
 	SaveCookies(data []byte) error
 }
 
-type localCookie struct {
+const (
+	// fileMode cookies 文件的权限，只有当前用户可以读写
+	fileMode = 0600
+	// dirMode cookies 目录的权限
+	dirMode = 0700
+)
+
+type fileCookie struct {
 	path string
 }
 
-func NewLoadCookie(path string) Cookier {
+// NewFileCookie 以明文 JSON 文件保存 cookies，文件权限为 0600
+func NewFileCookie(path string) (Cookier, error) {
 	if path == "" {
-		panic("path is required")
+		return nil, errors.New("cookies 文件路径不能为空")
 	}
-
-	if err := os.MkdirAll(filepath.Dir(path), 0644); err != nil {
-		panic(err)
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return nil, errors.Wrap(err, "创建 cookies 目录失败")
 	}
 
-	return &localCookie{
-		path: path,
-	}
+	return &fileCookie{path: path}, nil
 }
 
 // LoadCookies 从文件中加载 cookies。
-func (c *localCookie) LoadCookies() ([]byte, error) {
-
+func (c *fileCookie) LoadCookies() ([]byte, error) {
 	data, err := os.ReadFile(c.path)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to read cookies from tmp file")
+		return nil, errors.Wrapf(err, "读取 cookies 文件 %s 失败", c.path)
 	}
 
 	return data, nil
 }
 
 // SaveCookies 保存 cookies 到文件中。
-func (c *localCookie) SaveCookies(data []byte) error {
-	return os.WriteFile(c.path, data, 0644)
+func (c *fileCookie) SaveCookies(data []byte) error {
+	return writeFile(c.path, data)
+}
+
+// writeFile 先写临时文件再重命名，写入中途失败不会损坏已有的 cookies
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "创建 cookies 临时文件失败")
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "设置 cookies 文件权限失败")
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "写入 cookies 失败")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "写入 cookies 失败")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "保存 cookies 到 %s 失败", path)
+	}
+	return nil
+}
+
+// Migrate 把旧位置的明文 cookies 文件迁移到 dst，返回是否迁移
+// dst 已经有 cookies 或旧文件不存在时不做处理；迁移成功后删除旧文件，删除失败时把旧文件改为 0600
+func Migrate(dst Cookier, legacyPath string) (bool, error) {
+	if _, err := dst.LoadCookies(); !errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(legacyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "读取旧的 cookies 文件 %s 失败", legacyPath)
+	}
+	if err := Validate(data); err != nil {
+		return false, errors.Wrapf(err, "旧的 cookies 文件 %s 格式错误", legacyPath)
+	}
+	if err := dst.SaveCookies(data); err != nil {
+		return false, err
+	}
+	logrus.Warnf("已把明文 cookies 文件 %s 迁移到新的 cookies 存储", legacyPath)
+
+	if err := os.Remove(legacyPath); err != nil {
+		if chmodErr := os.Chmod(legacyPath, fileMode); chmodErr != nil {
+			logrus.Warnf("旧的明文 cookies 文件 %s 已迁移，但无法删除或修改权限，请手动删除: %v", legacyPath, err)
+		} else {
+			logrus.Warnf("旧的明文 cookies 文件 %s 已迁移，但无法删除，已改为仅当前用户可读，请手动删除: %v", legacyPath, err)
+		}
+	}
+	return true, nil
+}
+
+// Validate 检查数据是否为 cookies 的 JSON 数组
+func Validate(data []byte) error {
+	var cks []json.RawMessage
+	if err := json.Unmarshal(data, &cks); err != nil {
+		return errors.Wrap(err, "cookies 应为浏览器 cookies 的 JSON 数组")
+	}
+	return nil
 }
 
-// GetCookiesFilePath 获取 cookies 文件路径。
+// GetCookiesFilePath 旧版本的 cookies 文件路径，位于系统临时目录，可能被系统清理。
 func GetCookiesFilePath() string {
 	tmpDir := os.TempDir()
 	filePath := filepath.Join(tmpDir, "cookies.json")
 	return filePath
 }
+
+// DefaultDir 默认的 cookies 目录，位于用户配置目录下，不会随临时目录被清理
+// 无法获取用户配置目录时使用系统临时目录
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "xiaohongshu-mcp", "cookies")
+}
@@ -0,0 +1,148 @@
+package cookies
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCookies = `[{"name":"web_session","value":"abc","domain":".xiaohongshu.com"}]`
+
+func TestFileCookie(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brand-a", "cookies.json")
+	c, err := NewFileCookie(path)
+	require.NoError(t, err)
+
+	_, err = c.LoadCookies()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	require.NoError(t, c.SaveCookies([]byte(testCookies)))
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, testCookies, string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = NewFileCookie("")
+	assert.Error(t, err)
+}
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	key, err = ParseKey([]byte(base64.StdEncoding.EncodeToString(testKey) + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	key, err = ParseKey([]byte(hex.EncodeToString(testKey)))
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	// 口令和长度不对的密钥都不接受
+	_, err = ParseKey([]byte("secret"))
+	assert.Error(t, err)
+	_, err = ParseKey([]byte(base64.StdEncoding.EncodeToString(testKey[:16])))
+	assert.Error(t, err)
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json.enc")
+	c, err := NewEncryptedCookie(path, testKey)
+	require.NoError(t, err)
+
+	require.NoError(t, c.SaveCookies([]byte(testCookies)))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "web_session")
+
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, testCookies, string(data))
+
+	wrong, err := NewEncryptedCookie(path, bytes.Repeat([]byte{0x24}, KeySize))
+	require.NoError(t, err)
+	_, err = wrong.LoadCookies()
+	assert.ErrorContains(t, err, "解密")
+
+	_, err = NewEncryptedCookie(path, []byte("secret"))
+	assert.Error(t, err)
+}
+
+func TestEncryptedCookieRejectsPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	require.NoError(t, os.WriteFile(path, []byte(testCookies), 0600))
+
+	c, err := NewEncryptedCookie(path, testKey)
+	require.NoError(t, err)
+	_, err = c.LoadCookies()
+	assert.ErrorContains(t, err, "不是加密格式")
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.json")
+	require.NoError(t, os.WriteFile(legacy, []byte(testCookies), 0644))
+
+	dst, err := NewEncryptedCookie(filepath.Join(dir, "new", "cookies.json.enc"), testKey)
+	require.NoError(t, err)
+
+	migrated, err := Migrate(dst, legacy)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	data, err := dst.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, testCookies, string(data))
+
+	// 迁移后删除旧的明文文件
+	_, err = os.Stat(legacy)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// 新的存储已经有 cookies 时不再读取旧文件
+	require.NoError(t, os.WriteFile(legacy, []byte(`[]`), 0600))
+	migrated, err = Migrate(dst, legacy)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+	data, err = dst.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, testCookies, string(data))
+
+	// 旧文件格式错误时不迁移
+	empty := NewMemoryCookie()
+	require.NoError(t, os.WriteFile(legacy, []byte("not json"), 0600))
+	_, err = Migrate(empty, legacy)
+	assert.Error(t, err)
+}
+
+func TestImport(t *testing.T) {
+	c := NewMemoryCookie()
+	_, err := c.LoadCookies()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	require.NoError(t, Import(c, []byte(" "+testCookies+"\n")))
+	data, err := c.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, testCookies, string(data))
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(`[]`))
+	require.NoError(t, Import(c, []byte(encoded)))
+	data, err = c.LoadCookies()
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(data))
+
+	assert.Error(t, Import(c, []byte("")))
+	assert.Error(t, Import(c, []byte(`{"name":"web_session"}`)))
+	assert.Error(t, Import(c, []byte("not base64!")))
+}
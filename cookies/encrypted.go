@@ -0,0 +1,113 @@
+package cookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// KeySize 加密密钥的长度，AES-256 需要 32 字节
+const KeySize = 32
+
+// encryptedMagic 加密 cookies 文件的头部，用于区分明文文件
+var encryptedMagic = []byte("XHSCK1")
+
+// ParseKey 解析加密密钥，密钥必须是 32 字节的随机数，可以是原始字节、base64 或十六进制编码
+// 不接受口令，口令没有足够的随机性，例如可以用 openssl rand -base64 32 生成密钥
+func ParseKey(raw []byte) ([]byte, error) {
+	if len(raw) == KeySize {
+		return raw, nil
+	}
+
+	text := string(bytes.TrimSpace(raw))
+	for _, decode := range []func(string) ([]byte, error){
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+		hex.DecodeString,
+	} {
+		if key, err := decode(text); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, errors.New("cookies 加密密钥必须是 32 字节的随机数（原始字节、base64 或十六进制编码），可以用 openssl rand -base64 32 生成")
+}
+
+type encryptedCookie struct {
+	path string
+	aead cipher.AEAD
+}
+
+// NewEncryptedCookie 以 AES-256-GCM 加密保存 cookies，文件权限为 0600
+// key 为 ParseKey 解析得到的 32 字节密钥
+func NewEncryptedCookie(path string, key []byte) (Cookier, error) {
+	if path == "" {
+		return nil, errors.New("cookies 文件路径不能为空")
+	}
+	if len(key) != KeySize {
+		return nil, errors.Errorf("cookies 加密密钥长度应为 %d 字节", KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "初始化 cookies 加密失败")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "初始化 cookies 加密失败")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return nil, errors.Wrap(err, "创建 cookies 目录失败")
+	}
+
+	return &encryptedCookie{path: path, aead: aead}, nil
+}
+
+// LoadCookies 读取并解密 cookies，不接受明文文件，明文 cookies 需要通过 Migrate 或导入写入。
+func (c *encryptedCookie) LoadCookies() ([]byte, error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取 cookies 文件 %s 失败", c.path)
+	}
+
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return nil, errors.Errorf("cookies 文件 %s 不是加密格式，明文 cookies 请通过 -cookies-stdin 或环境变量导入", c.path)
+	}
+
+	data = data[len(encryptedMagic):]
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.Errorf("cookies 文件 %s 格式错误", c.path)
+	}
+
+	plain, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], encryptedMagic)
+	if err != nil {
+		return nil, errors.Errorf("解密 cookies 文件 %s 失败，密钥错误或文件已损坏", c.path)
+	}
+	return plain, nil
+}
+
+// SaveCookies 加密保存 cookies，每次保存使用新的随机 nonce。
+func (c *encryptedCookie) SaveCookies(data []byte) error {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "生成随机数失败")
+	}
+
+	out := make([]byte, 0, len(encryptedMagic)+len(nonce)+len(data)+c.aead.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, nonce...)
+	out = c.aead.Seal(out, nonce, data, encryptedMagic)
+
+	return writeFile(c.path, out)
+}
@@ -0,0 +1,73 @@
+package cookies
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type memoryCookie struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+// NewMemoryCookie 只在进程内存中保存 cookies，不写磁盘，适合只读文件系统的容器
+// 初始 cookies 通过 Import 从环境变量或标准输入导入，浏览器重启时仍然可以加载
+func NewMemoryCookie() Cookier {
+	return &memoryCookie{}
+}
+
+// LoadCookies 读取内存中的 cookies。
+func (c *memoryCookie) LoadCookies() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.data == nil {
+		return nil, errors.Wrap(os.ErrNotExist, "没有导入或保存过 cookies")
+	}
+	return bytes.Clone(c.data), nil
+}
+
+// SaveCookies 保存 cookies 到内存中。
+func (c *memoryCookie) SaveCookies(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = bytes.Clone(data)
+	return nil
+}
+
+// Decode 解析导入的 cookies，支持 JSON 数组和 base64 编码的 JSON 数组
+// 环境变量中不方便写引号时可以使用 base64
+func Decode(raw []byte) ([]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errors.New("导入的 cookies 为空")
+	}
+
+	data := raw
+	if raw[0] != '[' {
+		decoded, err := base64.StdEncoding.DecodeString(string(raw))
+		if err != nil {
+			return nil, errors.New("导入的 cookies 应为 JSON 数组或其 base64 编码")
+		}
+		data = bytes.TrimSpace(decoded)
+	}
+
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Import 解析导入的 cookies 并保存到 c
+func Import(c Cookier, raw []byte) error {
+	data, err := Decode(raw)
+	if err != nil {
+		return err
+	}
+	return c.SaveCookies(data)
+}
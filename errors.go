@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/artifacts"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// actionErrorKind 动作失败原因在 HTTP 和 MCP 层的表示
+type actionErrorKind struct {
+	// HTTPStatus HTTP 接口返回的状态码
+	HTTPStatus int
+	// Code HTTP 响应和 MCP 工具错误中的错误码
+	Code string
+	// RPCCode JSON-RPC 错误码，用于 resources/read、prompts/get 等直接返回 JSON-RPC 错误的方法
+	RPCCode int
+}
+
+// 类型化错误对应的错误码，JSON-RPC 错误码使用实现自定义的 -32000 ~ -32099 区间
+var (
+	errKindNotLoggedIn       = actionErrorKind{http.StatusUnauthorized, "NOT_LOGGED_IN", -32010}
+	errKindCaptchaRequired   = actionErrorKind{http.StatusForbidden, "CAPTCHA_REQUIRED", -32011}
+	errKindRateLimited       = actionErrorKind{http.StatusTooManyRequests, "RATE_LIMITED", -32012}
+	errKindNavigationTimeout = actionErrorKind{http.StatusGatewayTimeout, "NAVIGATION_TIMEOUT", -32013}
+	errKindElementNotFound   = actionErrorKind{http.StatusBadGateway, "ELEMENT_NOT_FOUND", -32014}
+)
+
+// classifyActionError 根据 xiaohongshu 包返回的类型化错误判断失败原因
+// 无法识别的错误返回 false，由调用方按各自的默认错误处理
+func classifyActionError(err error) (actionErrorKind, bool) {
+	var notFound *xiaohongshu.ErrElementNotFound
+
+	switch {
+	case errors.Is(err, xiaohongshu.ErrNotLoggedIn):
+		return errKindNotLoggedIn, true
+	case errors.Is(err, xiaohongshu.ErrCaptchaRequired):
+		return errKindCaptchaRequired, true
+	case errors.Is(err, xiaohongshu.ErrRateLimited):
+		return errKindRateLimited, true
+	case errors.Is(err, xiaohongshu.ErrNavigationTimeout):
+		return errKindNavigationTimeout, true
+	case errors.As(err, &notFound):
+		return errKindElementNotFound, true
+	default:
+		return actionErrorKind{}, false
+	}
+}
+
+// artifactError 附带失败现场的动作错误，Unwrap 后仍然可以判断原始的类型化错误
+type artifactError struct {
+	err      error
+	artifact *artifacts.Artifact
+}
+
+func (e *artifactError) Error() string {
+	return e.err.Error()
+}
+
+func (e *artifactError) Unwrap() error {
+	return e.err
+}
+
+// ArtifactRef 错误响应中引用的失败现场
+type ArtifactRef struct {
+	ID  string `json:"id"`
+	Dir string `json:"dir"`
+	URL string `json:"url"`
+}
+
+// artifactRefFromError 从错误中取出失败现场的引用，没有保存现场时返回 nil
+func artifactRefFromError(err error) *ArtifactRef {
+	var ae *artifactError
+	if !errors.As(err, &ae) {
+		return nil
+	}
+
+	return &ArtifactRef{
+		ID:  ae.artifact.ID,
+		Dir: ae.artifact.Dir,
+		URL: "/api/v1/artifacts/" + ae.artifact.ID,
+	}
+}
+
+// respondActionError 返回动作失败的错误响应
+// 类型化错误使用对应的状态码和错误码，其他错误使用 500 和 fallbackCode；保存了失败现场时响应中附带现场引用
+func respondActionError(c *gin.Context, fallbackCode, message string, err error) {
+	statusCode, code := http.StatusInternalServerError, fallbackCode
+	if kind, ok := classifyActionError(err); ok {
+		statusCode, code = kind.HTTPStatus, kind.Code
+	}
+
+	writeErrorResponse(c, statusCode, ErrorResponse{
+		Error:    message,
+		Code:     code,
+		Details:  err.Error(),
+		Artifact: artifactRefFromError(err),
+	})
+}
@@ -1,26 +1,42 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/artifacts"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // respondError 返回错误响应
 func respondError(c *gin.Context, statusCode int, code, message string, details any) {
-	response := ErrorResponse{
+	writeErrorResponse(c, statusCode, ErrorResponse{
 		Error:   message,
 		Code:    code,
 		Details: details,
-	}
+	})
+}
 
+// writeErrorResponse 记录日志并写出错误响应
+func writeErrorResponse(c *gin.Context, statusCode int, response ErrorResponse) {
 	logrus.Errorf("%s %s %s %d", c.Request.Method, c.Request.URL.Path,
 		c.GetString("account"), statusCode)
 
 	c.JSON(statusCode, response)
 }
 
+// withRequestAccount 把请求指定的账号放入 context，并记录到 gin 上下文供日志使用，未指定时为默认账号
+func withRequestAccount(c *gin.Context, account string) context.Context {
+	ctx := withAccount(c.Request.Context(), account)
+	c.Set("account", accountFromContext(ctx))
+	return ctx
+}
+
 // respondSuccess 返回成功响应
 func respondSuccess(c *gin.Context, data any, message string) {
 	response := SuccessResponse{
@@ -37,17 +53,64 @@ func respondSuccess(c *gin.Context, data any, message string) {
 
 // checkLoginStatusHandler 检查登录状态
 func (s *AppServer) checkLoginStatusHandler(c *gin.Context) {
-	status, err := s.xiaohongshuService.CheckLoginStatus(c.Request.Context())
+	var req AccountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	status, err := s.xiaohongshuService.CheckLoginStatus(withRequestAccount(c, req.Account))
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "STATUS_CHECK_FAILED",
-			"检查登录状态失败", err.Error())
+		respondActionError(c, "STATUS_CHECK_FAILED",
+			"检查登录状态失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, status, "检查登录状态成功")
 }
 
+// getLoginQRCodeHandler 获取登录二维码，二维码以 base64 PNG 返回
+func (s *AppServer) getLoginQRCodeHandler(c *gin.Context) {
+	var req AccountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result, err := s.xiaohongshuService.GetLoginQRCode(withRequestAccount(c, req.Account))
+	if err != nil {
+		respondActionError(c, "GET_LOGIN_QRCODE_FAILED",
+			"获取登录二维码失败", err)
+		return
+	}
+
+	respondSuccess(c, &LoginQRCodeHTTPResponse{
+		LoginQRCodeResponse: result,
+		Image:               base64.StdEncoding.EncodeToString(result.Data),
+	}, "获取登录二维码成功")
+}
+
+// waitForLoginHandler 等待扫码登录
+func (s *AppServer) waitForLoginHandler(c *gin.Context) {
+	var req WaitForLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result, err := s.xiaohongshuService.WaitForLogin(withRequestAccount(c, req.Account), time.Duration(req.TimeoutSeconds)*time.Second)
+	if err != nil {
+		respondActionError(c, "WAIT_FOR_LOGIN_FAILED",
+			"等待扫码登录失败", err)
+		return
+	}
+
+	respondSuccess(c, result, result.Message)
+}
+
 // publishHandler 发布内容
 func (s *AppServer) publishHandler(c *gin.Context) {
 	var req PublishRequest
@@ -58,10 +121,10 @@ func (s *AppServer) publishHandler(c *gin.Context) {
 	}
 
 	// 执行发布
-	result, err := s.xiaohongshuService.PublishContent(c.Request.Context(), &req)
+	result, err := s.xiaohongshuService.PublishContent(withHumanize(withRequestAccount(c, req.Account), req.Humanize), &req)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "PUBLISH_FAILED",
-			"发布失败", err.Error())
+		respondActionError(c, "PUBLISH_FAILED",
+			"发布失败", err)
 		return
 	}
 
@@ -70,36 +133,46 @@ func (s *AppServer) publishHandler(c *gin.Context) {
 
 // listFeedsHandler 获取Feeds列表
 func (s *AppServer) listFeedsHandler(c *gin.Context) {
+	var req ListFeedsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
 	// 获取 Feeds 列表
-	result, err := s.xiaohongshuService.ListFeeds(c.Request.Context())
+	result, err := s.xiaohongshuService.ListFeeds(withRequestAccount(c, req.Account), req.Limit)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "LIST_FEEDS_FAILED",
-			"获取Feeds列表失败", err.Error())
+		respondActionError(c, "LIST_FEEDS_FAILED",
+			"获取Feeds列表失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, "获取Feeds列表成功")
 }
 
 // searchFeedsHandler 搜索Feeds
 func (s *AppServer) searchFeedsHandler(c *gin.Context) {
-	keyword := c.Query("keyword")
-	if keyword == "" {
-		respondError(c, http.StatusBadRequest, "MISSING_KEYWORD",
-			"缺少关键词参数", "keyword parameter is required")
+	var req SearchFeedsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if req.Keyword == "" {
+			respondError(c, http.StatusBadRequest, "MISSING_KEYWORD",
+				"缺少关键词参数", "keyword parameter is required")
+			return
+		}
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
 		return
 	}
 
 	// 搜索 Feeds
-	result, err := s.xiaohongshuService.SearchFeeds(c.Request.Context(), keyword)
+	result, err := s.xiaohongshuService.SearchFeeds(withRequestAccount(c, req.Account), req.Keyword, req.Limit)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "SEARCH_FEEDS_FAILED",
-			"搜索Feeds失败", err.Error())
+		respondActionError(c, "SEARCH_FEEDS_FAILED",
+			"搜索Feeds失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, "搜索Feeds成功")
 }
 
@@ -113,14 +186,13 @@ func (s *AppServer) getFeedDetailHandler(c *gin.Context) {
 	}
 
 	// 获取 Feed 详情
-	result, err := s.xiaohongshuService.GetFeedDetail(c.Request.Context(), req.FeedID, req.XsecToken)
+	result, err := s.xiaohongshuService.GetFeedDetail(withRequestAccount(c, req.Account), req.FeedID, req.XsecToken, req.options())
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "GET_FEED_DETAIL_FAILED",
-			"获取Feed详情失败", err.Error())
+		respondActionError(c, "GET_FEED_DETAIL_FAILED",
+			"获取Feed详情失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, "获取Feed详情成功")
 }
 
@@ -134,14 +206,13 @@ func (s *AppServer) postCommentHandler(c *gin.Context) {
 	}
 
 	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(c.Request.Context(), req.FeedID, req.XsecToken, req.Content)
+	result, err := s.xiaohongshuService.PostCommentToFeed(withHumanize(withRequestAccount(c, req.Account), req.Humanize), req.FeedID, req.XsecToken, req.Content)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "POST_COMMENT_FAILED",
-			"发表评论失败", err.Error())
+		respondActionError(c, "POST_COMMENT_FAILED",
+			"发表评论失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, result.Message)
 }
 
@@ -155,14 +226,13 @@ func (s *AppServer) likeFeedHandler(c *gin.Context) {
 	}
 
 	// 执行点赞操作
-	result, err := s.xiaohongshuService.LikeFeed(c.Request.Context(), req.FeedID, req.XsecToken)
+	result, err := s.xiaohongshuService.LikeFeed(withHumanize(withRequestAccount(c, req.Account), req.Humanize), req.FeedID, req.XsecToken)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "LIKE_FEED_FAILED",
-			"点赞失败", err.Error())
+		respondActionError(c, "LIKE_FEED_FAILED",
+			"点赞失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, result.Message)
 }
 
@@ -176,23 +246,153 @@ func (s *AppServer) collectFeedHandler(c *gin.Context) {
 	}
 
 	// 执行收藏操作
-	result, err := s.xiaohongshuService.CollectFeed(c.Request.Context(), req.FeedID, req.XsecToken)
+	result, err := s.xiaohongshuService.CollectFeed(withHumanize(withRequestAccount(c, req.Account), req.Humanize), req.FeedID, req.XsecToken)
 	if err != nil {
-		respondError(c, http.StatusInternalServerError, "COLLECT_FEED_FAILED",
-			"收藏失败", err.Error())
+		respondActionError(c, "COLLECT_FEED_FAILED",
+			"收藏失败", err)
 		return
 	}
 
-	c.Set("account", "ai-report")
 	respondSuccess(c, result, result.Message)
 }
 
-// healthHandler 健康检查
-func healthHandler(c *gin.Context) {
+// healthHandler 健康检查，附带浏览器状态、页面池和动作调度统计
+func (s *AppServer) healthHandler(c *gin.Context) {
 	respondSuccess(c, map[string]any{
 		"status":    "healthy",
 		"service":   "xiaohongshu-mcp",
-		"account":   "ai-report",
+		"account":   configs.GetDefaultAccount(),
 		"timestamp": "now",
+		"browser":   s.xiaohongshuService.BrowserStats(),
+		"page_pool": s.xiaohongshuService.PagePoolStats(),
+		"scheduler": s.xiaohongshuService.SchedulerStats(),
+		"sessions":  s.xiaohongshuService.SessionHealth(),
 	}, "服务正常")
 }
+
+// listArtifactsHandler 列出动作失败现场
+func (s *AppServer) listArtifactsHandler(c *gin.Context) {
+	list, err := s.xiaohongshuService.Artifacts().List()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "LIST_ARTIFACTS_FAILED",
+			"获取失败现场列表失败", err.Error())
+		return
+	}
+	if list == nil {
+		list = []artifacts.Artifact{}
+	}
+
+	respondSuccess(c, map[string]any{
+		"artifacts": list,
+		"count":     len(list),
+	}, "获取失败现场列表成功")
+}
+
+// getArtifactHandler 获取单个失败现场的文件列表
+func (s *AppServer) getArtifactHandler(c *gin.Context) {
+	artifact, err := s.xiaohongshuService.Artifacts().Get(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "ARTIFACT_NOT_FOUND",
+			"失败现场不存在", err.Error())
+		return
+	}
+
+	respondSuccess(c, artifact, "获取失败现场成功")
+}
+
+// getArtifactFileHandler 下载失败现场中的文件
+func (s *AppServer) getArtifactFileHandler(c *gin.Context) {
+	path, err := s.xiaohongshuService.Artifacts().FilePath(c.Param("id"), c.Param("file"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "ARTIFACT_NOT_FOUND",
+			"失败现场文件不存在", err.Error())
+		return
+	}
+
+	c.File(path)
+}
+
+// listActionsHandler 列出进行中的动作，可以按账号过滤
+func (s *AppServer) listActionsHandler(c *gin.Context) {
+	var req AccountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	actions := s.xiaohongshuService.Actions(req.Account)
+
+	respondSuccess(c, &ActionsListResponse{
+		Actions: actions,
+		Count:   len(actions),
+	}, "获取动作列表成功")
+}
+
+// getActionScreenshotHandler 返回动作页面当前画面的 PNG 截图
+func (s *AppServer) getActionScreenshotHandler(c *gin.Context) {
+	data, err := s.xiaohongshuService.ActionScreenshot(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, "ACTION_SCREENSHOT_FAILED",
+			"获取动作截图失败", err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+// resumeActionHandler 人工完成验证后通知动作继续执行
+func (s *AppServer) resumeActionHandler(c *gin.Context) {
+	info, err := s.xiaohongshuService.ResumeAction(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusConflict, "RESUME_ACTION_FAILED",
+			"继续动作失败", err.Error())
+		return
+	}
+
+	respondSuccess(c, info, "已通知动作继续执行")
+}
+
+// listAccountsHandler 列出所有账号
+func (s *AppServer) listAccountsHandler(c *gin.Context) {
+	respondSuccess(c, s.xiaohongshuService.ListAccounts(), "获取账号列表成功")
+}
+
+// switchDefaultAccountHandler 切换默认账号
+func (s *AppServer) switchDefaultAccountHandler(c *gin.Context) {
+	var req SwitchAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+			"请求参数错误", err.Error())
+		return
+	}
+
+	result, err := s.xiaohongshuService.SwitchDefaultAccount(req.Account)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "SWITCH_ACCOUNT_FAILED",
+			"切换默认账号失败", err.Error())
+		return
+	}
+
+	respondSuccess(c, result, "切换默认账号成功")
+}
+
+// listSelectorsHandler 列出选择器及各候选的匹配统计
+func (s *AppServer) listSelectorsHandler(c *gin.Context) {
+	respondSuccess(c, map[string]any{
+		"selectors": xiaohongshu.SelectorStats(),
+	}, "获取选择器成功")
+}
+
+// reloadSelectorsHandler 重新加载选择器覆盖文件
+func (s *AppServer) reloadSelectorsHandler(c *gin.Context) {
+	if err := xiaohongshu.ReloadSelectorOverrides(); err != nil {
+		respondError(c, http.StatusBadRequest, "RELOAD_SELECTORS_FAILED",
+			"重新加载选择器失败", err.Error())
+		return
+	}
+
+	respondSuccess(c, map[string]any{
+		"selectors": xiaohongshu.SelectorStats(),
+	}, "重新加载选择器成功")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFeedsHandlerValidation(t *testing.T) {
+	s := NewAppServer(&XiaohongshuService{})
+	t.Cleanup(s.mcpSessions.Close)
+	router := setupRoutes(s)
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"", "MISSING_KEYWORD"},
+		{"limit=10", "MISSING_KEYWORD"},
+		{"keyword=x&limit=500", "INVALID_REQUEST"},
+		{"keyword=x&limit=abc", "INVALID_REQUEST"},
+		{"keyword=x&account=no-such-account", "INVALID_REQUEST"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/feeds/search?"+test.query, nil))
+		require.Equal(t, http.StatusBadRequest, w.Code, test.query)
+
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, test.code, resp.Code, test.query)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/browser"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+const (
+	// loginQRCodeTTL 二维码页面保留的时间，超时后关闭页面，需要重新获取二维码
+	loginQRCodeTTL = 3 * time.Minute
+
+	// defaultLoginWaitTimeout wait_for_login 没有指定等待时间时的默认值
+	defaultLoginWaitTimeout = 60 * time.Second
+)
+
+// pendingLogin 等待扫码的登录页面
+// 二维码与页面绑定，页面在获取二维码后保持打开，直到登录完成、重新获取二维码或过期
+type pendingLogin struct {
+	page      *rod.Page
+	expiresAt time.Time
+	timer     *time.Timer
+	waitMu    sync.Mutex // 同一时间只有一个等待在页面上检查登录状态
+}
+
+// loginRegistry 按账号记录等待扫码的登录页面
+type loginRegistry struct {
+	mu      sync.Mutex
+	pending map[string]*pendingLogin
+}
+
+func newLoginRegistry() *loginRegistry {
+	return &loginRegistry{pending: make(map[string]*pendingLogin)}
+}
+
+// put 记录账号新的登录页面，关闭之前未完成的登录页面
+func (r *loginRegistry) put(account string, page *rod.Page) *pendingLogin {
+	p := &pendingLogin{
+		page:      page,
+		expiresAt: time.Now().Add(loginQRCodeTTL),
+	}
+
+	r.mu.Lock()
+	p.timer = time.AfterFunc(loginQRCodeTTL, func() {
+		if r.remove(account, p) {
+			logrus.Infof("账号 %s 的登录二维码已过期", account)
+		}
+	})
+	old := r.pending[account]
+	r.pending[account] = p
+	r.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+	return p
+}
+
+// get 账号等待扫码的登录页面
+func (r *loginRegistry) get(account string) (*pendingLogin, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pending[account]
+	return p, ok
+}
+
+// remove 移除并关闭账号的登录页面，页面已经被替换时不做处理；返回是否移除
+func (r *loginRegistry) remove(account string, p *pendingLogin) bool {
+	r.mu.Lock()
+	if r.pending[account] != p {
+		r.mu.Unlock()
+		return false
+	}
+	delete(r.pending, account)
+	r.mu.Unlock()
+
+	p.close()
+	return true
+}
+
+// closeAll 关闭所有登录页面
+func (r *loginRegistry) closeAll() {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[string]*pendingLogin)
+	r.mu.Unlock()
+
+	for _, p := range pending {
+		p.close()
+	}
+}
+
+func (p *pendingLogin) close() {
+	p.timer.Stop()
+	if err := p.page.Close(); err != nil {
+		logrus.Debugf("关闭登录页面失败: %v", err)
+	}
+}
+
+// LoginQRCodeResponse 登录二维码响应，二维码以 image 内容返回
+type LoginQRCodeResponse struct {
+	Account string `json:"account"`
+	// IsLoggedIn 账号已经登录时为 true，不返回二维码
+	IsLoggedIn bool   `json:"is_logged_in"`
+	MIMEType   string `json:"mime_type,omitempty"`
+	// ExpiresAt 二维码过期时间，过期前调用 wait_for_login 等待扫码
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Data      []byte `json:"-"`
+}
+
+// toolContent 二维码作为 image 内容返回，不放进 structuredContent
+func (r *LoginQRCodeResponse) toolContent() []MCPContent {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	return []MCPContent{{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(r.Data),
+		MIMEType: r.MIMEType,
+	}}
+}
+
+// LoginQRCodeHTTPResponse HTTP 接口的登录二维码响应，Image 为 base64 编码的 PNG
+type LoginQRCodeHTTPResponse struct {
+	*LoginQRCodeResponse
+	Image string `json:"image,omitempty"`
+}
+
+// WaitForLoginRequest 等待扫码登录请求
+type WaitForLoginRequest struct {
+	Account        string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" binding:"omitempty,min=1,max=300" description:"最长等待秒数，默认 60 秒，不超过二维码的过期时间"`
+}
+
+// WaitForLoginResponse 等待扫码登录响应
+type WaitForLoginResponse struct {
+	Account    string `json:"account"`
+	IsLoggedIn bool   `json:"is_logged_in"`
+	Message    string `json:"message"`
+}
+
+// GetLoginQRCode 在账号的浏览器中打开登录弹窗并返回登录二维码
+// 登录页面保持打开，扫码后调用 WaitForLogin 保存 cookies
+func (s *XiaohongshuService) GetLoginQRCode(ctx context.Context) (*LoginQRCodeResponse, error) {
+	sess, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 登录页面需要跨请求保持打开，不从页面池租用
+	page, err := sess.browser.NewPage()
+	if err != nil {
+		return nil, err
+	}
+
+	png, loggedIn, err := xiaohongshu.NewLogin(page).FetchQRCode(ctx)
+	if err != nil || loggedIn {
+		_ = page.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "获取登录二维码失败")
+		}
+		s.recordLoginStatus(sess.name, xiaohongshu.LoginStateLoggedIn, "")
+		return &LoginQRCodeResponse{Account: sess.name, IsLoggedIn: true}, nil
+	}
+
+	p := s.logins.put(sess.name, page)
+	logrus.Infof("已获取账号 %s 的登录二维码，%s 前有效", sess.name, p.expiresAt.Format(time.RFC3339))
+
+	return &LoginQRCodeResponse{
+		Account:   sess.name,
+		MIMEType:  "image/png",
+		ExpiresAt: p.expiresAt.Format(time.RFC3339),
+		Data:      png,
+	}, nil
+}
+
+// WaitForLogin 等待 GetLoginQRCode 返回的二维码被扫码，登录成功后保存 cookies 并关闭登录页面
+// 超时仍未登录时返回未登录，二维码过期前可以再次等待
+func (s *XiaohongshuService) WaitForLogin(ctx context.Context, timeout time.Duration) (*WaitForLoginResponse, error) {
+	account := accountFromContext(ctx)
+
+	p, ok := s.logins.get(account)
+	if !ok {
+		return nil, errors.Errorf("账号 %s 没有等待扫码的二维码，请先获取登录二维码", account)
+	}
+
+	if timeout <= 0 {
+		timeout = defaultLoginWaitTimeout
+	}
+	if remaining := time.Until(p.expiresAt); remaining < timeout {
+		timeout = remaining
+	}
+
+	p.waitMu.Lock()
+	loggedIn, err := xiaohongshu.NewLogin(p.page).WaitForLogin(ctx, timeout)
+	p.waitMu.Unlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "等待扫码登录失败")
+	}
+
+	if !loggedIn {
+		return &WaitForLoginResponse{
+			Account: account,
+			Message: "等待超时，尚未扫码登录",
+		}, nil
+	}
+
+	// 保存到会话使用的存储，memory 后端时与会话共享同一份 cookies
+	sess, err := s.session(ctx)
+	if err == nil {
+		err = browser.SaveCookies(p.page, sess.cookies)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "登录成功但保存 cookies 失败")
+	}
+	s.logins.remove(account, p)
+	s.recordLoginStatus(account, xiaohongshu.LoginStateLoggedIn, "")
+
+	logrus.Infof("账号 %s 扫码登录成功，cookies 已保存", account)
+
+	return &WaitForLoginResponse{
+		Account:    account,
+		IsLoggedIn: true,
+		Message:    "登录成功，cookies 已保存",
+	}, nil
+}
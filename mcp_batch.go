@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// BatchPolicy JSON-RPC 批量请求的执行策略
+type BatchPolicy string
+
+const (
+	// BatchSequential 按顺序逐个执行批量请求中的调用
+	BatchSequential BatchPolicy = "sequential"
+	// BatchConcurrent 并发执行批量请求中的调用，响应顺序与请求顺序一致
+	BatchConcurrent BatchPolicy = "concurrent"
+)
+
+// ParseBatchPolicy 解析批量执行策略
+func ParseBatchPolicy(value string) (BatchPolicy, error) {
+	switch BatchPolicy(value) {
+	case BatchSequential, BatchConcurrent:
+		return BatchPolicy(value), nil
+	default:
+		return "", errors.Errorf("unknown batch policy: %s", value)
+	}
+}
+
+// jsonrpcMessage 解析后的单条 JSON-RPC 消息
+type jsonrpcMessage struct {
+	request *JSONRPCRequest
+	// notification 没有 id 的通知或客户端发来的响应，不需要回复
+	notification bool
+	// invalid 无法解析的消息，直接回复该错误
+	invalid *JSONRPCError
+}
+
+// parseJSONRPCMessages 解析请求体，支持单条消息和批量数组
+func parseJSONRPCMessages(body []byte) ([]*jsonrpcMessage, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, errors.New("empty body")
+	}
+
+	if trimmed[0] != '[' {
+		message, err := parseJSONRPCMessage(trimmed)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*jsonrpcMessage{message}, false, nil
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(trimmed, &raws); err != nil {
+		return nil, true, errors.Wrap(err, "invalid batch")
+	}
+
+	messages := make([]*jsonrpcMessage, 0, len(raws))
+	for _, raw := range raws {
+		message, err := parseJSONRPCMessage(raw)
+		if err != nil {
+			// 批量中单条消息无效时只影响该条，回复 Invalid Request
+			message = &jsonrpcMessage{
+				invalid: &JSONRPCError{Code: -32600, Message: "Invalid Request"},
+			}
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, true, nil
+}
+
+// parseJSONRPCMessage 解析单条消息，并根据是否存在 id 字段区分请求和通知
+func parseJSONRPCMessage(raw json.RawMessage) (*jsonrpcMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, errors.Wrap(err, "invalid message")
+	}
+
+	var request JSONRPCRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return nil, errors.Wrap(err, "invalid message")
+	}
+
+	_, hasID := fields["id"]
+
+	return &jsonrpcMessage{
+		request:      &request,
+		notification: !hasID || request.Method == "",
+	}, nil
+}
+
+// hasStreamableRequest 批量中是否有需要流式响应的请求
+func (s *AppServer) hasStreamableRequest(messages []*jsonrpcMessage) bool {
+	for _, message := range messages {
+		if message.request != nil && !message.notification && s.isStreamableMethod(message.request) {
+			return true
+		}
+	}
+	return false
+}
+
+// processJSONRPCMessages 按批量策略处理消息，每得到一条需要回复的响应就调用 emit
+// 通知不会产生响应
+func (s *AppServer) processJSONRPCMessages(ctx context.Context, messages []*jsonrpcMessage, emit func(*JSONRPCResponse)) {
+	process := func(message *jsonrpcMessage) *JSONRPCResponse {
+		if message.invalid != nil {
+			var id any
+			if message.request != nil {
+				id = message.request.ID
+			}
+			return &JSONRPCResponse{JSONRPC: "2.0", Error: message.invalid, ID: id}
+		}
+
+		if message.request.Method == "" {
+			// 客户端发来的响应，目前服务端不会主动发起请求，直接忽略
+			return nil
+		}
+
+		response := s.processJSONRPCRequest(message.request, ctx)
+		if message.notification {
+			return nil
+		}
+		return response
+	}
+
+	if s.batchPolicy != BatchConcurrent || len(messages) == 1 {
+		for _, message := range messages {
+			if response := process(message); response != nil {
+				emit(response)
+			}
+		}
+		return
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		slots = make([]*JSONRPCResponse, len(messages))
+	)
+
+	for i, message := range messages {
+		wg.Add(1)
+		go func(i int, message *jsonrpcMessage) {
+			defer wg.Done()
+
+			response := process(message)
+
+			mu.Lock()
+			slots[i] = response
+			mu.Unlock()
+		}(i, message)
+	}
+	wg.Wait()
+
+	for _, response := range slots {
+		if response != nil {
+			emit(response)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// mcpEventHistorySize 每个会话保留的历史事件数量，用于 Last-Event-ID 断线重连补发
+	mcpEventHistorySize = 256
+
+	// mcpEventBufferSize 每个订阅者的事件缓冲大小
+	mcpEventBufferSize = 64
+)
+
+// MCPEvent 推送给客户端的 SSE 事件
+type MCPEvent struct {
+	ID   uint64
+	Data []byte
+}
+
+// EventID 返回 SSE 事件 ID
+func (e MCPEvent) EventID() string {
+	return strconv.FormatUint(e.ID, 10)
+}
+
+// MCPEventBus 会话级事件总线，负责给 GET SSE 连接推送 JSON-RPC 通知
+type MCPEventBus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []MCPEvent
+	subscribers map[chan MCPEvent]struct{}
+	closed      bool
+}
+
+// NewMCPEventBus 创建事件总线
+func NewMCPEventBus() *MCPEventBus {
+	return &MCPEventBus{
+		subscribers: make(map[chan MCPEvent]struct{}),
+	}
+}
+
+// Publish 发布一条 JSON-RPC 消息，写入历史并推送给所有订阅者
+func (b *MCPEventBus) Publish(message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal MCP event")
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	event := MCPEvent{ID: b.nextID, Data: data}
+
+	b.history = append(b.history, event)
+	if len(b.history) > mcpEventHistorySize {
+		b.history = b.history[len(b.history)-mcpEventHistorySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者处理过慢，丢弃该事件，客户端可通过 Last-Event-ID 重连补发
+			logrus.WithField("event", event.ID).Warn("MCP 事件订阅者缓冲已满，丢弃事件")
+		}
+	}
+}
+
+// Subscribe 订阅事件流
+// lastEventID 大于 0 时，返回该 ID 之后的历史事件用于补发
+// 事件总线关闭后返回的 channel 会被关闭，订阅者应结束推送
+func (b *MCPEventBus) Subscribe(lastEventID uint64) ([]MCPEvent, <-chan MCPEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []MCPEvent
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	ch := make(chan MCPEvent, mcpEventBufferSize)
+	if b.closed {
+		close(ch)
+		return replay, ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers, ch)
+	}
+
+	return replay, ch, unsubscribe
+}
+
+// Close 关闭事件总线，关闭所有订阅者的 channel，之后发布的事件被丢弃
+func (b *MCPEventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for ch := range b.subscribers {
+		close(ch)
+		delete(b.subscribers, ch)
+	}
+}
+
+// parseLastEventID 解析 Last-Event-ID 头，无法解析时返回 0
+func parseLastEventID(value string) uint64 {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -2,458 +2,207 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 // MCP 工具处理函数
+// 参数已经由工具注册表解码并校验，返回的类型化结果会作为 structuredContent 返回给客户端
 
 // handleCheckLoginStatus 处理检查登录状态
-func (s *AppServer) handleCheckLoginStatus(ctx context.Context) *MCPToolResult {
-	logrus.Info("MCP: 检查登录状态")
+func (s *AppServer) handleCheckLoginStatus(ctx context.Context, req *AccountRequest) (*LoginStatusResponse, error) {
+	logrus.Infof("MCP: 检查登录状态 - 账号: %s", req.Account)
 
-	status, err := s.xiaohongshuService.CheckLoginStatus(ctx)
+	status, err := s.xiaohongshuService.CheckLoginStatus(withAccount(ctx, req.Account))
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "检查登录状态失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "检查登录状态失败")
 	}
 
-	resultText := fmt.Sprintf("登录状态检查成功: %+v", status)
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return status, nil
 }
 
-// handlePublishContent 处理发布内容
-func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 发布内容")
-
-	// 解析参数
-	title, _ := args["title"].(string)
-	content, _ := args["content"].(string)
-	publishTime, _ := args["publish_time"].(string)
-	imagePathsInterface, _ := args["images"].([]interface{})
-
-	var imagePaths []string
-	for _, path := range imagePathsInterface {
-		if pathStr, ok := path.(string); ok {
-			imagePaths = append(imagePaths, pathStr)
-		}
+// handleGetLoginQRCode 处理获取登录二维码
+func (s *AppServer) handleGetLoginQRCode(ctx context.Context, req *AccountRequest) (*LoginQRCodeResponse, error) {
+	logrus.Infof("MCP: 获取登录二维码 - 账号: %s", req.Account)
+
+	result, err := s.xiaohongshuService.GetLoginQRCode(withAccount(ctx, req.Account))
+	if err != nil {
+		return nil, errors.Wrap(err, "获取登录二维码失败")
 	}
 
-	logrus.Infof("MCP: 发布内容 - 标题: %s, 图片数量: %d, 发布时间: %s", title, len(imagePaths), publishTime)
+	return result, nil
+}
 
-	// 构建发布请求
-	req := &PublishRequest{
-		Title:       title,
-		Content:     content,
-		Images:      imagePaths,
-		PublishTime: publishTime,
-	}
+// handleWaitForLogin 处理等待扫码登录
+func (s *AppServer) handleWaitForLogin(ctx context.Context, req *WaitForLoginRequest) (*WaitForLoginResponse, error) {
+	logrus.Infof("MCP: 等待扫码登录 - 账号: %s", req.Account)
 
-	// 执行发布
-	result, err := s.xiaohongshuService.PublishContent(ctx, req)
+	result, err := s.xiaohongshuService.WaitForLogin(withAccount(ctx, req.Account), time.Duration(req.TimeoutSeconds)*time.Second)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发布失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "等待扫码登录失败")
 	}
 
-	resultText := fmt.Sprintf("内容发布成功: %+v", result)
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return result, nil
 }
 
-// handlePublishArticle 处理发布文章
-func (s *AppServer) handlePublishArticle(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 发布文章")
-
-	// 解析参数
-	title, _ := args["title"].(string)
-	content, _ := args["content"].(string)
-	publishTime, _ := args["publish_time"].(string)
-	
-	// 解析图片路径
-	imagePathsInterface, _ := args["images"].([]interface{})
-	var imagePaths []string
-	for _, path := range imagePathsInterface {
-		if pathStr, ok := path.(string); ok {
-			imagePaths = append(imagePaths, pathStr)
-		}
-	}
+// handlePublishContent 处理发布内容
+func (s *AppServer) handlePublishContent(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
+	logrus.Infof("MCP: 发布内容 - 标题: %s, 图片数量: %d, 发布时间: %s", req.Title, len(req.Images), req.PublishTime)
 
-	// 解析标签
-	tagsInterface, _ := args["tags"].([]interface{})
-	var tags []string
-	for _, tag := range tagsInterface {
-		if tagStr, ok := tag.(string); ok {
-			tags = append(tags, tagStr)
-		}
+	// 执行发布
+	result, err := s.xiaohongshuService.PublishContent(withHumanize(withAccount(ctx, req.Account), req.Humanize), req)
+	if err != nil {
+		return nil, errors.Wrap(err, "发布失败")
 	}
 
-	logrus.Infof("MCP: 发布文章 - 标题: %s, 图片数量: %d, 标签数量: %d, 发布时间: %s", 
-		title, len(imagePaths), len(tags), publishTime)
+	return result, nil
+}
 
-	// 构建发布请求
-	req := &PublishArticleRequest{
-		Title:       title,
-		Content:     content,
-		Tags:        tags,
-		Images:      imagePaths,
-		PublishTime: publishTime,
-	}
+// handlePublishArticle 处理发布文章
+func (s *AppServer) handlePublishArticle(ctx context.Context, req *PublishArticleRequest) (*PublishResponse, error) {
+	logrus.Infof("MCP: 发布文章 - 标题: %s, 图片数量: %d, 标签数量: %d, 发布时间: %s",
+		req.Title, len(req.Images), len(req.Tags), req.PublishTime)
 
 	// 执行发布
-	result, err := s.xiaohongshuService.PublishArticle(ctx, req)
+	result, err := s.xiaohongshuService.PublishArticle(withHumanize(withAccount(ctx, req.Account), req.Humanize), req)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "文章发布失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "文章发布失败")
 	}
 
-	resultText := fmt.Sprintf("文章发布成功: %+v", result)
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return result, nil
 }
 
 // handleListFeeds 处理获取Feeds列表
-func (s *AppServer) handleListFeeds(ctx context.Context) *MCPToolResult {
-	logrus.Info("MCP: 获取Feeds列表")
+func (s *AppServer) handleListFeeds(ctx context.Context, req *ListFeedsRequest) (*FeedsListResponse, error) {
+	logrus.Infof("MCP: 获取Feeds列表 - 数量: %d", req.Limit)
 
-	result, err := s.xiaohongshuService.ListFeeds(ctx)
+	result, err := s.xiaohongshuService.ListFeeds(withAccount(ctx, req.Account), req.Limit)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "获取Feeds列表失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "获取Feeds列表失败")
 	}
 
-	// 格式化输出，转换为JSON字符串
-	jsonData, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: fmt.Sprintf("获取Feeds列表成功，但序列化失败: %v", err),
-			}},
-			IsError: true,
-		}
-	}
+	s.recentResources.rememberFeeds(result.Feeds)
 
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: string(jsonData),
-		}},
-	}
+	return result, nil
 }
 
 // handleSearchFeeds 处理搜索Feeds
-func (s *AppServer) handleSearchFeeds(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 搜索Feeds")
-
-	// 解析参数
-	keyword, ok := args["keyword"].(string)
-	if !ok || keyword == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "搜索Feeds失败: 缺少关键词参数",
-			}},
-			IsError: true,
-		}
-	}
+func (s *AppServer) handleSearchFeeds(ctx context.Context, req *SearchFeedsRequest) (*FeedsListResponse, error) {
+	logrus.Infof("MCP: 搜索Feeds - 关键词: %s", req.Keyword)
 
-	logrus.Infof("MCP: 搜索Feeds - 关键词: %s", keyword)
-
-	result, err := s.xiaohongshuService.SearchFeeds(ctx, keyword)
+	result, err := s.xiaohongshuService.SearchFeeds(withAccount(ctx, req.Account), req.Keyword, req.Limit)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "搜索Feeds失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "搜索Feeds失败")
 	}
 
-	// 格式化输出，转换为JSON字符串
-	jsonData, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: fmt.Sprintf("搜索Feeds成功，但序列化失败: %v", err),
-			}},
-			IsError: true,
-		}
-	}
+	s.recentResources.rememberFeeds(result.Feeds)
 
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: string(jsonData),
-		}},
-	}
+	return result, nil
 }
 
 // handleGetFeedDetail 处理获取Feed详情
-func (s *AppServer) handleGetFeedDetail(ctx context.Context, args map[string]any) *MCPToolResult {
-	logrus.Info("MCP: 获取Feed详情")
-
-	// 解析参数
-	feedID, ok := args["feed_id"].(string)
-	if !ok || feedID == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "获取Feed详情失败: 缺少feed_id参数",
-			}},
-			IsError: true,
-		}
-	}
+func (s *AppServer) handleGetFeedDetail(ctx context.Context, req *FeedDetailRequest) (*FeedDetailResponse, error) {
+	logrus.Infof("MCP: 获取Feed详情 - Feed ID: %s", req.FeedID)
 
-	xsecToken, ok := args["xsec_token"].(string)
-	if !ok || xsecToken == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "获取Feed详情失败: 缺少xsec_token参数",
-			}},
-			IsError: true,
-		}
-	}
-
-	logrus.Infof("MCP: 获取Feed详情 - Feed ID: %s", feedID)
-
-	result, err := s.xiaohongshuService.GetFeedDetail(ctx, feedID, xsecToken)
+	result, err := s.xiaohongshuService.GetFeedDetail(withAccount(ctx, req.Account), req.FeedID, req.XsecToken, req.options())
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "获取Feed详情失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "获取Feed详情失败")
 	}
 
-	// 格式化输出，转换为JSON字符串
-	jsonData, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: fmt.Sprintf("获取Feed详情成功，但序列化失败: %v", err),
-			}},
-			IsError: true,
-		}
-	}
+	note := result.Data.Note
+	s.recentResources.remember(resourceKindNote, req.FeedID, req.XsecToken, note.Title)
+	s.observeNoteEngagement(makeResourceURI(resourceKindNote, req.FeedID, ""), note.InteractInfo)
 
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: string(jsonData),
-		}},
-	}
+	return result, nil
 }
 
 // handlePostComment 处理发表评论到Feed
-func (s *AppServer) handlePostComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 发表评论到Feed")
-
-	// 解析参数
-	feedID, ok := args["feed_id"].(string)
-	if !ok || feedID == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发表评论失败: 缺少feed_id参数",
-			}},
-			IsError: true,
-		}
-	}
+func (s *AppServer) handlePostComment(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error) {
+	logrus.Infof("MCP: 发表评论 - Feed ID: %s, 内容长度: %d", req.FeedID, len(req.Content))
 
-	xsecToken, ok := args["xsec_token"].(string)
-	if !ok || xsecToken == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发表评论失败: 缺少xsec_token参数",
-			}},
-			IsError: true,
-		}
+	// 发表评论
+	result, err := s.xiaohongshuService.PostCommentToFeed(withHumanize(withAccount(ctx, req.Account), req.Humanize), req.FeedID, req.XsecToken, req.Content)
+	if err != nil {
+		return nil, errors.Wrap(err, "发表评论失败")
 	}
 
-	content, ok := args["content"].(string)
-	if !ok || content == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发表评论失败: 缺少content参数",
-			}},
-			IsError: true,
-		}
-	}
+	return result, nil
+}
 
-	logrus.Infof("MCP: 发表评论 - Feed ID: %s, 内容长度: %d", feedID, len(content))
+// handleLikeFeed 处理点赞Feed
+func (s *AppServer) handleLikeFeed(ctx context.Context, req *LikeFeedRequest) (*LikeFeedResponse, error) {
+	logrus.Infof("MCP: 点赞Feed - Feed ID: %s", req.FeedID)
 
-	// 发表评论
-	result, err := s.xiaohongshuService.PostCommentToFeed(ctx, feedID, xsecToken, content)
+	// 执行点赞操作
+	result, err := s.xiaohongshuService.LikeFeed(withHumanize(withAccount(ctx, req.Account), req.Humanize), req.FeedID, req.XsecToken)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "发表评论失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "点赞失败")
 	}
 
-	// 返回成功结果，只包含feed_id
-	resultText := fmt.Sprintf("评论发表成功 - Feed ID: %s", result.FeedID)
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return result, nil
 }
 
-// handleLikeFeed 处理点赞Feed
-func (s *AppServer) handleLikeFeed(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 点赞Feed")
-
-	// 解析参数
-	feedID, ok := args["feed_id"].(string)
-	if !ok || feedID == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "点赞失败: 缺少feed_id参数",
-			}},
-			IsError: true,
-		}
-	}
+// handleCollectFeed 处理收藏Feed
+func (s *AppServer) handleCollectFeed(ctx context.Context, req *CollectFeedRequest) (*CollectFeedResponse, error) {
+	logrus.Infof("MCP: 收藏Feed - Feed ID: %s", req.FeedID)
 
-	xsecToken, ok := args["xsec_token"].(string)
-	if !ok || xsecToken == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "点赞失败: 缺少xsec_token参数",
-			}},
-			IsError: true,
-		}
+	// 执行收藏操作
+	result, err := s.xiaohongshuService.CollectFeed(withHumanize(withAccount(ctx, req.Account), req.Humanize), req.FeedID, req.XsecToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "收藏失败")
 	}
 
-	logrus.Infof("MCP: 点赞Feed - Feed ID: %s", feedID)
+	return result, nil
+}
 
-	// 执行点赞操作
-	result, err := s.xiaohongshuService.LikeFeed(ctx, feedID, xsecToken)
+// handleListActions 处理列出进行中的动作
+func (s *AppServer) handleListActions(_ context.Context, req *AccountRequest) (*ActionsListResponse, error) {
+	actions := s.xiaohongshuService.Actions(req.Account)
+	return &ActionsListResponse{Actions: actions, Count: len(actions)}, nil
+}
+
+// handleGetActionScreenshot 处理获取动作页面截图
+func (s *AppServer) handleGetActionScreenshot(ctx context.Context, req *ActionRequest) (*ActionScreenshotResponse, error) {
+	data, err := s.xiaohongshuService.ActionScreenshot(ctx, req.ActionID)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "点赞失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "获取动作截图失败")
 	}
 
-	// 返回成功结果
-	resultText := fmt.Sprintf("点赞操作成功 - Feed ID: %s, 状态: %s, 点赞数: %s",
-		result.FeedID,
-		map[bool]string{true: "已点赞", false: "未点赞"}[result.Liked],
-		result.LikeCount)
-
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return &ActionScreenshotResponse{
+		ActionID: req.ActionID,
+		MIMEType: "image/png",
+		Size:     len(data),
+		Data:     data,
+	}, nil
 }
 
-// handleCollectFeed 处理收藏Feed
-func (s *AppServer) handleCollectFeed(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	logrus.Info("MCP: 收藏Feed")
-
-	// 解析参数
-	feedID, ok := args["feed_id"].(string)
-	if !ok || feedID == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "收藏失败: 缺少feed_id参数",
-			}},
-			IsError: true,
-		}
-	}
+// handleResumeAction 处理人工完成验证后继续动作
+func (s *AppServer) handleResumeAction(_ context.Context, req *ActionRequest) (*ActionInfo, error) {
+	logrus.Infof("MCP: 继续动作 - ID: %s", req.ActionID)
 
-	xsecToken, ok := args["xsec_token"].(string)
-	if !ok || xsecToken == "" {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "收藏失败: 缺少xsec_token参数",
-			}},
-			IsError: true,
-		}
+	info, err := s.xiaohongshuService.ResumeAction(req.ActionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "继续动作失败")
 	}
 
-	logrus.Infof("MCP: 收藏Feed - Feed ID: %s", feedID)
+	return &info, nil
+}
 
-	// 执行收藏操作
-	result, err := s.xiaohongshuService.CollectFeed(ctx, feedID, xsecToken)
+// handleListAccounts 处理列出账号
+func (s *AppServer) handleListAccounts(_ context.Context, _ *EmptyRequest) (*AccountsListResponse, error) {
+	return s.xiaohongshuService.ListAccounts(), nil
+}
+
+// handleSwitchDefaultAccount 处理切换默认账号
+func (s *AppServer) handleSwitchDefaultAccount(_ context.Context, req *SwitchAccountRequest) (*AccountsListResponse, error) {
+	logrus.Infof("MCP: 切换默认账号 - 账号: %s", req.Account)
+
+	result, err := s.xiaohongshuService.SwitchDefaultAccount(req.Account)
 	if err != nil {
-		return &MCPToolResult{
-			Content: []MCPContent{{
-				Type: "text",
-				Text: "收藏失败: " + err.Error(),
-			}},
-			IsError: true,
-		}
+		return nil, errors.Wrap(err, "切换默认账号失败")
 	}
 
-	// 返回成功结果
-	resultText := fmt.Sprintf("收藏操作成功 - Feed ID: %s, 状态: %s, 收藏数: %s",
-		result.FeedID,
-		map[bool]string{true: "已收藏", false: "未收藏"}[result.Collected],
-		result.CollectCount)
-
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: resultText,
-		}},
-	}
+	return result, nil
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// notificationProgress 进度通知方法名
+const notificationProgress = "notifications/progress"
+
+// progressSink 进度通知的输出目标，例如 POST 请求的 SSE 响应流
+type progressSink func(message any)
+
+type progressSinkKey struct{}
+
+// withProgressSink 在 context 中设置进度通知的输出目标
+func withProgressSink(ctx context.Context, sink progressSink) context.Context {
+	return context.WithValue(ctx, progressSinkKey{}, sink)
+}
+
+// progressSinkFromContext 从 context 中获取进度通知的输出目标
+func progressSinkFromContext(ctx context.Context) progressSink {
+	sink, _ := ctx.Value(progressSinkKey{}).(progressSink)
+	return sink
+}
+
+// ProgressNotificationParams notifications/progress 通知参数
+type ProgressNotificationParams struct {
+	ProgressToken any     `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         int     `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// progressTokenFromParams 读取请求参数中的 _meta.progressToken，未提供时返回 nil
+func progressTokenFromParams(params map[string]interface{}) any {
+	meta, _ := params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	if !ok {
+		return nil
+	}
+
+	switch token.(type) {
+	case string, float64:
+		return token
+	default:
+		return nil
+	}
+}
+
+// withToolProgress 若工具调用携带 progressToken，将 xiaohongshu 动作的步骤进度和排队位置转为 notifications/progress
+// 优先写入当前 POST 请求的 SSE 响应流，否则推送到会话的 GET SSE 连接
+func (s *AppServer) withToolProgress(ctx context.Context, params map[string]interface{}) context.Context {
+	token := progressTokenFromParams(params)
+	if token == nil {
+		return ctx
+	}
+
+	sink := progressSinkFromContext(ctx)
+	if sink == nil {
+		session := mcpSessionFromContext(ctx)
+		if session == nil {
+			return ctx
+		}
+		sink = func(message any) {
+			session.Events().Publish(message)
+		}
+	}
+
+	send := func(progress float64, total int, message string) {
+		sink(&JSONRPCNotification{
+			JSONRPC: "2.0",
+			Method:  notificationProgress,
+			Params: &ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      progress,
+				Total:         total,
+				Message:       message,
+			},
+		})
+	}
+
+	// 排队阶段的进度取 1/(position+1)，位置越靠前数值越大且始终小于第一个步骤的 1，
+	// 满足进度值必须递增的要求；位置变化的回调可能乱序到达，只推送更靠前的位置
+	var (
+		mu           sync.Mutex
+		lastPosition = -1
+	)
+	ctx = WithQueueObserver(ctx, func(position int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if position <= 0 || (lastPosition >= 0 && position >= lastPosition) {
+			return
+		}
+		lastPosition = position
+
+		send(1/float64(position+1), 0, fmt.Sprintf("排队中，前面还有 %d 个动作", position))
+	})
+
+	return xiaohongshu.WithProgress(ctx, func(progress, total int, message string) {
+		send(float64(progress), total, message)
+	})
+}
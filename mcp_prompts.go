@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// draftReplyMaxComments draft_reply 查找评论时最多加载的一级评论数量，同时展开各评论下的回复
+const draftReplyMaxComments = 200
+
+// MCPPrompt MCP 提示词模板定义
+type MCPPrompt struct {
+	Name        string              `json:"name"`
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+
+	get func(ctx context.Context, args map[string]string) (*MCPPromptResult, error)
+}
+
+// MCPPromptArgument MCP 提示词参数
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// registerPrompts 注册所有提示词模板
+func (s *AppServer) registerPrompts() {
+	s.prompts = []*MCPPrompt{
+		{
+			Name:        "write_post",
+			Title:       "根据产品信息写小红书笔记",
+			Description: "根据产品信息撰写符合小红书平台限制的图文笔记或长文，并调用发布工具",
+			Arguments: []MCPPromptArgument{
+				{Name: "product", Description: "产品信息，例如名称、卖点、价格、使用体验", Required: true},
+				{Name: "type", Description: "笔记类型：image（图文，默认）或 article（长文）"},
+				{Name: "audience", Description: "目标人群，例如「学生党」「新手妈妈」"},
+				{Name: "style", Description: "文案风格，例如「真实测评」「种草分享」"},
+			},
+			get: s.getWritePostPrompt,
+		},
+		{
+			Name:        "summarize_comments",
+			Title:       "总结笔记评论",
+			Description: "获取笔记详情和评论，总结评论区的主要观点、情绪和高频问题",
+			Arguments: []MCPPromptArgument{
+				{Name: "feed_id", Description: "笔记 ID", Required: true},
+				{Name: "xsec_token", Description: "访问令牌，来自 Feed 列表或搜索结果；最近获取过的笔记可以省略"},
+			},
+			get: s.getSummarizeCommentsPrompt,
+		},
+		{
+			Name:        "draft_reply",
+			Title:       "起草评论回复",
+			Description: "结合笔记内容和指定评论，起草一条回复；回复以一级评论发布在笔记下，不会挂在该评论下面",
+			Arguments: []MCPPromptArgument{
+				{Name: "feed_id", Description: "笔记 ID", Required: true},
+				{Name: "comment_id", Description: "要回复的评论 ID", Required: true},
+				{Name: "xsec_token", Description: "访问令牌，来自 Feed 列表或搜索结果；最近获取过的笔记可以省略"},
+				{Name: "tone", Description: "回复语气，例如「友好」「专业」「幽默」"},
+			},
+			get: s.getDraftReplyPrompt,
+		},
+	}
+}
+
+// processPromptsList 处理 prompts/list
+func (s *AppServer) processPromptsList(request *JSONRPCRequest) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"prompts": s.prompts,
+		},
+		ID: request.ID,
+	}
+}
+
+// processPromptsGet 处理 prompts/get
+func (s *AppServer) processPromptsGet(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	params, _ := request.Params.(map[string]interface{})
+	name, _ := params["name"].(string)
+
+	var prompt *MCPPrompt
+	for _, p := range s.prompts {
+		if p.Name == name {
+			prompt = p
+			break
+		}
+	}
+	if prompt == nil {
+		return promptErrorResponse(request, -32602, fmt.Sprintf("Unknown prompt: %s", name))
+	}
+
+	args := make(map[string]string)
+	if rawArgs, ok := params["arguments"].(map[string]interface{}); ok {
+		for k, v := range rawArgs {
+			if str, ok := v.(string); ok {
+				args[k] = strings.TrimSpace(str)
+			}
+		}
+	}
+
+	for _, arg := range prompt.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return promptErrorResponse(request, -32602, fmt.Sprintf("%s 缺少必需参数: %s", name, arg.Name))
+		}
+	}
+
+	logrus.Infof("MCP: 获取提示词 - %s", name)
+
+	result, err := prompt.get(ctx, args)
+	if err != nil {
+		logrus.WithError(err).Errorf("获取提示词失败: %s", name)
+		resp := promptErrorResponse(request, -32603, "获取提示词失败: "+err.Error())
+		if kind, ok := classifyActionError(err); ok {
+			resp.Error.Code = kind.RPCCode
+			resp.Error.Data = map[string]string{"code": kind.Code}
+		}
+		return resp
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  result,
+		ID:      request.ID,
+	}
+}
+
+func promptErrorResponse(request *JSONRPCRequest, code int, message string) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error: &JSONRPCError{
+			Code:    code,
+			Message: message,
+		},
+		ID: request.ID,
+	}
+}
+
+// getWritePostPrompt 根据产品信息写笔记，标题宽度等限制与发布工具的校验保持一致
+func (s *AppServer) getWritePostPrompt(_ context.Context, args map[string]string) (*MCPPromptResult, error) {
+	var b strings.Builder
+
+	b.WriteString("请根据下面的产品信息写一篇小红书笔记。\n\n")
+	fmt.Fprintf(&b, "产品信息：\n%s\n", args["product"])
+	if args["audience"] != "" {
+		fmt.Fprintf(&b, "目标人群：%s\n", args["audience"])
+	}
+	if args["style"] != "" {
+		fmt.Fprintf(&b, "文案风格：%s\n", args["style"])
+	}
+
+	b.WriteString("\n平台限制（发布工具会校验，超出会直接失败）：\n")
+	if args["type"] == "article" {
+		articleWidth := maxWidthOf[PublishArticleRequest]("Title")
+		fmt.Fprintf(&b, "- 标题最多 %d 个宽度单位，中文占 2 个、英文和数字占 1 个（约 %d 个中文字）\n", articleWidth, articleWidth/2)
+		b.WriteString("- 正文是长文，分段清晰，可以使用小标题\n")
+		b.WriteString("- 标签不要写进正文，单独放在 tags 参数中，不带 # 号\n")
+		b.WriteString("- 至少需要 1 张图片\n")
+		b.WriteString("\n写好后调用 publish_article 工具发布，参数为 title、content、tags、images。")
+	} else {
+		titleWidth := maxWidthOf[PublishRequest]("Title")
+		fmt.Fprintf(&b, "- 标题最多 %d 个宽度单位，中文占 2 个、英文和数字占 1 个（约 %d 个中文字）\n", titleWidth, titleWidth/2)
+		b.WriteString("- 正文口语化、分段短，适当使用 emoji\n")
+		b.WriteString("- 话题使用 #话题 语法，# 后不能有空格，话题之间用空格分隔，例如 #好物分享 #学生党；发布时话题会被移到正文末尾并逐个选中\n")
+		b.WriteString("- 至少需要 1 张图片，支持本地绝对路径或图片 URL\n")
+		b.WriteString("\n写好后调用 publish_content 工具发布，参数为 title、content、images。")
+	}
+
+	return &MCPPromptResult{
+		Description: "根据产品信息写小红书笔记",
+		Messages: []MCPPromptMessage{
+			{Role: "user", Content: MCPContent{Type: "text", Text: b.String()}},
+		},
+	}, nil
+}
+
+// getSummarizeCommentsPrompt 嵌入笔记详情和评论，要求总结评论区
+func (s *AppServer) getSummarizeCommentsPrompt(ctx context.Context, args map[string]string) (*MCPPromptResult, error) {
+	detail, resource, err := s.loadNoteForPrompt(ctx, args["feed_id"], args["xsec_token"], xiaohongshu.FeedDetailOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf(`请阅读笔记「%s」及其评论（已作为资源附上，当前加载了 %d 条评论），总结：
+1. 评论区的主要观点和讨论话题
+2. 整体情绪倾向（正面/中性/负面）及典型评论
+3. 高频问题或诉求，以及值得作者回复的评论（附评论 ID）`, detail.Note.Title, len(detail.Comments.List))
+
+	return &MCPPromptResult{
+		Description: "总结笔记评论",
+		Messages: []MCPPromptMessage{
+			{Role: "user", Content: MCPContent{Type: "resource", Resource: resource}},
+			{Role: "user", Content: MCPContent{Type: "text", Text: text}},
+		},
+	}, nil
+}
+
+// getDraftReplyPrompt 嵌入笔记详情并定位评论，要求起草回复
+// 目前没有回复指定评论的动作，起草的回复只能通过 post_comment_to_feed 以一级评论发布
+func (s *AppServer) getDraftReplyPrompt(ctx context.Context, args map[string]string) (*MCPPromptResult, error) {
+	detail, resource, err := s.loadNoteForPrompt(ctx, args["feed_id"], args["xsec_token"], xiaohongshu.FeedDetailOptions{
+		MaxComments:     draftReplyMaxComments,
+		LoadSubComments: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comment := findComment(detail.Comments.List, args["comment_id"])
+	if comment == nil {
+		return nil, fmt.Errorf("评论 %s 不在已加载的 %d 条一级评论及其回复中", args["comment_id"], len(detail.Comments.List))
+	}
+
+	tone := args["tone"]
+	if tone == "" {
+		tone = "友好、真诚"
+	}
+
+	nickname := comment.UserInfo.Nickname
+	if nickname == "" {
+		nickname = comment.UserInfo.NickName
+	}
+
+	text := fmt.Sprintf(`请以笔记作者的身份，结合笔记「%s」的内容（已作为资源附上），回复下面这条评论：
+
+评论者：%s
+评论内容：%s
+
+要求：语气%s，简短自然，不要使用营销腔，不要添加话题标签。
+起草完成后先给出回复内容。注意：目前没有回复指定评论的工具，post_comment_to_feed 只能在笔记下发布一级评论，不会出现在这条评论的回复中；
+如果确认以一级评论发布，建议以「@%s 」开头让对方知道是在回复 TA，再调用 post_comment_to_feed 工具发布（feed_id=%s）。`,
+		detail.Note.Title, nickname, comment.Content, tone, nickname, args["feed_id"])
+
+	return &MCPPromptResult{
+		Description: "起草评论回复",
+		Messages: []MCPPromptMessage{
+			{Role: "user", Content: MCPContent{Type: "resource", Resource: resource}},
+			{Role: "user", Content: MCPContent{Type: "text", Text: text}},
+		},
+	}, nil
+}
+
+// loadNoteForPrompt 通过 FeedDetailAction 获取笔记详情，并转换为嵌入资源，opts 控制加载的评论
+func (s *AppServer) loadNoteForPrompt(ctx context.Context, feedID, xsecToken string, opts xiaohongshu.FeedDetailOptions) (*xiaohongshu.FeedDetailResponse, *MCPResourceContents, error) {
+	if xsecToken == "" {
+		xsecToken = s.recentResources.token(resourceKindNote, feedID)
+	}
+	if xsecToken == "" {
+		return nil, nil, fmt.Errorf("缺少 xsec_token，请先通过 Feed 列表或搜索获取该笔记")
+	}
+
+	result, err := s.xiaohongshuService.GetFeedDetail(ctx, feedID, xsecToken, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail := result.Data
+	s.recentResources.remember(resourceKindNote, feedID, xsecToken, detail.Note.Title)
+	s.observeNoteEngagement(makeResourceURI(resourceKindNote, feedID, ""), detail.Note.InteractInfo)
+
+	resource, err := jsonResourceContents(makeResourceURI(resourceKindNote, feedID, xsecToken), detail)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return detail, resource, nil
+}
+
+// findComment 在评论及其子评论中查找指定 ID 的评论
+func findComment(comments []xiaohongshu.Comment, commentID string) *xiaohongshu.Comment {
+	for i := range comments {
+		if comments[i].ID == commentID {
+			return &comments[i]
+		}
+		if sub := findComment(comments[i].SubComments, commentID); sub != nil {
+			return sub
+		}
+	}
+	return nil
+}
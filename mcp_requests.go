@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// notificationCancelled 客户端取消请求的通知方法名
+const notificationCancelled = "notifications/cancelled"
+
+// MCPRequestRegistry 记录会话中正在执行的请求，按 JSON-RPC ID 索引，用于响应 notifications/cancelled
+type MCPRequestRegistry struct {
+	mu       sync.Mutex
+	inFlight map[string]context.CancelFunc
+}
+
+// NewMCPRequestRegistry 创建请求注册表
+func NewMCPRequestRegistry() *MCPRequestRegistry {
+	return &MCPRequestRegistry{
+		inFlight: make(map[string]context.CancelFunc),
+	}
+}
+
+// Register 登记一个正在执行的请求，返回可被取消的 context 和请求结束时调用的清理函数
+func (r *MCPRequestRegistry) Register(ctx context.Context, id any) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if id == nil {
+		return ctx, cancel
+	}
+
+	key := requestKey(id)
+
+	r.mu.Lock()
+	r.inFlight[key] = cancel
+	r.mu.Unlock()
+
+	done := func() {
+		r.mu.Lock()
+		delete(r.inFlight, key)
+		r.mu.Unlock()
+
+		cancel()
+	}
+
+	return ctx, done
+}
+
+// Cancel 取消指定 ID 的请求，返回该请求是否仍在执行
+func (r *MCPRequestRegistry) Cancel(id any) bool {
+	key := requestKey(id)
+
+	r.mu.Lock()
+	cancel, ok := r.inFlight[key]
+	delete(r.inFlight, key)
+	r.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// CancelAll 取消所有正在执行的请求，会话结束时调用
+func (r *MCPRequestRegistry) CancelAll() {
+	r.mu.Lock()
+	inFlight := r.inFlight
+	r.inFlight = make(map[string]context.CancelFunc)
+	r.mu.Unlock()
+
+	for _, cancel := range inFlight {
+		cancel()
+	}
+}
+
+// requestKey JSON-RPC ID 可能是字符串或数字，统一转成字符串作为索引
+func requestKey(id any) string {
+	return fmt.Sprintf("%v", id)
+}
+
+// processCancelled 处理 notifications/cancelled，取消对应请求的 context
+// 正在执行的浏览器动作会在下一个步骤边界停止
+func (s *AppServer) processCancelled(ctx context.Context, request *JSONRPCRequest) {
+	params, _ := request.Params.(map[string]interface{})
+	requestID, ok := params["requestId"]
+	if !ok {
+		return
+	}
+	reason, _ := params["reason"].(string)
+
+	session := mcpSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+
+	cancelled := session.Requests().Cancel(requestID)
+	logrus.WithFields(logrus.Fields{
+		"session":   session.ID,
+		"requestId": requestID,
+		"reason":    reason,
+		"inFlight":  cancelled,
+	}).Info("MCP 请求已取消")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+const (
+	// notificationResourceUpdated 订阅的资源发生变化时推送的通知
+	notificationResourceUpdated = "notifications/resources/updated"
+
+	// resourcePollInterval 轮询订阅笔记互动数据的间隔
+	resourcePollInterval = 5 * time.Minute
+
+	// resourcePollTimeout 单个笔记轮询的超时时间
+	resourcePollTimeout = 2 * time.Minute
+)
+
+// resourceWatcher 记录订阅笔记最近一次的互动数据，变化时通知订阅者
+type resourceWatcher struct {
+	mu         sync.Mutex
+	engagement map[string]xiaohongshu.InteractInfo
+
+	cancel  context.CancelFunc
+	done    chan struct{} // 后台轮询启动后，结束时关闭
+	stopped bool
+}
+
+// startResourceWatcher 第一次有订阅时启动后台轮询，服务关闭后不再启动
+func (s *AppServer) startResourceWatcher() {
+	w := &s.resourceWatcher
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped || w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		s.runResourceWatcher(ctx)
+	}()
+}
+
+// stopResourceWatcher 停止后台轮询，等待进行中的轮询结束
+func (s *AppServer) stopResourceWatcher() {
+	w := &s.resourceWatcher
+
+	w.mu.Lock()
+	w.stopped = true
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// runResourceWatcher 定时轮询所有会话订阅的笔记，ctx 结束时退出
+func (s *AppServer) runResourceWatcher(ctx context.Context) {
+	ticker := time.NewTicker(resourcePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.pollSubscribedResources(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollSubscribedResources 逐个获取订阅笔记的详情，比较互动数据
+// 每个笔记使用订阅 URI 中指定的账号，没有指定时使用默认账号
+func (s *AppServer) pollSubscribedResources(ctx context.Context) {
+	// 会话结束或空闲回收后，清理不再有人订阅的笔记
+	s.pruneNoteEngagement()
+
+	for _, uri := range s.mcpSessions.SubscribedURIs() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		parsed, err := parseResourceURI(uri)
+		if err != nil || parsed.Kind != resourceKindNote {
+			continue
+		}
+
+		xsecToken := s.recentResources.token(resourceKindNote, parsed.ID)
+		if xsecToken == "" {
+			logrus.WithField("uri", uri).Warn("订阅的笔记缺少 xsec_token，跳过轮询")
+			continue
+		}
+
+		pollCtx, cancel := context.WithTimeout(withAccount(ctx, parsed.Account), resourcePollTimeout)
+		result, err := s.xiaohongshuService.GetFeedDetail(pollCtx, parsed.ID, xsecToken, xiaohongshu.FeedDetailOptions{})
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logrus.WithError(err).WithField("uri", uri).Warn("轮询订阅笔记失败")
+			continue
+		}
+
+		s.observeNoteEngagement(uri, result.Data.Note.InteractInfo)
+	}
+}
+
+// observeNoteEngagement 记录订阅笔记的互动数据，与上一次记录不同时通知订阅者
+// 第一次记录只作为基线，不发送通知；没有会话订阅的笔记不记录
+func (s *AppServer) observeNoteEngagement(uri string, info xiaohongshu.InteractInfo) {
+	if !s.mcpSessions.Subscribed(uri) {
+		return
+	}
+
+	w := &s.resourceWatcher
+
+	w.mu.Lock()
+	if w.engagement == nil {
+		w.engagement = make(map[string]xiaohongshu.InteractInfo)
+	}
+	prev, seen := w.engagement[uri]
+	w.engagement[uri] = info
+	w.mu.Unlock()
+
+	if !seen || prev == info {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"uri":       uri,
+		"liked":     info.LikedCount,
+		"collected": info.CollectedCount,
+		"comments":  info.CommentCount,
+	}).Info("笔记互动数据已变化")
+
+	s.mcpSessions.NotifyResourceUpdated(uri)
+}
+
+// pruneNoteEngagement 删除已经没有会话订阅的笔记的互动数据记录
+func (s *AppServer) pruneNoteEngagement() {
+	subscribed := make(map[string]struct{})
+	for _, uri := range s.mcpSessions.SubscribedURIs() {
+		subscribed[uri] = struct{}{}
+	}
+
+	w := &s.resourceWatcher
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for uri := range w.engagement {
+		if _, ok := subscribed[uri]; !ok {
+			delete(w.engagement, uri)
+		}
+	}
+}
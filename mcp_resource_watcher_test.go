@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+func TestResourceURIAccount(t *testing.T) {
+	parsed, err := parseResourceURI("xhs://note/abc?xsec_token=t&account=brand-b")
+	require.NoError(t, err)
+	assert.Equal(t, "brand-b", parsed.Account)
+	assert.Equal(t, "xhs://note/abc?account=brand-b", parsed.key())
+
+	parsed, err = parseResourceURI("xhs://note/abc?xsec_token=t")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Account)
+	assert.Equal(t, "xhs://note/abc", parsed.key())
+
+	_, err = parseResourceURI("xhs://note/abc?account=../x")
+	assert.Error(t, err)
+}
+
+func TestResourceWatcherStops(t *testing.T) {
+	s := NewAppServer(&XiaohongshuService{})
+	t.Cleanup(s.mcpSessions.Close)
+
+	s.startResourceWatcher()
+	done := s.resourceWatcher.done
+	require.NotNil(t, done)
+
+	s.stopResourceWatcher()
+	select {
+	case <-done:
+	default:
+		t.Fatal("停止后后台轮询没有退出")
+	}
+
+	// 服务关闭后新的订阅不再启动轮询
+	s.startResourceWatcher()
+	assert.Equal(t, done, s.resourceWatcher.done)
+}
+
+func TestNoteEngagementOnlyForSubscribed(t *testing.T) {
+	s := NewAppServer(&XiaohongshuService{})
+	t.Cleanup(s.mcpSessions.Close)
+
+	key := "xhs://note/abc"
+	info := xiaohongshu.InteractInfo{LikedCount: "1"}
+
+	// 没有订阅的笔记读取后不记录
+	s.observeNoteEngagement(key, info)
+	assert.NotContains(t, s.resourceWatcher.engagement, key)
+
+	first := s.mcpSessions.Create()
+	second := s.mcpSessions.Create()
+	first.Subscribe(key, key+"?xsec_token=t")
+	second.Subscribe(key, key)
+	s.observeNoteEngagement(key, info)
+	assert.Contains(t, s.resourceWatcher.engagement, key)
+
+	// 还有其他会话订阅时保留记录
+	first.Unsubscribe(key)
+	s.pruneNoteEngagement()
+	assert.Contains(t, s.resourceWatcher.engagement, key)
+
+	// 最后一个订阅的会话结束后删除记录
+	s.mcpSessions.Delete(second.ID)
+	s.pruneNoteEngagement()
+	assert.NotContains(t, s.resourceWatcher.engagement, key)
+}
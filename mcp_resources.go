@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+const (
+	// resourceScheme 资源 URI 的 scheme
+	resourceScheme = "xhs"
+
+	// 资源类型，对应 URI 的 host 部分
+	resourceKindNote  = "note"
+	resourceKindUser  = "user"
+	resourceKindImage = "images"
+
+	// maxRecentResources 最近访问过的笔记和用户资源的保留数量
+	maxRecentResources = 100
+
+	// errCodeResourceNotFound MCP 规范中资源不存在的错误码
+	errCodeResourceNotFound = -32002
+)
+
+// resourceTemplates 服务端支持的资源 URI 模板
+var resourceTemplates = []MCPResourceTemplate{
+	{
+		URITemplate: "xhs://note/{id}{?xsec_token,account}",
+		Name:        "note",
+		Title:       "小红书笔记",
+		Description: "笔记详情，包括正文、图片、互动数据和评论。xsec_token 来自 Feed 列表或搜索结果，最近访问过的笔记可以省略；account 指定读取和订阅轮询使用的账号，不填使用默认账号",
+		MimeType:    "application/json",
+	},
+	{
+		URITemplate: "xhs://user/{id}{?xsec_token,account}",
+		Name:        "user",
+		Title:       "小红书用户主页",
+		Description: "用户基本信息、关注/粉丝/获赞数据和发布的笔记；account 指定读取使用的账号，不填使用默认账号",
+		MimeType:    "application/json",
+	},
+	{
+		URITemplate: "xhs://images/{hash}",
+		Name:        "image",
+		Title:       "本地缓存图片",
+		Description: "发布时通过 URL 下载到本地缓存的图片，hash 为图片 URL 的短哈希",
+	},
+}
+
+// resourceURI 解析后的资源 URI
+type resourceURI struct {
+	Kind      string
+	ID        string
+	XsecToken string
+	// Account 读取资源使用的账号，为空时使用默认账号
+	Account string
+}
+
+// key 规范化后的资源 URI，不带 xsec_token，指定了账号时带上账号，用于匹配订阅
+func (r *resourceURI) key() string {
+	uri := makeResourceURI(r.Kind, r.ID, "")
+	if r.Account == "" {
+		return uri
+	}
+	return uri + "?" + url.Values{"account": {r.Account}}.Encode()
+}
+
+// parseResourceURI 解析 xhs:// 资源 URI
+func parseResourceURI(raw string) (*resourceURI, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无效的资源 URI: %s", raw)
+	}
+	if u.Scheme != resourceScheme {
+		return nil, fmt.Errorf("不支持的资源 URI scheme: %s", u.Scheme)
+	}
+
+	id := strings.Trim(u.Path, "/")
+	if id == "" || strings.Contains(id, "/") {
+		return nil, fmt.Errorf("无效的资源 URI: %s", raw)
+	}
+
+	switch u.Host {
+	case resourceKindNote, resourceKindUser, resourceKindImage:
+	default:
+		return nil, fmt.Errorf("未知的资源类型: %s", u.Host)
+	}
+
+	account := u.Query().Get("account")
+	if account != "" {
+		if err := configs.ValidateAccountName(account); err != nil {
+			return nil, err
+		}
+	}
+
+	return &resourceURI{
+		Kind:      u.Host,
+		ID:        id,
+		XsecToken: u.Query().Get("xsec_token"),
+		Account:   account,
+	}, nil
+}
+
+// makeResourceURI 生成资源 URI，xsecToken 为空时不带查询参数
+func makeResourceURI(kind, id, xsecToken string) string {
+	uri := fmt.Sprintf("%s://%s/%s", resourceScheme, kind, url.PathEscape(id))
+	if xsecToken == "" {
+		return uri
+	}
+	return uri + "?" + url.Values{"xsec_token": {xsecToken}}.Encode()
+}
+
+// recentResources 最近访问过的笔记和用户，供 resources/list 列出，并为省略 xsec_token 的读取补全 token
+type recentResources struct {
+	mu        sync.Mutex
+	resources []MCPResource // 按最近访问时间倒序
+	tokens    map[string]string
+}
+
+// remember 记录一个资源，已存在时移到最前
+func (r *recentResources) remember(kind, id, xsecToken, title string) {
+	if id == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeResourceURI(kind, id, "")
+	if r.tokens == nil {
+		r.tokens = make(map[string]string)
+	}
+	if xsecToken != "" {
+		r.tokens[key] = xsecToken
+	}
+
+	resource := MCPResource{
+		URI:      makeResourceURI(kind, id, r.tokens[key]),
+		Name:     kind + "-" + id,
+		Title:    title,
+		MimeType: "application/json",
+	}
+
+	for i, existing := range r.resources {
+		if existing.Name == resource.Name {
+			if resource.Title == "" {
+				resource.Title = existing.Title
+			}
+			r.resources = append(r.resources[:i], r.resources[i+1:]...)
+			break
+		}
+	}
+
+	r.resources = append([]MCPResource{resource}, r.resources...)
+	if len(r.resources) > maxRecentResources {
+		dropped := r.resources[maxRecentResources:]
+		r.resources = r.resources[:maxRecentResources]
+		for _, d := range dropped {
+			delete(r.tokens, strings.SplitN(d.URI, "?", 2)[0])
+		}
+	}
+}
+
+// rememberFeeds 记录 Feed 列表中的笔记和作者
+func (r *recentResources) rememberFeeds(feeds []xiaohongshu.Feed) {
+	for _, feed := range feeds {
+		r.remember(resourceKindNote, feed.ID, feed.XsecToken, feed.NoteCard.DisplayTitle)
+
+		user := feed.NoteCard.User
+		nickname := user.Nickname
+		if nickname == "" {
+			nickname = user.NickName
+		}
+		r.remember(resourceKindUser, user.UserID, user.XsecToken, nickname)
+	}
+}
+
+// token 查找最近记录的 xsec_token
+func (r *recentResources) token(kind, id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tokens[makeResourceURI(kind, id, "")]
+}
+
+// list 返回最近访问过的资源
+func (r *recentResources) list() []MCPResource {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]MCPResource(nil), r.resources...)
+}
+
+// processResourcesList 处理 resources/list：最近访问过的笔记、用户以及本地缓存图片
+func (s *AppServer) processResourcesList(request *JSONRPCRequest) *JSONRPCResponse {
+	resources := s.recentResources.list()
+
+	images, err := downloader.ListCachedImages(configs.GetImagesPath())
+	if err != nil {
+		logrus.WithError(err).Warn("列出缓存图片失败")
+	}
+	for _, image := range images {
+		resources = append(resources, MCPResource{
+			URI:      makeResourceURI(resourceKindImage, image.Hash, ""),
+			Name:     "image-" + image.Hash,
+			MimeType: image.MimeType,
+			Size:     image.Size,
+		})
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"resources": resources,
+		},
+		ID: request.ID,
+	}
+}
+
+// processResourceTemplatesList 处理 resources/templates/list
+func (s *AppServer) processResourceTemplatesList(request *JSONRPCRequest) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"resourceTemplates": resourceTemplates,
+		},
+		ID: request.ID,
+	}
+}
+
+// processResourcesRead 处理 resources/read
+func (s *AppServer) processResourcesRead(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	uri, parsed, errResp := parseResourceParams(request)
+	if errResp != nil {
+		return errResp
+	}
+
+	logrus.Infof("MCP: 读取资源 - %s", uri)
+
+	ctx = withAccount(ctx, parsed.Account)
+	var contents *MCPResourceContents
+	var err error
+	switch parsed.Kind {
+	case resourceKindNote:
+		contents, err = s.readNoteResource(ctx, uri, parsed)
+	case resourceKindUser:
+		contents, err = s.readUserResource(ctx, uri, parsed)
+	case resourceKindImage:
+		contents, err = readImageResource(uri, parsed)
+	}
+
+	if err != nil {
+		logrus.WithError(err).Errorf("读取资源失败: %s", uri)
+		code := -32603
+		data := map[string]string{"uri": uri}
+		if parsed.Kind == resourceKindImage {
+			code = errCodeResourceNotFound
+		} else if kind, ok := classifyActionError(err); ok {
+			code = kind.RPCCode
+			data["code"] = kind.Code
+		}
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    code,
+				Message: "读取资源失败: " + err.Error(),
+				Data:    data,
+			},
+			ID: request.ID,
+		}
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"contents": []*MCPResourceContents{contents},
+		},
+		ID: request.ID,
+	}
+}
+
+// readNoteResource 读取笔记详情，笔记被订阅时同时记录互动数据用于变更通知
+func (s *AppServer) readNoteResource(ctx context.Context, uri string, parsed *resourceURI) (*MCPResourceContents, error) {
+	xsecToken := parsed.XsecToken
+	if xsecToken == "" {
+		xsecToken = s.recentResources.token(resourceKindNote, parsed.ID)
+	}
+	if xsecToken == "" {
+		return nil, fmt.Errorf("缺少 xsec_token，请使用 xhs://note/{id}?xsec_token=... 或先获取 Feed 列表")
+	}
+
+	result, err := s.xiaohongshuService.GetFeedDetail(ctx, parsed.ID, xsecToken, xiaohongshu.FeedDetailOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	note := result.Data.Note
+	s.recentResources.remember(resourceKindNote, parsed.ID, xsecToken, note.Title)
+	s.observeNoteEngagement(parsed.key(), note.InteractInfo)
+
+	return jsonResourceContents(uri, result.Data)
+}
+
+// readUserResource 读取用户主页
+func (s *AppServer) readUserResource(ctx context.Context, uri string, parsed *resourceURI) (*MCPResourceContents, error) {
+	xsecToken := parsed.XsecToken
+	if xsecToken == "" {
+		xsecToken = s.recentResources.token(resourceKindUser, parsed.ID)
+	}
+
+	profile, err := s.xiaohongshuService.GetUserProfile(ctx, parsed.ID, xsecToken)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recentResources.remember(resourceKindUser, parsed.ID, xsecToken, profile.UserBasicInfo.Nickname)
+
+	return jsonResourceContents(uri, profile)
+}
+
+// readImageResource 从下载器缓存读取图片
+func readImageResource(uri string, parsed *resourceURI) (*MCPResourceContents, error) {
+	image, err := downloader.FindCachedImage(configs.GetImagesPath(), parsed.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(image.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MCPResourceContents{
+		URI:      uri,
+		MimeType: image.MimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// jsonResourceContents 将数据序列化为 JSON 文本资源
+func jsonResourceContents(uri string, data any) (*MCPResourceContents, error) {
+	text, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &MCPResourceContents{
+		URI:      uri,
+		MimeType: "application/json",
+		Text:     string(text),
+	}, nil
+}
+
+// processResourcesSubscribe 处理 resources/subscribe，目前支持订阅笔记的互动数据变化
+func (s *AppServer) processResourcesSubscribe(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	uri, parsed, errResp := parseResourceParams(request)
+	if errResp != nil {
+		return errResp
+	}
+
+	if parsed.Kind != resourceKindNote {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    -32602,
+				Message: "仅支持订阅笔记资源: " + uri,
+			},
+			ID: request.ID,
+		}
+	}
+
+	session := mcpSessionFromContext(ctx)
+	if session == nil {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    -32600,
+				Message: "订阅资源需要 MCP 会话",
+			},
+			ID: request.ID,
+		}
+	}
+
+	if parsed.XsecToken != "" {
+		s.recentResources.remember(resourceKindNote, parsed.ID, parsed.XsecToken, "")
+	}
+
+	// 订阅按不带 xsec_token 的 URI 匹配，token 变化不影响订阅；不同账号的订阅分别轮询
+	session.Subscribe(parsed.key(), uri)
+	s.startResourceWatcher()
+
+	logrus.WithFields(logrus.Fields{
+		"session": session.ID,
+		"uri":     uri,
+	}).Info("MCP 资源已订阅")
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{},
+		ID:      request.ID,
+	}
+}
+
+// processResourcesUnsubscribe 处理 resources/unsubscribe
+func (s *AppServer) processResourcesUnsubscribe(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	_, parsed, errResp := parseResourceParams(request)
+	if errResp != nil {
+		return errResp
+	}
+
+	if session := mcpSessionFromContext(ctx); session != nil {
+		session.Unsubscribe(parsed.key())
+		s.pruneNoteEngagement()
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{},
+		ID:      request.ID,
+	}
+}
+
+// parseResourceParams 从请求参数中取出并解析 uri，失败时返回错误响应
+func parseResourceParams(request *JSONRPCRequest) (string, *resourceURI, *JSONRPCResponse) {
+	params, _ := request.Params.(map[string]interface{})
+	uri, _ := params["uri"].(string)
+
+	parsed, err := parseResourceURI(uri)
+	if err != nil {
+		return "", nil, &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JSONRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+			ID: request.ID,
+		}
+	}
+
+	return uri, parsed, nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// mcpSessionHeader MCP 会话 ID 请求/响应头
+	mcpSessionHeader = "Mcp-Session-Id"
+
+	// mcpSessionIdleTimeout 会话空闲超时时间，超时后会话被回收
+	mcpSessionIdleTimeout = 30 * time.Minute
+
+	// mcpSessionReapInterval 检查空闲会话的间隔
+	mcpSessionReapInterval = time.Minute
+
+	// latestProtocolVersion 服务端支持的最新协议版本
+	latestProtocolVersion = "2025-06-18"
+)
+
+// supportedProtocolVersions 服务端支持的协议版本，按从新到旧排序
+var supportedProtocolVersions = []string{
+	latestProtocolVersion,
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// MCPSession MCP 会话，保存协商结果和客户端信息
+type MCPSession struct {
+	ID              string
+	ProtocolVersion string
+	ClientInfo      map[string]any
+	Capabilities    map[string]any
+	CreatedAt       time.Time
+
+	events   *MCPEventBus
+	requests *MCPRequestRegistry
+
+	mu            sync.Mutex
+	initialized   bool
+	lastActive    time.Time
+	streams       int // 打开的 GET SSE 连接数量，有连接时会话不算空闲
+	subscriptions map[string]string
+}
+
+// touch 更新会话最近活跃时间
+func (s *MCPSession) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastActive = time.Now()
+}
+
+// idleSince 返回会话已空闲的时长，打开着 GET SSE 连接的会话不算空闲
+func (s *MCPSession) idleSince(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.streams > 0 {
+		return 0
+	}
+	return now.Sub(s.lastActive)
+}
+
+// openStream 记录打开的 GET SSE 连接，返回连接断开时调用的函数
+func (s *MCPSession) openStream() func() {
+	s.mu.Lock()
+	s.streams++
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		s.streams--
+		s.lastActive = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// setClientInfo 记录 initialize 协商的协议版本和客户端信息
+func (s *MCPSession) setClientInfo(protocolVersion string, clientInfo, capabilities map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ProtocolVersion = protocolVersion
+	s.ClientInfo = clientInfo
+	s.Capabilities = capabilities
+}
+
+// close 取消会话中正在执行的请求，并断开会话的 GET SSE 连接
+func (s *MCPSession) close() {
+	s.requests.CancelAll()
+	s.events.Close()
+}
+
+// Events 会话的事件总线
+func (s *MCPSession) Events() *MCPEventBus {
+	return s.events
+}
+
+// Requests 会话中正在执行的请求
+func (s *MCPSession) Requests() *MCPRequestRegistry {
+	return s.requests
+}
+
+// Notify 向会话推送 JSON-RPC 通知
+func (s *MCPSession) Notify(method string, params any) {
+	s.events.Publish(&JSONRPCNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// MarkInitialized 标记客户端已确认初始化完成
+func (s *MCPSession) MarkInitialized() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.initialized = true
+}
+
+// Initialized 客户端是否已确认初始化完成
+func (s *MCPSession) Initialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.initialized
+}
+
+// Subscribe 订阅资源更新，key 为规范化后的资源 URI，uri 为客户端订阅时使用的原始 URI
+func (s *MCPSession) Subscribe(key, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]string)
+	}
+	s.subscriptions[key] = uri
+}
+
+// Unsubscribe 取消订阅资源更新
+func (s *MCPSession) Unsubscribe(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscriptions, key)
+}
+
+// Subscription 返回客户端订阅该资源时使用的 URI
+func (s *MCPSession) Subscription(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uri, ok := s.subscriptions[key]
+	return uri, ok
+}
+
+// Subscriptions 会话订阅的全部资源（规范化 URI）
+func (s *MCPSession) Subscriptions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.subscriptions))
+	for key := range s.subscriptions {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// MCPSessionManager MCP 会话管理器
+type MCPSessionManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*MCPSession
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMCPSessionManager 创建会话管理器，并在后台定期回收空闲会话
+func NewMCPSessionManager() *MCPSessionManager {
+	m := &MCPSessionManager{
+		sessions: make(map[string]*MCPSession),
+		stop:     make(chan struct{}),
+	}
+
+	go m.runReaper()
+	return m
+}
+
+// runReaper 定期回收空闲超时的会话，直到管理器关闭
+func (m *MCPSessionManager) runReaper() {
+	ticker := time.NewTicker(mcpSessionReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.reapIdle(now)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// reapIdle 回收空闲超过 mcpSessionIdleTimeout 的会话
+func (m *MCPSessionManager) reapIdle(now time.Time) {
+	var idle []*MCPSession
+
+	m.mu.Lock()
+	for id, s := range m.sessions {
+		if s.idleSince(now) > mcpSessionIdleTimeout {
+			idle = append(idle, s)
+			delete(m.sessions, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range idle {
+		logrus.WithField("session", s.ID).Info("MCP 会话空闲超时，已回收")
+		s.close()
+	}
+}
+
+// Close 停止回收并结束所有会话，正在执行的请求被取消，GET SSE 连接被断开
+func (m *MCPSessionManager) Close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*MCPSession)
+	m.mu.Unlock()
+
+	for _, s := range sessions {
+		s.close()
+	}
+}
+
+// Create 创建新会话
+func (m *MCPSessionManager) Create() *MCPSession {
+	now := time.Now()
+	session := &MCPSession{
+		ID:              newSessionID(),
+		ProtocolVersion: latestProtocolVersion,
+		CreatedAt:       now,
+		events:          NewMCPEventBus(),
+		requests:        NewMCPRequestRegistry(),
+		lastActive:      now,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.ID] = session
+	return session
+}
+
+// Get 获取会话，并刷新其活跃时间
+func (m *MCPSessionManager) Get(id string) (*MCPSession, bool) {
+	m.mu.RLock()
+	session, ok := m.sessions[id]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if session.idleSince(time.Now()) > mcpSessionIdleTimeout {
+		m.Delete(id)
+		return nil, false
+	}
+
+	session.touch()
+	return session, true
+}
+
+// Delete 结束会话，取消会话中正在执行的请求并断开 GET SSE 连接，返回会话是否存在
+func (m *MCPSessionManager) Delete(id string) bool {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	session.close()
+	return true
+}
+
+// Broadcast 向所有已建立的会话推送 JSON-RPC 通知
+func (m *MCPSessionManager) Broadcast(method string, params any) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		session.Notify(method, params)
+	}
+}
+
+// NotifyResourceUpdated 向订阅了该资源的会话推送 notifications/resources/updated
+// 通知中的 uri 使用各会话订阅时的原始 URI
+func (m *MCPSessionManager) NotifyResourceUpdated(key string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		if uri, ok := session.Subscription(key); ok {
+			session.Notify(notificationResourceUpdated, map[string]interface{}{
+				"uri": uri,
+			})
+		}
+	}
+}
+
+// SubscribedURIs 所有会话订阅的资源（规范化 URI，去重）
+func (m *MCPSessionManager) SubscribedURIs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, session := range m.sessions {
+		for _, key := range session.Subscriptions() {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Subscribed 是否有会话订阅了该资源（规范化 URI）
+func (m *MCPSessionManager) Subscribed(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		if _, ok := session.Subscription(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Count 当前会话数量
+func (m *MCPSessionManager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.sessions)
+}
+
+// negotiateProtocolVersion 协商协议版本：支持客户端请求的版本则使用该版本，否则返回最新版本
+func negotiateProtocolVersion(requested string) string {
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return latestProtocolVersion
+}
+
+// newSessionID 生成随机会话 ID
+func newSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand 读取失败极少发生，退化为时间戳
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(buf)
+}
+
+type mcpSessionKey struct{}
+
+// withMCPSession 将会话放入 context
+func withMCPSession(ctx context.Context, session *MCPSession) context.Context {
+	return context.WithValue(ctx, mcpSessionKey{}, session)
+}
+
+// mcpSessionFromContext 从 context 中获取会话
+func mcpSessionFromContext(ctx context.Context) *MCPSession {
+	session, _ := ctx.Value(mcpSessionKey{}).(*MCPSession)
+	return session
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/jsonschema"
+)
+
+// MCPTool MCP 工具定义，inputSchema 和 outputSchema 分别由输入、输出结构体生成
+type MCPTool struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	InputSchema  map[string]any `json:"inputSchema"`
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
+
+	call func(ctx context.Context, args map[string]interface{}) *MCPToolResult
+}
+
+// MCPToolRegistry MCP 工具注册表
+type MCPToolRegistry struct {
+	mu    sync.RWMutex
+	tools []*MCPTool
+	index map[string]*MCPTool
+}
+
+// NewMCPToolRegistry 创建工具注册表，工具在服务启动时注册，运行期间不变
+func NewMCPToolRegistry() *MCPToolRegistry {
+	return &MCPToolRegistry{
+		index: make(map[string]*MCPTool),
+	}
+}
+
+// register 注册工具，同名工具会被替换
+func (r *MCPToolRegistry) register(tool *MCPTool) {
+	r.mu.Lock()
+	if old, ok := r.index[tool.Name]; ok {
+		for i, t := range r.tools {
+			if t == old {
+				r.tools[i] = tool
+			}
+		}
+	} else {
+		r.tools = append(r.tools, tool)
+	}
+	r.index[tool.Name] = tool
+	r.mu.Unlock()
+}
+
+// List 按注册顺序返回所有工具
+func (r *MCPToolRegistry) List() []*MCPTool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]*MCPTool, len(r.tools))
+	copy(tools, r.tools)
+	return tools
+}
+
+// Get 按名称查找工具
+func (r *MCPToolRegistry) Get(name string) (*MCPTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.index[name]
+	return tool, ok
+}
+
+// addTool 以输入结构体 In 和输出结构体 Out 声明工具
+// inputSchema、参数解码、参数校验和调用都由 In 推导，outputSchema 和 structuredContent 由 Out 推导
+// In 的字段使用 json tag 命名，description tag 描述，binding tag 声明校验规则（与 HTTP 接口一致）
+func addTool[In, Out any](r *MCPToolRegistry, name, description string, handler func(ctx context.Context, req *In) (*Out, error)) {
+	r.register(&MCPTool{
+		Name:         name,
+		Description:  description,
+		InputSchema:  jsonschema.For[In](),
+		OutputSchema: jsonschema.For[Out](),
+		call: func(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+			req, err := decodeToolArgs[In](args)
+			if err != nil {
+				return newToolErrorResult(fmt.Sprintf("%s 参数错误: %v", name, err))
+			}
+
+			out, err := handler(ctx, req)
+			if err != nil {
+				return newToolActionErrorResult(err)
+			}
+			return newToolStructuredResult(out)
+		},
+	})
+}
+
+// decodeToolArgs 将工具参数解码为输入结构体并校验
+func decodeToolArgs[In any](args map[string]interface{}) (*In, error) {
+	req := new(In)
+
+	if len(args) > 0 {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateRequest(req); err != nil {
+		return nil, formatValidationError(err)
+	}
+
+	return req, nil
+}
+
+// formatValidationError 将校验错误转换为按参数名描述的错误
+func formatValidationError(err error) error {
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	msg := ""
+	for i, fe := range errs {
+		if i > 0 {
+			msg += "; "
+		}
+		if fe.Param() != "" {
+			msg += fmt.Sprintf("%s 不满足 %s=%s", fe.Field(), fe.Tag(), fe.Param())
+		} else {
+			msg += fmt.Sprintf("%s 不满足 %s", fe.Field(), fe.Tag())
+		}
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// toolContentProvider 工具输出需要在 content 中附加文本以外的内容（例如图片）时实现
+type toolContentProvider interface {
+	toolContent() []MCPContent
+}
+
+// newToolStructuredResult 构造结构化工具结果，同时保留 JSON 文本作为不支持 structuredContent 的客户端的兜底
+func newToolStructuredResult(out any) *MCPToolResult {
+	jsonData, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return newToolErrorResult(fmt.Sprintf("结果序列化失败: %v", err))
+	}
+
+	content := []MCPContent{{
+		Type: "text",
+		Text: string(jsonData),
+	}}
+	if p, ok := out.(toolContentProvider); ok {
+		content = append(content, p.toolContent()...)
+	}
+
+	return &MCPToolResult{
+		Content:           content,
+		StructuredContent: out,
+	}
+}
+
+// newToolErrorResult 构造工具错误结果
+func newToolErrorResult(text string) *MCPToolResult {
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: text,
+		}},
+		IsError: true,
+	}
+}
+
+// newToolActionErrorResult 构造动作失败的工具错误结果
+// 类型化错误在文本前加上错误码，并通过 _meta.errorCode 返回，方便客户端区分未登录、验证码、限流等情况；
+// 保存了失败现场时附带现场目录，并通过 _meta.artifact 返回
+func newToolActionErrorResult(err error) *MCPToolResult {
+	text := err.Error()
+	meta := make(map[string]any)
+
+	if kind, ok := classifyActionError(err); ok {
+		text = fmt.Sprintf("[%s] %s", kind.Code, text)
+		meta["errorCode"] = kind.Code
+	}
+	if ref := artifactRefFromError(err); ref != nil {
+		text += fmt.Sprintf("\n失败现场已保存到 %s（%s）", ref.Dir, ref.URL)
+		meta["artifact"] = ref
+	}
+
+	result := newToolErrorResult(text)
+	if len(meta) > 0 {
+		result.Meta = meta
+	}
+	return result
+}
+
+// registerTools 注册所有 MCP 工具
+func (s *AppServer) registerTools() {
+	addTool(s.tools, "check_login_status", "检查小红书登录状态", s.handleCheckLoginStatus)
+	addTool(s.tools, "get_login_qrcode", "获取小红书登录二维码（图片），用小红书 App 扫码后调用 wait_for_login 完成登录；已登录时不返回二维码", s.handleGetLoginQRCode)
+	addTool(s.tools, "wait_for_login", "等待 get_login_qrcode 返回的二维码被扫码，登录成功后保存 cookies；超时未登录时可以再次调用", s.handleWaitForLogin)
+	addTool(s.tools, "publish_content", "发布小红书图文内容", s.handlePublishContent)
+	addTool(s.tools, "list_feeds", "获取用户发布的内容列表", s.handleListFeeds)
+	addTool(s.tools, "search_feeds", "搜索小红书内容（需要已登录）", s.handleSearchFeeds)
+	addTool(s.tools, "get_feed_detail", "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表", s.handleGetFeedDetail)
+	addTool(s.tools, "post_comment_to_feed", "发表评论到小红书笔记", s.handlePostComment)
+	addTool(s.tools, "like_feed", "点赞或取消点赞小红书笔记", s.handleLikeFeed)
+	addTool(s.tools, "collect_feed", "收藏或取消收藏小红书笔记", s.handleCollectFeed)
+	addTool(s.tools, "publish_article", "发布小红书长文章内容", s.handlePublishArticle)
+	addTool(s.tools, "list_actions", "列出进行中的动作，status 为 needs_human 的动作遇到了验证码，需要人工通过 live_view_url 在浏览器中完成验证", s.handleListActions)
+	addTool(s.tools, "get_action_screenshot", "获取进行中动作的页面截图，用于查看需要人工处理的验证码", s.handleGetActionScreenshot)
+	addTool(s.tools, "resume_action", "人工完成验证后通知动作继续执行（验证消失后动作会自动继续，通常不需要调用）", s.handleResumeAction)
+	addTool(s.tools, "list_accounts", "列出所有小红书账号及默认账号，其他工具的 account 参数从这里获取", s.handleListAccounts)
+	addTool(s.tools, "switch_default_account", "切换默认账号，之后没有指定 account 参数的工具调用都使用该账号", s.handleSwitchDefaultAccount)
+}
@@ -0,0 +1,170 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// captureTimeout 保存失败现场时每一项采集的超时时间，避免页面卡死时阻塞请求
+	captureTimeout = 10 * time.Second
+
+	// maxConsoleLines 最多保留的控制台日志行数
+	maxConsoleLines = 1000
+
+	// 失败现场中的文件名
+	FileScreenshot   = "screenshot.png"
+	FileHTML         = "page.html"
+	FileInitialState = "initial_state.json"
+	FileConsoleLog   = "console.log"
+	FileError        = "error.txt"
+)
+
+// initialStateJS 序列化 window.__INITIAL_STATE__，跳过循环引用和函数，并展开 Vue ref 的 _value
+const initialStateJS = `() => {
+	const state = window.__INITIAL_STATE__;
+	if (state === undefined) {
+		return "";
+	}
+	const seen = new WeakSet();
+	return JSON.stringify(state, (key, value) => {
+		if (typeof value === "function") {
+			return undefined;
+		}
+		if (value && typeof value === "object") {
+			if (seen.has(value)) {
+				return "[Circular]";
+			}
+			seen.add(value);
+			if ("_value" in value && "__v_isRef" in value) {
+				return value._value;
+			}
+		}
+		return value;
+	}, 2);
+}`
+
+// ConsoleRecorder 记录页面的控制台输出、未捕获异常和浏览器日志
+type ConsoleRecorder struct {
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	lines []string
+}
+
+// RecordConsole 开始记录页面的控制台输出，调用方在动作结束后调用 Stop
+func RecordConsole(page *rod.Page) *ConsoleRecorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &ConsoleRecorder{cancel: cancel}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.RuntimeConsoleAPICalled) {
+			args := make([]string, 0, len(e.Args))
+			for _, arg := range e.Args {
+				args = append(args, remoteObjectString(arg))
+			}
+			r.add(string(e.Type), strings.Join(args, " "))
+		},
+		func(e *proto.RuntimeExceptionThrown) {
+			text := e.ExceptionDetails.Text
+			if e.ExceptionDetails.Exception != nil {
+				text += " " + remoteObjectString(e.ExceptionDetails.Exception)
+			}
+			r.add("exception", text)
+		},
+		func(e *proto.LogEntryAdded) {
+			r.add(string(e.Entry.Level), fmt.Sprintf("[%s] %s %s", e.Entry.Source, e.Entry.Text, e.Entry.URL))
+		},
+	)
+	go wait()
+
+	return r
+}
+
+// Stop 停止记录
+func (r *ConsoleRecorder) Stop() {
+	r.cancel()
+}
+
+// Lines 已记录的日志
+func (r *ConsoleRecorder) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := make([]string, len(r.lines))
+	copy(lines, r.lines)
+	return lines
+}
+
+// add 追加一行日志，记录接收时间，超过上限时丢弃最早的日志
+func (r *ConsoleRecorder) add(level, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.lines) >= maxConsoleLines {
+		r.lines = r.lines[1:]
+	}
+	r.lines = append(r.lines, fmt.Sprintf("%s [%s] %s", time.Now().Format("15:04:05.000"), level, text))
+}
+
+func remoteObjectString(obj *proto.RuntimeRemoteObject) string {
+	if obj.Value.Nil() {
+		return obj.Description
+	}
+	if str, ok := obj.Value.Val().(string); ok {
+		return str
+	}
+	return obj.Value.JSON("", "")
+}
+
+// Capture 采集页面的失败现场：整页截图、outer HTML、__INITIAL_STATE__ 和控制台日志
+// 单项采集失败不会中断其他项，失败原因记录在 error.txt 中
+func Capture(page *rod.Page, console *ConsoleRecorder, actionErr error) map[string][]byte {
+	files := make(map[string][]byte)
+
+	var report strings.Builder
+	if actionErr != nil {
+		fmt.Fprintf(&report, "error: %+v\n", actionErr)
+	}
+	if info, err := page.Timeout(captureTimeout).Info(); err == nil {
+		fmt.Fprintf(&report, "url: %s\ntitle: %s\n", info.URL, info.Title)
+	}
+
+	if data, err := page.Timeout(captureTimeout).Screenshot(true, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	}); err == nil {
+		files[FileScreenshot] = data
+	} else {
+		fmt.Fprintf(&report, "screenshot failed: %v\n", err)
+	}
+
+	if html, err := page.Timeout(captureTimeout).HTML(); err == nil {
+		files[FileHTML] = []byte(html)
+	} else {
+		fmt.Fprintf(&report, "html failed: %v\n", err)
+	}
+
+	if result, err := page.Timeout(captureTimeout).Eval(initialStateJS); err == nil {
+		if state := result.Value.Str(); state != "" {
+			files[FileInitialState] = []byte(state)
+		}
+	} else {
+		fmt.Fprintf(&report, "initial state failed: %v\n", err)
+	}
+
+	if console != nil {
+		if lines := console.Lines(); len(lines) > 0 {
+			files[FileConsoleLog] = []byte(strings.Join(lines, "\n") + "\n")
+		}
+	}
+
+	files[FileError] = []byte(report.String())
+
+	return files
+}
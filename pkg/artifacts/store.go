@@ -0,0 +1,204 @@
+package artifacts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMaxArtifacts 默认最多保留的失败现场数量，超出后删除最旧的
+	DefaultMaxArtifacts = 200
+
+	// idTimeLayout ID 中的时间格式（后面再拼接毫秒），保证按字典序排序即按时间排序
+	idTimeLayout = "20060102-150405"
+)
+
+// ErrArtifactNotFound 失败现场或文件不存在
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// validID 失败现场 ID 只允许字母、数字、横线和下划线，防止路径穿越
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Artifact 一次失败动作保存的现场
+type Artifact struct {
+	ID        string    `json:"id"`
+	Dir       string    `json:"dir"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+// File 失败现场中的单个文件
+type File struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Store 失败现场存储，每个失败的请求在 dir 下对应一个子目录
+type Store struct {
+	dir          string
+	maxArtifacts int
+}
+
+// NewStore 创建失败现场存储，目录在第一次保存时创建
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:          dir,
+		maxArtifacts: DefaultMaxArtifacts,
+	}
+}
+
+// Dir 存储根目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save 保存一次失败现场，files 为文件名到内容的映射，返回新建的失败现场
+// name 用于 ID 中标识动作，例如 publish_content
+func (s *Store) Save(name string, files map[string][]byte) (*Artifact, error) {
+	id := newID(name, time.Now())
+	dir := filepath.Join(s.dir, id)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create artifact dir")
+	}
+
+	for fileName, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(fileName)), data, 0644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write artifact file %s", fileName)
+		}
+	}
+
+	s.prune(id)
+
+	return s.Get(id)
+}
+
+// List 列出所有失败现场，按时间倒序排列
+func (s *Store) List() ([]Artifact, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read artifact dir")
+	}
+
+	var artifacts []Artifact
+	for _, entry := range entries {
+		if !entry.IsDir() || !validID.MatchString(entry.Name()) {
+			continue
+		}
+
+		artifact, err := s.Get(entry.Name())
+		if err != nil {
+			continue
+		}
+		artifacts = append(artifacts, *artifact)
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].ID > artifacts[j].ID
+	})
+
+	return artifacts, nil
+}
+
+// Get 获取单个失败现场及其文件列表
+func (s *Store) Get(id string) (*Artifact, error) {
+	if !validID.MatchString(id) {
+		return nil, ErrArtifactNotFound
+	}
+
+	dir := filepath.Join(s.dir, id)
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, ErrArtifactNotFound
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read artifact")
+	}
+
+	artifact := &Artifact{
+		ID:        id,
+		Dir:       dir,
+		CreatedAt: info.ModTime(),
+		Files:     []File{},
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		artifact.Files = append(artifact.Files, File{Name: entry.Name(), Size: fi.Size()})
+	}
+
+	return artifact, nil
+}
+
+// FilePath 返回失败现场中文件的本地路径，文件不存在时返回 ErrArtifactNotFound
+func (s *Store) FilePath(id, name string) (string, error) {
+	if !validID.MatchString(id) || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", ErrArtifactNotFound
+	}
+
+	path := filepath.Join(s.dir, id, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", ErrArtifactNotFound
+	}
+
+	return path, nil
+}
+
+// prune 删除超出保留数量的最旧失败现场，keep 为刚保存的失败现场，始终保留
+func (s *Store) prune(keep string) {
+	if s.maxArtifacts <= 0 {
+		return
+	}
+
+	artifacts, err := s.List()
+	if err != nil || len(artifacts) <= s.maxArtifacts {
+		return
+	}
+
+	removed := 0
+	for i := len(artifacts) - 1; i >= 0 && len(artifacts)-removed > s.maxArtifacts; i-- {
+		if artifacts[i].ID == keep {
+			continue
+		}
+		_ = os.RemoveAll(artifacts[i].Dir)
+		removed++
+	}
+}
+
+// newID 生成失败现场 ID：时间-动作名-随机后缀
+func newID(name string, now time.Time) string {
+	buf := make([]byte, 3)
+	_, _ = rand.Read(buf)
+
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		name = "action"
+	}
+
+	return fmt.Sprintf("%s%03d-%s-%s", now.Format(idTimeLayout), now.Nanosecond()/int(time.Millisecond), name, hex.EncodeToString(buf))
+}
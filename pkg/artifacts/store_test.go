@@ -0,0 +1,73 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	artifacts, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, artifacts)
+
+	saved, err := store.Save("publish content", map[string][]byte{
+		FileHTML:  []byte("<html></html>"),
+		FileError: []byte("error: boom\n"),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, saved.ID, "-publish_content-")
+	assert.Len(t, saved.Files, 2)
+
+	artifacts, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, saved.ID, artifacts[0].ID)
+
+	path, err := store.FilePath(saved.ID, FileHTML)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<html></html>", string(data))
+
+	_, err = store.FilePath(saved.ID, FileScreenshot)
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+}
+
+func TestStoreRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(filepath.Join(dir, "artifacts"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+
+	_, err := store.Get("..")
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+
+	_, err = store.FilePath("..", "secret.txt")
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+
+	saved, err := store.Save("like", map[string][]byte{FileError: nil})
+	require.NoError(t, err)
+
+	_, err = store.FilePath(saved.ID, "../../secret.txt")
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+}
+
+func TestStorePrune(t *testing.T) {
+	store := NewStore(t.TempDir())
+	store.maxArtifacts = 2
+
+	for i := 0; i < 4; i++ {
+		_, err := store.Save("search", map[string][]byte{FileError: nil})
+		require.NoError(t, err)
+	}
+
+	artifacts, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, artifacts, 2)
+}
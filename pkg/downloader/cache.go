@@ -0,0 +1,101 @@
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/h2non/filetype"
+	"github.com/pkg/errors"
+)
+
+// CachedImage 表示下载器缓存目录中的一张图片
+type CachedImage struct {
+	Hash     string    // 图片 URL 的短哈希，即文件名 img_{hash}_{timestamp}.{ext} 中的 hash
+	Path     string    // 本地文件路径
+	MimeType string    // 根据扩展名推断的 MIME 类型
+	Size     int64     // 文件大小（字节）
+	ModTime  time.Time // 最后修改时间
+}
+
+// ListCachedImages 列出缓存目录中的图片，同一哈希只保留最新的一份，按修改时间倒序排列
+func ListCachedImages(dir string) ([]CachedImage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read image cache")
+	}
+
+	latest := make(map[string]CachedImage)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		hash, ext, ok := parseCachedFileName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		image := CachedImage{
+			Hash:     hash,
+			Path:     filepath.Join(dir, entry.Name()),
+			MimeType: filetype.GetType(ext).MIME.Value,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		}
+
+		if prev, ok := latest[hash]; !ok || image.ModTime.After(prev.ModTime) {
+			latest[hash] = image
+		}
+	}
+
+	images := make([]CachedImage, 0, len(latest))
+	for _, image := range latest {
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].ModTime.After(images[j].ModTime)
+	})
+
+	return images, nil
+}
+
+// FindCachedImage 按哈希查找缓存图片
+func FindCachedImage(dir, hash string) (*CachedImage, error) {
+	images, err := ListCachedImages(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range images {
+		if image.Hash == hash {
+			return &image, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cached image not found: %s", hash)
+}
+
+// parseCachedFileName 解析 generateFileName 生成的文件名，返回哈希和扩展名
+func parseCachedFileName(name string) (hash, ext string, ok bool) {
+	ext = strings.TrimPrefix(filepath.Ext(name), ".")
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+
+	parts := strings.Split(base, "_")
+	if len(parts) != 3 || parts[0] != "img" || parts[1] == "" || ext == "" {
+		return "", "", false
+	}
+
+	return parts[1], ext, true
+}
@@ -100,3 +100,38 @@ func TestImageDownloader_generateFileName(t *testing.T) {
 		t.Errorf("different URLs should generate different file names")
 	}
 }
+
+func TestListCachedImages(t *testing.T) {
+	dir := t.TempDir()
+
+	files := []string{
+		"img_aaaaaaaaaaaaaaaa_100.jpg",
+		"img_bbbbbbbbbbbbbbbb_200.png",
+		"readme.txt",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	images, err := ListCachedImages(dir)
+	if err != nil {
+		t.Fatalf("ListCachedImages error: %v", err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("len(images) = %d, expected 2", len(images))
+	}
+
+	image, err := FindCachedImage(dir, "bbbbbbbbbbbbbbbb")
+	if err != nil {
+		t.Fatalf("FindCachedImage error: %v", err)
+	}
+	if image.MimeType != "image/png" {
+		t.Errorf("MimeType = %q, expected image/png", image.MimeType)
+	}
+
+	if _, err := FindCachedImage(dir, "cccccccccccccccc"); err == nil {
+		t.Error("FindCachedImage should fail for unknown hash")
+	}
+}
@@ -0,0 +1,233 @@
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema JSON Schema 对象，直接使用 map 以便序列化到 MCP 协议中
+type Schema = map[string]any
+
+// 字段上用于生成 schema 的 tag
+const (
+	// tagDescription 字段说明
+	tagDescription = "description"
+	// tagEnum 字段可选值，逗号分隔
+	tagEnum = "enum"
+	// tagBinding gin 的校验规则，用于推导 required、minItems 等约束
+	tagBinding = "binding"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// For 根据类型 T 生成 JSON Schema
+func For[T any]() Schema {
+	return Reflect(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Reflect 根据 Go 类型生成 JSON Schema
+// 结构体字段名取自 json tag，说明取自 description tag，
+// 必填和长度约束取自 binding tag（与 HTTP 接口的参数校验保持一致）
+func Reflect(t reflect.Type) Schema {
+	return reflectType(t, map[reflect.Type]bool{})
+}
+
+// reflectType 生成类型的 schema，visiting 记录正在展开的结构体，用于处理递归类型
+func reflectType(t reflect.Type, visiting map[reflect.Type]bool) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if visiting[t] {
+			// 递归引用自身（例如评论的子评论），不再展开
+			return Schema{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		return reflectStruct(t, visiting)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte 按 encoding/json 的规则序列化为 base64 字符串
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": reflectType(t.Elem(), visiting)}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": reflectType(t.Elem(), visiting)}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	default:
+		// interface 等无法确定的类型，不做约束
+		return Schema{}
+	}
+}
+
+// reflectStruct 生成结构体的 object schema
+func reflectStruct(t reflect.Type, visiting map[reflect.Type]bool) Schema {
+	properties := Schema{}
+	var required []string
+
+	collectFields(t, visiting, properties, &required)
+
+	schema := Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectFields 收集结构体字段，匿名嵌入的结构体字段会被展开
+func collectFields(t reflect.Type, visiting map[reflect.Type]bool, properties Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omitEmpty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// 与 encoding/json 一致：没有 json 名称的匿名结构体字段会被展开，即使类型未导出
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, visiting, properties, required)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := reflectType(field.Type, visiting)
+		if desc := field.Tag.Get(tagDescription); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := field.Tag.Get(tagEnum); enum != "" {
+			prop["enum"] = strings.Split(enum, ",")
+		}
+
+		if applyBinding(prop, field.Tag.Get(tagBinding)) {
+			*required = append(*required, name)
+		} else if !omitEmpty && isNilable(field.Type) {
+			// 没有 omitempty 的 nil 切片、map、指针会被 encoding/json 序列化为 null
+			if typ, ok := prop["type"].(string); ok {
+				prop["type"] = []string{typ, "null"}
+			}
+		}
+
+		properties[name] = prop
+	}
+}
+
+// jsonName 解析 json tag，返回字段名、是否 omitempty 以及是否跳过该字段
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	omitEmpty := false
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// isNilable 类型的零值是否会被序列化为 null
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyBinding 将 binding 规则转换为 schema 约束，返回字段是否必填
+func applyBinding(prop Schema, binding string) bool {
+	if binding == "" {
+		return false
+	}
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+
+		switch key {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			applyLimit(prop, key, n)
+		case "maxwidth":
+			n, err := strconv.Atoi(value)
+			if err != nil || prop["type"] != "string" {
+				continue
+			}
+			applyMaxWidth(prop, n)
+		}
+	}
+
+	return required
+}
+
+// applyMaxWidth 按显示宽度限制的字符串：显示宽度不小于字符数，maxLength 取同样的上限，
+// 具体的宽度规则写进说明
+func applyMaxWidth(prop Schema, n int) {
+	prop["maxLength"] = n
+
+	note := fmt.Sprintf("最多 %d 个宽度单位，中文、日文、韩文占 2 个，英文和数字占 1 个（约 %d 个中文字）", n, n/2)
+	if desc, _ := prop["description"].(string); desc != "" {
+		note = desc + "，" + note
+	}
+	prop["description"] = note
+}
+
+// applyLimit 根据 schema 类型设置长度或数值范围
+func applyLimit(prop Schema, key string, n int) {
+	var name string
+	switch prop["type"] {
+	case "array":
+		name = map[string]string{"min": "minItems", "max": "maxItems"}[key]
+	case "string":
+		name = map[string]string{"min": "minLength", "max": "maxLength"}[key]
+	case "integer", "number":
+		name = map[string]string{"min": "minimum", "max": "maximum"}[key]
+	default:
+		return
+	}
+	prop[name] = n
+}
@@ -0,0 +1,65 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type embedded struct {
+	Account string `json:"account,omitempty" description:"账号"`
+}
+
+type sampleRequest struct {
+	embedded
+	Title   string            `json:"title" binding:"required,max=40" description:"标题"`
+	Images  []string          `json:"images" binding:"required,min=1"`
+	Count   int               `json:"count,omitempty" binding:"min=1,max=20"`
+	Mode    string            `json:"mode,omitempty" enum:"fast,normal"`
+	Name    string            `json:"name,omitempty" binding:"omitempty,maxwidth=20" description:"名称"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Ignored string            `json:"-"`
+	private string
+}
+
+func TestFor(t *testing.T) {
+	schema := For[sampleRequest]()
+
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, []string{"title", "images"}, schema["required"])
+
+	props := schema["properties"].(Schema)
+	assert.Len(t, props, 7)
+	assert.NotContains(t, props, "Ignored")
+	assert.NotContains(t, props, "private")
+
+	assert.Equal(t, Schema{"type": "string", "description": "账号"}, props["account"])
+	assert.Equal(t, Schema{"type": "string", "description": "标题", "maxLength": 40}, props["title"])
+	assert.Equal(t, Schema{"type": "array", "items": Schema{"type": "string"}, "minItems": 1}, props["images"])
+	assert.Equal(t, Schema{"type": "integer", "minimum": 1, "maximum": 20}, props["count"])
+	assert.Equal(t, []string{"fast", "normal"}, props["mode"].(Schema)["enum"])
+	assert.Equal(t, Schema{
+		"type":        "string",
+		"description": "名称，最多 20 个宽度单位，中文、日文、韩文占 2 个，英文和数字占 1 个（约 10 个中文字）",
+		"maxLength":   20,
+	}, props["name"])
+	assert.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "string"}}, props["labels"])
+}
+
+func TestForEmptyStruct(t *testing.T) {
+	schema := For[struct{}]()
+
+	assert.Equal(t, Schema{"type": "object", "properties": Schema{}}, schema)
+}
+
+type node struct {
+	Name     string `json:"name"`
+	Children []node `json:"children"`
+}
+
+func TestForRecursiveType(t *testing.T) {
+	schema := For[node]()
+
+	props := schema["properties"].(Schema)
+	assert.Equal(t, Schema{"type": []string{"array", "null"}, "items": Schema{"type": "object"}}, props["children"])
+}
@@ -18,7 +18,7 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 	router.Use(corsMiddleware())
 
 	// 健康检查
-	router.GET("/health", healthHandler)
+	router.GET("/health", appServer.healthHandler)
 
 	// MCP 端点 - 使用 Streamable HTTP 协议
 	mcpHandler := appServer.StreamableHTTPHandler()
@@ -29,6 +29,8 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 	api := router.Group("/api/v1")
 	{
 		api.GET("/login/status", appServer.checkLoginStatusHandler)
+		api.GET("/login/qrcode", appServer.getLoginQRCodeHandler)
+		api.POST("/login/wait", appServer.waitForLoginHandler)
 		api.POST("/publish", appServer.publishHandler)
 		api.GET("/feeds/list", appServer.listFeedsHandler)
 		api.GET("/feeds/search", appServer.searchFeedsHandler)
@@ -36,6 +38,16 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
 		api.POST("/feeds/comment", appServer.postCommentHandler)
 		api.POST("/feeds/like", appServer.likeFeedHandler)
 		api.POST("/feeds/collect", appServer.collectFeedHandler)
+		api.GET("/artifacts", appServer.listArtifactsHandler)
+		api.GET("/artifacts/:id", appServer.getArtifactHandler)
+		api.GET("/artifacts/:id/:file", appServer.getArtifactFileHandler)
+		api.GET("/actions", appServer.listActionsHandler)
+		api.GET("/actions/:id/screenshot", appServer.getActionScreenshotHandler)
+		api.POST("/actions/:id/resume", appServer.resumeActionHandler)
+		api.GET("/accounts", appServer.listAccountsHandler)
+		api.POST("/accounts/default", appServer.switchDefaultAccountHandler)
+		api.GET("/selectors", appServer.listSelectorsHandler)
+		api.POST("/selectors/reload", appServer.reloadSelectorsHandler)
 	}
 
 	return router
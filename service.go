@@ -2,49 +2,224 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"sync"
+	"time"
 
-	"github.com/mattn/go-runewidth"
-	"github.com/xpzouying/headless_browser"
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/artifacts"
 	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
 	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // XiaohongshuService 小红书业务服务
-type XiaohongshuService struct{
-	browser *headless_browser.Browser // 共享浏览器实例，用于调试时保持打开状态
+type XiaohongshuService struct {
+	scheduler *ActionScheduler // 动作调度器，同一账号的写动作互斥执行
+	events    serviceEvents    // 服务事件分发，用于推送 MCP 通知
+	artifacts *artifacts.Store // 动作失败时保存的现场（截图、DOM、控制台日志）
+	actions   *actionTracker   // 进行中的动作，遇到验证码时标记为等待人工处理
+	logins    *loginRegistry   // 等待扫码的登录页面
+	monitor   *sessionMonitor  // 会话健康状态，定期检查登录状态并在会话失效前告警
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*accountSession // 按账号名索引的会话，账号第一次执行动作时启动浏览器
 }
 
-// NewXiaohongshuService 创建小红书服务实例
-func NewXiaohongshuService() *XiaohongshuService {
-	return &XiaohongshuService{
-		browser: browser.NewBrowser(configs.IsHeadless()),
+// NewXiaohongshuService 创建小红书服务实例，并启动默认账号的浏览器，浏览器启动失败时返回错误
+func NewXiaohongshuService() (*XiaohongshuService, error) {
+	s := &XiaohongshuService{
+		scheduler: NewActionScheduler(configs.GetReadConcurrency()),
+		artifacts: artifacts.NewStore(configs.GetArtifactsPath()),
+		actions:   newActionTracker(),
+		logins:    newLoginRegistry(),
+		monitor:   newSessionMonitor(),
+		sessions:  make(map[string]*accountSession),
 	}
+
+	if _, err := s.session(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if interval := configs.GetSessionCheckInterval(); interval > 0 {
+		s.startSessionMonitor(interval)
+	}
+
+	// 遇到验证码时暂停动作，等待人工在浏览器中处理
+	xiaohongshu.SetVerificationHandler(s.waitForHuman)
+
+	return s, nil
+}
+
+// acquirePage 按动作类型排队获得执行权后，从 context 指定账号的页面池租用页面并绑定请求 context，
+// 请求被取消时页面上的导航和等待会随之中止
+// 调用方必须在动作结束后以动作的错误调用返回的 release 归还页面和执行权：
+// 动作失败时 release 会先保存失败现场，并返回附带现场信息的错误
+func (s *XiaohongshuService) acquirePage(ctx context.Context, kind ActionKind, name string) (*rod.Page, func(error) error, error) {
+	sess, err := s.session(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	done, err := s.scheduler.Acquire(ctx, sess.name, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	generation := sess.browser.Generation()
+
+	page, err := sess.pages.Acquire(ctx)
+	if err != nil {
+		done()
+		if ctx.Err() == nil {
+			checkBrowser(sess, generation)
+		}
+		return nil, nil, err
+	}
+
+	console := artifacts.RecordConsole(page)
+	action := s.actions.start(sess, name, kind, page)
+
+	release := func(actionErr error) error {
+		s.actions.finish(action)
+		console.Stop()
+		if actionErr != nil && ctx.Err() == nil && checkBrowser(sess, generation) {
+			// 浏览器已经重启，旧页面无法保存现场也不能复用
+			sess.pages.Discard(page)
+			done()
+			return errors.Wrap(actionErr, "浏览器失去响应，已重启")
+		}
+		if actionErr != nil {
+			actionErr = s.saveArtifact(page, console, name, actionErr)
+		} else {
+			s.saveSessionCookies(sess, page)
+		}
+		sess.pages.Release(page)
+		done()
+		return actionErr
+	}
+
+	return page.Context(ctx), release, nil
+}
+
+// checkBrowser 动作失败后检查账号的浏览器是否存活，失去响应时重启浏览器；返回动作开始后浏览器是否被重启过
+func checkBrowser(sess *accountSession, generation uint64) bool {
+	restarted, err := sess.browser.Check(context.Background(), generation)
+	if err != nil {
+		logrus.Errorf("账号 %s 的浏览器失去响应且重启失败: %v", sess.name, err)
+	}
+	return restarted
+}
+
+// retryRead 执行只读动作，动作失败且执行期间浏览器被重启过时重新执行一次
+// 只读动作可以安全地重复执行，写动作不重试，避免重复发布或评论
+func retryRead[T any](s *XiaohongshuService, ctx context.Context, name string, fn func() (T, error)) (T, error) {
+	sess, err := s.session(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	generation := sess.browser.Generation()
+
+	out, err := fn()
+	if err == nil || ctx.Err() != nil || sess.browser.Generation() == generation {
+		return out, err
+	}
+
+	logrus.Warnf("%s 执行期间浏览器已重启，重试一次: %v", name, err)
+	return fn()
+}
+
+// withHumanize 把请求中的操作节奏放入 context，动作中的点击、输入和停顿按该节奏执行
+// 参数已经由 binding 校验，为空时使用服务默认节奏
+func withHumanize(ctx context.Context, preset string) context.Context {
+	return xiaohongshu.WithHumanize(ctx, xiaohongshu.HumanizePreset(preset))
+}
+
+// saveArtifact 保存动作失败时的页面现场，返回附带现场信息的错误
+// 主动取消的动作不保存现场
+func (s *XiaohongshuService) saveArtifact(page *rod.Page, console *artifacts.ConsoleRecorder, name string, actionErr error) error {
+	if errors.Is(actionErr, context.Canceled) {
+		return actionErr
+	}
+
+	artifact, err := s.artifacts.Save(name, artifacts.Capture(page, console, actionErr))
+	if err != nil {
+		logrus.WithError(err).Warn("保存失败现场失败")
+		return actionErr
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"action": name,
+		"dir":    artifact.Dir,
+	}).Warnf("动作失败，已保存失败现场: %v", actionErr)
+
+	return &artifactError{err: actionErr, artifact: artifact}
+}
+
+// Artifacts 失败现场存储
+func (s *XiaohongshuService) Artifacts() *artifacts.Store {
+	return s.artifacts
+}
+
+// SchedulerStats 动作调度器统计信息
+func (s *XiaohongshuService) SchedulerStats() SchedulerStats {
+	return s.scheduler.Stats()
+}
+
+// BrowserStats 按账号统计的浏览器守护信息，包括是否存活和重启次数，只包含浏览器已经启动的账号
+func (s *XiaohongshuService) BrowserStats() map[string]browser.SupervisorStats {
+	stats := make(map[string]browser.SupervisorStats)
+	for name, sess := range s.runningSessions() {
+		stats[name] = sess.browser.Stats()
+	}
+	return stats
+}
+
+// PagePoolStats 按账号统计的页面池信息，只包含浏览器已经启动的账号
+func (s *XiaohongshuService) PagePoolStats() map[string]browser.PagePoolStats {
+	stats := make(map[string]browser.PagePoolStats)
+	for name, sess := range s.runningSessions() {
+		stats[name] = sess.pages.Stats()
+	}
+	return stats
 }
 
 // PublishRequest 发布请求
 type PublishRequest struct {
-	Title       string   `json:"title" binding:"required"`
-	Content     string   `json:"content" binding:"required"`
-	Images      []string `json:"images" binding:"required,min=1"`
-	PublishTime string   `json:"publish_time,omitempty"` // 可选的定时发布时间，格式: "2025-09-12 14:22"（北京时间）
+	Title       string   `json:"title" binding:"required,maxwidth=40" description:"内容标题"`
+	Content     string   `json:"content" binding:"required" description:"正文内容，支持话题标签"`
+	Images      []string `json:"images" binding:"required,min=1" description:"图片路径列表，支持绝对本地路径或URL（至少需要1张图片）"`
+	PublishTime string   `json:"publish_time,omitempty" description:"可选的定时发布时间，格式为 '2025-09-12 14:22'（北京时间），不提供则立即发布"`
+	Humanize    string   `json:"humanize,omitempty" binding:"omitempty,oneof=fast normal cautious" enum:"fast,normal,cautious" description:"可选的操作节奏：fast、normal 或 cautious，控制点击、输入的速度和停顿，不提供时使用服务默认值"`
+	Account     string   `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // PublishArticleRequest 发布文章请求
 type PublishArticleRequest struct {
-	Title       string   `json:"title" binding:"required"`
-	Content     string   `json:"content" binding:"required"`
-	Tags        []string `json:"tags,omitempty"`          // 标签列表
-	Images      []string `json:"images" binding:"required,min=1"`
-	PublishTime string   `json:"publish_time,omitempty"` // 可选的定时发布时间，格式: "2025-09-12 14:22"（北京时间）
+	Title       string   `json:"title" binding:"required,maxwidth=64" description:"文章标题"`
+	Content     string   `json:"content" binding:"required" description:"正文内容"`
+	Tags        []string `json:"tags,omitempty" description:"标签列表，与内容分开（可选）"`
+	Images      []string `json:"images" binding:"required,min=1" description:"图片路径列表，必须使用绝对路径或URL（至少需要1张图片）"`
+	PublishTime string   `json:"publish_time,omitempty" description:"可选的定时发布时间，格式为 '2025-09-12 14:22'（北京时间），不提供则立即发布"`
+	Humanize    string   `json:"humanize,omitempty" binding:"omitempty,oneof=fast normal cautious" enum:"fast,normal,cautious" description:"可选的操作节奏：fast、normal 或 cautious，控制点击、输入的速度和停顿，不提供时使用服务默认值"`
+	Account     string   `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // LoginStatusResponse 登录状态响应
 type LoginStatusResponse struct {
+	Account    string `json:"account"`
 	IsLoggedIn bool   `json:"is_logged_in"`
-	Username   string `json:"username,omitempty"`
+	// State 登录状态：logged_in、logged_out（没有登录 cookie）、expired（登录已失效）、risk_controlled（被风控）
+	State  xiaohongshu.LoginState `json:"state"`
+	Reason string                 `json:"reason,omitempty"`
+	// Username 当前登录用户的昵称
+	Username string                 `json:"username,omitempty"`
+	User     *xiaohongshu.LoginUser `json:"user,omitempty"`
+	// CookieExpiresAt 登录 cookie 的过期时间
+	CookieExpiresAt *time.Time `json:"cookie_expires_at,omitempty"`
 }
 
 // PublishResponse 发布响应
@@ -64,18 +239,38 @@ type FeedsListResponse struct {
 
 // CheckLoginStatus 检查登录状态
 func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatusResponse, error) {
-	page := s.browser.NewPage()
+	return retryRead(s, ctx, "check_login_status", func() (*LoginStatusResponse, error) {
+		return s.checkLoginStatus(ctx)
+	})
+}
+
+func (s *XiaohongshuService) checkLoginStatus(ctx context.Context) (_ *LoginStatusResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "check_login_status")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	loginAction := xiaohongshu.NewLogin(page)
 
-	isLoggedIn, err := loginAction.CheckLoginStatus(ctx)
+	status, err := loginAction.CheckLoginStatus(ctx, xiaohongshu.LoginStatusOptions{WithCounts: true})
 	if err != nil {
 		return nil, err
 	}
 
+	account := accountFromContext(ctx)
+	s.recordLoginStatus(account, status.State, status.Reason)
+
 	response := &LoginStatusResponse{
-		IsLoggedIn: isLoggedIn,
-		Username:   configs.Username,
+		Account:         account,
+		IsLoggedIn:      status.LoggedIn(),
+		State:           status.State,
+		Reason:          status.Reason,
+		User:            status.User,
+		CookieExpiresAt: status.CookieExpiresAt,
+	}
+	if status.User != nil {
+		response.Username = status.User.Nickname
 	}
 
 	return response, nil
@@ -83,13 +278,6 @@ func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatus
 
 // PublishContent 发布内容
 func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
-	// 验证标题长度
-	// 小红书限制：最大40个单位长度
-	// 中文/日文/韩文占2个单位，英文/数字占1个单位
-	if titleWidth := runewidth.StringWidth(req.Title); titleWidth > 40 {
-		return nil, fmt.Errorf("标题长度超过限制")
-	}
-
 	// 处理图片：下载URL图片或使用本地路径
 	imagePaths, err := s.processImages(req.Images)
 	if err != nil {
@@ -105,7 +293,9 @@ func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishReq
 	}
 
 	// 执行发布
-	if err := s.publishContent(ctx, content); err != nil {
+	err = s.publishContent(ctx, content)
+	s.emitPublishCompleted(accountFromContext(ctx), "image", req.Title, err)
+	if err != nil {
 		return nil, err
 	}
 
@@ -126,8 +316,12 @@ func (s *XiaohongshuService) processImages(images []string) ([]string, error) {
 }
 
 // publishContent 执行内容发布
-func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohongshu.PublishImageContent) error {
-	page := s.browser.NewPage()
+func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohongshu.PublishImageContent) (err error) {
+	page, release, err := s.acquirePage(ctx, ActionWrite, "publish_content")
+	if err != nil {
+		return err
+	}
+	defer func() { err = release(err) }()
 
 	action, err := xiaohongshu.NewPublishImageAction(page)
 	if err != nil {
@@ -138,15 +332,28 @@ func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohon
 	return action.Publish(ctx, content)
 }
 
-// ListFeeds 获取Feeds列表
-func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse, error) {
-	page := s.browser.NewPage()
+// ListFeeds 获取Feeds列表，limit 大于首屏数量时滚动加载更多，为 0 时只返回首屏
+func (s *XiaohongshuService) ListFeeds(ctx context.Context, limit int) (*FeedsListResponse, error) {
+	return retryRead(s, ctx, "list_feeds", func() (*FeedsListResponse, error) {
+		return s.listFeeds(ctx, limit)
+	})
+}
+
+func (s *XiaohongshuService) listFeeds(ctx context.Context, limit int) (_ *FeedsListResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "list_feeds")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	// 创建 Feeds 列表 action
-	action := xiaohongshu.NewFeedsListAction(page)
+	action, err := xiaohongshu.NewFeedsListAction(page)
+	if err != nil {
+		return nil, err
+	}
 
 	// 获取 Feeds 列表
-	feeds, err := action.GetFeedsList(ctx)
+	feeds, err := action.GetFeedsList(ctx, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -159,12 +366,23 @@ func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse,
 	return response, nil
 }
 
-func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string) (*FeedsListResponse, error) {
-	page := s.browser.NewPage()
+// SearchFeeds 搜索Feeds，limit 大于首屏数量时滚动加载更多，为 0 时只返回首屏
+func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string, limit int) (*FeedsListResponse, error) {
+	return retryRead(s, ctx, "search_feeds", func() (*FeedsListResponse, error) {
+		return s.searchFeeds(ctx, keyword, limit)
+	})
+}
+
+func (s *XiaohongshuService) searchFeeds(ctx context.Context, keyword string, limit int) (_ *FeedsListResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "search_feeds")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	action := xiaohongshu.NewSearchAction(page)
 
-	feeds, err := action.Search(ctx, keyword)
+	feeds, err := action.Search(ctx, keyword, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -177,15 +395,25 @@ func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string) (*
 	return response, nil
 }
 
-// GetFeedDetail 获取Feed详情
-func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*FeedDetailResponse, error) {
-	page := s.browser.NewPage()
+// GetFeedDetail 获取Feed详情，opts 控制评论的加载数量和是否展开回复
+func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToken string, opts xiaohongshu.FeedDetailOptions) (*FeedDetailResponse, error) {
+	return retryRead(s, ctx, "get_feed_detail", func() (*FeedDetailResponse, error) {
+		return s.getFeedDetail(ctx, feedID, xsecToken, opts)
+	})
+}
+
+func (s *XiaohongshuService) getFeedDetail(ctx context.Context, feedID, xsecToken string, opts xiaohongshu.FeedDetailOptions) (_ *FeedDetailResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "get_feed_detail")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	// 创建 Feed 详情 action
 	action := xiaohongshu.NewFeedDetailAction(page)
 
 	// 获取 Feed 详情
-	result, err := action.GetFeedDetail(ctx, feedID, xsecToken)
+	result, err := action.GetFeedDetail(ctx, feedID, xsecToken, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -198,15 +426,38 @@ func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToke
 	return response, nil
 }
 
+// GetUserProfile 获取用户主页信息
+func (s *XiaohongshuService) GetUserProfile(ctx context.Context, userID, xsecToken string) (*xiaohongshu.UserProfileResponse, error) {
+	return retryRead(s, ctx, "get_user_profile", func() (*xiaohongshu.UserProfileResponse, error) {
+		return s.getUserProfile(ctx, userID, xsecToken)
+	})
+}
+
+func (s *XiaohongshuService) getUserProfile(ctx context.Context, userID, xsecToken string) (_ *xiaohongshu.UserProfileResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "get_user_profile")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
+
+	action := xiaohongshu.NewUserProfileAction(page)
+
+	return action.GetUserProfile(ctx, userID, xsecToken)
+}
+
 // PostCommentToFeed 发表评论到Feed
-func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string) (*PostCommentResponse, error) {
-	page := s.browser.NewPage()
+func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsecToken, content string) (_ *PostCommentResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionWrite, "post_comment")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	// 创建 Feed 评论 action
 	action := xiaohongshu.NewCommentFeedAction(page)
 
 	// 发表评论
-	err := action.PostComment(ctx, feedID, xsecToken, content)
+	err = action.PostComment(ctx, feedID, xsecToken, content)
 	if err != nil {
 		return nil, err
 	}
@@ -221,8 +472,12 @@ func (s *XiaohongshuService) PostCommentToFeed(ctx context.Context, feedID, xsec
 }
 
 // LikeFeed 点赞或取消点赞Feed
-func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken string) (*LikeFeedResponse, error) {
-	page := s.browser.NewPage()
+func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken string) (_ *LikeFeedResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionWrite, "like_feed")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	// 创建 Feed 点赞 action
 	action := xiaohongshu.NewLikeFeedAction(page)
@@ -256,8 +511,12 @@ func (s *XiaohongshuService) LikeFeed(ctx context.Context, feedID, xsecToken str
 }
 
 // CollectFeed 收藏或取消收藏Feed
-func (s *XiaohongshuService) CollectFeed(ctx context.Context, feedID, xsecToken string) (*CollectFeedResponse, error) {
-	page := s.browser.NewPage()
+func (s *XiaohongshuService) CollectFeed(ctx context.Context, feedID, xsecToken string) (_ *CollectFeedResponse, err error) {
+	page, release, err := s.acquirePage(ctx, ActionWrite, "collect_feed")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
 
 	// 创建 Feed 收藏 action
 	action := xiaohongshu.NewCollectFeedAction(page)
@@ -292,11 +551,6 @@ func (s *XiaohongshuService) CollectFeed(ctx context.Context, feedID, xsecToken
 
 // PublishArticle 发布文章
 func (s *XiaohongshuService) PublishArticle(ctx context.Context, req *PublishArticleRequest) (*PublishResponse, error) {
-	// 验证标题长度
-	if titleWidth := runewidth.StringWidth(req.Title); titleWidth > 64 {
-		return nil, fmt.Errorf("文章标题长度超过限制（最大64字符）")
-	}
-
 	// 处理图片：下载URL图片或使用本地路径
 	imagePaths, err := s.processImages(req.Images)
 	if err != nil {
@@ -313,7 +567,9 @@ func (s *XiaohongshuService) PublishArticle(ctx context.Context, req *PublishArt
 	}
 
 	// 执行发布
-	if err := s.publishArticle(ctx, content); err != nil {
+	err = s.publishArticle(ctx, content)
+	s.emitPublishCompleted(accountFromContext(ctx), "article", req.Title, err)
+	if err != nil {
 		return nil, err
 	}
 
@@ -328,8 +584,12 @@ func (s *XiaohongshuService) PublishArticle(ctx context.Context, req *PublishArt
 }
 
 // publishArticle 执行文章发布
-func (s *XiaohongshuService) publishArticle(ctx context.Context, content xiaohongshu.PublishArticleContent) error {
-	page := s.browser.NewPage()
+func (s *XiaohongshuService) publishArticle(ctx context.Context, content xiaohongshu.PublishArticleContent) (err error) {
+	page, release, err := s.acquirePage(ctx, ActionWrite, "publish_article")
+	if err != nil {
+		return err
+	}
+	defer func() { err = release(err) }()
 
 	action, err := xiaohongshu.NewPublishArticleAction(page)
 	if err != nil {
@@ -340,9 +600,9 @@ func (s *XiaohongshuService) publishArticle(ctx context.Context, content xiaohon
 	return action.Publish(ctx, content)
 }
 
-// Close 关闭浏览器实例，用于清理资源
+// Close 关闭所有账号的浏览器实例，用于清理资源
 func (s *XiaohongshuService) Close() {
-	if s.browser != nil {
-		s.browser.Close()
-	}
+	s.monitor.close()
+	s.logins.closeAll()
+	s.closeSessions()
 }
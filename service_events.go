@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+// 服务端推送的通知方法名
+const (
+	notificationLoginStatusChanged  = "notifications/xiaohongshu/login_status_changed"
+	notificationPublishCompleted    = "notifications/xiaohongshu/publish_completed"
+	notificationActionStatusChanged = "notifications/xiaohongshu/action_status_changed"
+	notificationSessionWarning      = "notifications/xiaohongshu/session_warning"
+)
+
+// ServiceEventHandler 服务事件回调，用于向 MCP 客户端推送通知
+type ServiceEventHandler func(method string, params any)
+
+// serviceEvents 服务层事件分发，记录上一次的登录状态以便只在变化时通知
+type serviceEvents struct {
+	mu              sync.Mutex
+	handler         ServiceEventHandler
+	lastLoginStatus map[string]xiaohongshu.LoginState // 按账号记录
+}
+
+// LoginStatusChangedEvent 登录状态变化通知参数
+type LoginStatusChangedEvent struct {
+	Account    string                 `json:"account"`
+	IsLoggedIn bool                   `json:"is_logged_in"`
+	State      xiaohongshu.LoginState `json:"state"`
+	Timestamp  string                 `json:"timestamp"`
+}
+
+// PublishCompletedEvent 发布完成通知参数
+type PublishCompletedEvent struct {
+	Account   string `json:"account"`
+	Type      string `json:"type"` // image 或 article
+	Title     string `json:"title"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+// SessionWarningEvent 会话告警通知参数，同时作为 webhook 的请求体
+type SessionWarningEvent struct {
+	Account         string                 `json:"account"`
+	Kind            SessionWarningKind     `json:"kind"` // invalid、expiring 或 recovered
+	State           xiaohongshu.LoginState `json:"state,omitempty"`
+	Message         string                 `json:"message"`
+	CookieExpiresAt *time.Time             `json:"cookie_expires_at,omitempty"`
+	Timestamp       string                 `json:"timestamp"`
+}
+
+// SetEventHandler 设置服务事件回调
+func (s *XiaohongshuService) SetEventHandler(handler ServiceEventHandler) {
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+
+	s.events.handler = handler
+}
+
+// emit 分发服务事件，未设置回调时忽略
+func (s *XiaohongshuService) emit(method string, params any) {
+	s.events.mu.Lock()
+	handler := s.events.handler
+	s.events.mu.Unlock()
+
+	if handler != nil {
+		handler(method, params)
+	}
+}
+
+// emitLoginStatus 账号的登录状态发生变化时推送通知
+func (s *XiaohongshuService) emitLoginStatus(account string, state xiaohongshu.LoginState) {
+	s.events.mu.Lock()
+	if s.events.lastLoginStatus == nil {
+		s.events.lastLoginStatus = make(map[string]xiaohongshu.LoginState)
+	}
+	last, ok := s.events.lastLoginStatus[account]
+	changed := !ok || last != state
+	s.events.lastLoginStatus[account] = state
+	s.events.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	s.emit(notificationLoginStatusChanged, &LoginStatusChangedEvent{
+		Account:    account,
+		IsLoggedIn: state == xiaohongshu.LoginStateLoggedIn,
+		State:      state,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	})
+}
+
+// emitPublishCompleted 推送发布完成通知
+func (s *XiaohongshuService) emitPublishCompleted(account, publishType, title string, err error) {
+	event := &PublishCompletedEvent{
+		Account:   account,
+		Type:      publishType,
+		Title:     title,
+		Success:   err == nil,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	s.emit(notificationPublishCompleted, event)
+}
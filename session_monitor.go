@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+const (
+	// sessionCheckTimeout 一次定期登录检查的超时时间，包括排队等待页面的时间
+	sessionCheckTimeout = 2 * time.Minute
+
+	// sessionWebhookTimeout 发送会话告警 webhook 的超时时间
+	sessionWebhookTimeout = 10 * time.Second
+)
+
+// SessionWarningKind 会话告警类型
+type SessionWarningKind string
+
+const (
+	// SessionWarningInvalid 登录已失效、未登录或被风控
+	SessionWarningInvalid SessionWarningKind = "invalid"
+	// SessionWarningExpiring 登录 cookie 即将过期
+	SessionWarningExpiring SessionWarningKind = "expiring"
+	// SessionWarningRecovered 之前告警的会话恢复正常
+	SessionWarningRecovered SessionWarningKind = "recovered"
+)
+
+// SessionHealth 账号会话的健康状态
+type SessionHealth struct {
+	// State 最近一次检查到的登录状态
+	State      xiaohongshu.LoginState `json:"state,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	CheckedAt  *time.Time             `json:"checked_at,omitempty"`
+	CheckError string                 `json:"check_error,omitempty"`
+	// CookieExpiresAt 浏览器中登录 cookie 的过期时间，每次动作成功后更新
+	CookieExpiresAt *time.Time `json:"cookie_expires_at,omitempty"`
+	// CookiesSavedAt 最近一次保存 cookies 的时间
+	CookiesSavedAt *time.Time `json:"cookies_saved_at,omitempty"`
+	// Warning 当前的告警，会话正常时为空
+	Warning SessionWarningKind `json:"warning,omitempty"`
+}
+
+// sessionHealth 账号会话的健康状态和最近一次保存的 cookies
+type sessionHealth struct {
+	SessionHealth
+	savedCookies []byte // 没有变化时不重复写入
+}
+
+// sessionMonitor 按账号跟踪会话健康状态，定期检查登录状态，会话失效或登录 cookie 即将过期时告警
+type sessionMonitor struct {
+	mu     sync.Mutex
+	health map[string]*sessionHealth
+	client *http.Client
+
+	cancel context.CancelFunc
+	done   chan struct{} // 定期检查启动后，结束时关闭
+}
+
+func newSessionMonitor() *sessionMonitor {
+	return &sessionMonitor{
+		health: make(map[string]*sessionHealth),
+		client: &http.Client{Timeout: sessionWebhookTimeout},
+	}
+}
+
+// get 账号的健康状态，调用方持有 m.mu
+func (m *sessionMonitor) get(account string) *sessionHealth {
+	h, ok := m.health[account]
+	if !ok {
+		h = &sessionHealth{}
+		m.health[account] = h
+	}
+	return h
+}
+
+// update 在锁内修改账号的健康状态
+func (m *sessionMonitor) update(account string, fn func(h *sessionHealth)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(m.get(account))
+}
+
+// stats 所有账号的健康状态
+func (m *sessionMonitor) stats() map[string]SessionHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]SessionHealth, len(m.health))
+	for account, h := range m.health {
+		stats[account] = h.SessionHealth
+	}
+	return stats
+}
+
+// close 停止定期检查
+func (m *sessionMonitor) close() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// sessionWarning 根据健康状态判断是否需要告警，返回告警类型和说明；会话正常时返回空
+func sessionWarning(h SessionHealth, now time.Time, threshold time.Duration) (SessionWarningKind, string) {
+	switch h.State {
+	case xiaohongshu.LoginStateExpired:
+		return SessionWarningInvalid, "登录已失效，需要重新登录"
+	case xiaohongshu.LoginStateLoggedOut:
+		return SessionWarningInvalid, "账号未登录"
+	case xiaohongshu.LoginStateRiskControlled:
+		return SessionWarningInvalid, "账号被风控：" + h.Reason
+	}
+
+	if h.CookieExpiresAt != nil && h.CookieExpiresAt.Sub(now) < threshold {
+		return SessionWarningExpiring, fmt.Sprintf("登录 cookie 将于 %s 过期，请尽快重新登录", h.CookieExpiresAt.Local().Format(time.DateTime))
+	}
+	return "", ""
+}
+
+// SessionHealth 按账号统计的会话健康状态，只包含检查过或执行过动作的账号
+func (s *XiaohongshuService) SessionHealth() map[string]SessionHealth {
+	return s.monitor.stats()
+}
+
+// startSessionMonitor 定期检查浏览器已经启动的账号的登录状态，启动时先检查一次
+func (s *XiaohongshuService) startSessionMonitor(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.monitor.cancel = cancel
+	s.monitor.done = make(chan struct{})
+
+	go func() {
+		defer close(s.monitor.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, sess := range s.runningSessions() {
+				s.checkSession(ctx, sess)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// checkSession 检查账号的登录状态，动作成功后 acquirePage 会顺带保存 cookies 并更新过期时间
+func (s *XiaohongshuService) checkSession(ctx context.Context, sess *accountSession) {
+	ctx, cancel := context.WithTimeout(withAccount(ctx, sess.name), sessionCheckTimeout)
+	defer cancel()
+
+	status, err := s.checkSessionLogin(ctx)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return
+		}
+		logrus.Warnf("定期检查账号 %s 的登录状态失败: %v", sess.name, err)
+		s.monitor.update(sess.name, func(h *sessionHealth) {
+			h.CheckError = err.Error()
+		})
+		return
+	}
+
+	s.recordLoginStatus(sess.name, status.State, status.Reason)
+}
+
+func (s *XiaohongshuService) checkSessionLogin(ctx context.Context) (_ *xiaohongshu.LoginStatus, err error) {
+	page, release, err := s.acquirePage(ctx, ActionRead, "session_check")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = release(err) }()
+
+	// 定期检查不读取关注数和粉丝数，只打开首页
+	return xiaohongshu.NewLogin(page).CheckLoginStatus(ctx, xiaohongshu.LoginStatusOptions{})
+}
+
+// recordLoginStatus 记录账号检查到的登录状态，推送登录状态变化通知，并按需告警
+func (s *XiaohongshuService) recordLoginStatus(account string, state xiaohongshu.LoginState, reason string) {
+	s.emitLoginStatus(account, state)
+
+	now := time.Now()
+	s.monitor.update(account, func(h *sessionHealth) {
+		h.State = state
+		h.Reason = reason
+		h.CheckedAt = &now
+		h.CheckError = ""
+	})
+	s.evaluateSession(account)
+}
+
+// saveSessionCookies 动作成功后保存浏览器中刷新过的 cookies，并记录登录 cookie 的过期时间
+// 浏览器中没有登录 cookie 时不保存，避免覆盖之前仍然有效的 cookies
+func (s *XiaohongshuService) saveSessionCookies(sess *accountSession, page *rod.Page) {
+	cks, err := page.Browser().GetCookies()
+	if err != nil {
+		logrus.Debugf("读取账号 %s 的 cookies 失败: %v", sess.name, err)
+		return
+	}
+
+	session := xiaohongshu.SessionCookie(cks)
+	if session == nil {
+		return
+	}
+	data, err := json.Marshal(cks)
+	if err != nil {
+		return
+	}
+
+	var expiresAt *time.Time
+	if !session.Session {
+		expires := session.Expires.Time()
+		expiresAt = &expires
+	}
+
+	var changed bool
+	s.monitor.update(sess.name, func(h *sessionHealth) {
+		h.CookieExpiresAt = expiresAt
+		changed = !bytes.Equal(h.savedCookies, data)
+	})
+
+	if changed {
+		if err := sess.cookies.SaveCookies(data); err != nil {
+			logrus.Warnf("保存账号 %s 的 cookies 失败: %v", sess.name, err)
+		} else {
+			now := time.Now()
+			s.monitor.update(sess.name, func(h *sessionHealth) {
+				h.savedCookies = data
+				h.CookiesSavedAt = &now
+			})
+		}
+	}
+
+	s.evaluateSession(sess.name)
+}
+
+// evaluateSession 账号的告警发生变化时通过日志、MCP 通知和 webhook 告警
+func (s *XiaohongshuService) evaluateSession(account string) {
+	var (
+		kind, previous SessionWarningKind
+		message        string
+		health         SessionHealth
+	)
+	s.monitor.update(account, func(h *sessionHealth) {
+		kind, message = sessionWarning(h.SessionHealth, time.Now(), configs.GetSessionExpiryWarning())
+		previous = h.Warning
+		h.Warning = kind
+		health = h.SessionHealth
+	})
+
+	if kind == previous {
+		return
+	}
+
+	if kind == "" {
+		kind = SessionWarningRecovered
+		message = "会话已恢复正常"
+		logrus.Infof("账号 %s %s", account, message)
+	} else {
+		logrus.Warnf("账号 %s %s", account, message)
+	}
+
+	event := &SessionWarningEvent{
+		Account:         account,
+		Kind:            kind,
+		State:           health.State,
+		Message:         message,
+		CookieExpiresAt: health.CookieExpiresAt,
+		Timestamp:       time.Now().Format(time.RFC3339),
+	}
+	s.emit(notificationSessionWarning, event)
+	go s.monitor.postWebhook(event)
+}
+
+// postWebhook 以 JSON 向配置的 webhook 发送会话告警，未配置时忽略
+func (m *sessionMonitor) postWebhook(event *SessionWarningEvent) {
+	url := configs.GetSessionWebhook()
+	if url == "" {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	resp, err := m.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logrus.Warnf("发送会话告警 webhook 失败: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		logrus.Warnf("发送会话告警 webhook 失败: HTTP %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
+func TestSessionWarning(t *testing.T) {
+	now := time.Now()
+	threshold := 72 * time.Hour
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+
+	tests := []struct {
+		name   string
+		health SessionHealth
+		want   SessionWarningKind
+	}{
+		{"登录已失效", SessionHealth{State: xiaohongshu.LoginStateExpired}, SessionWarningInvalid},
+		{"未登录", SessionHealth{State: xiaohongshu.LoginStateLoggedOut}, SessionWarningInvalid},
+		{"被风控", SessionHealth{State: xiaohongshu.LoginStateRiskControlled, Reason: "验证码"}, SessionWarningInvalid},
+		{"失效优先于 cookie 过期时间", SessionHealth{State: xiaohongshu.LoginStateExpired, CookieExpiresAt: at(30 * 24 * time.Hour)}, SessionWarningInvalid},
+		{"cookie 即将过期", SessionHealth{State: xiaohongshu.LoginStateLoggedIn, CookieExpiresAt: at(time.Hour)}, SessionWarningExpiring},
+		{"cookie 已经过期", SessionHealth{State: xiaohongshu.LoginStateLoggedIn, CookieExpiresAt: at(-time.Hour)}, SessionWarningExpiring},
+		{"cookie 远未过期", SessionHealth{State: xiaohongshu.LoginStateLoggedIn, CookieExpiresAt: at(30 * 24 * time.Hour)}, ""},
+		{"会话 cookie 没有过期时间", SessionHealth{State: xiaohongshu.LoginStateLoggedIn}, ""},
+		{"还没有检查过", SessionHealth{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, message := sessionWarning(tt.health, now, threshold)
+			assert.Equal(t, tt.want, kind)
+			if tt.want == "" {
+				assert.Empty(t, message)
+			} else {
+				assert.NotEmpty(t, message)
+			}
+		})
+	}
+}
+
+// newSessionWarningRecorder 返回记录会话告警通知的服务
+func newSessionWarningRecorder() (*XiaohongshuService, *[]SessionWarningKind) {
+	s := &XiaohongshuService{monitor: newSessionMonitor()}
+	var kinds []SessionWarningKind
+	s.SetEventHandler(func(method string, params any) {
+		if method == notificationSessionWarning {
+			kinds = append(kinds, params.(*SessionWarningEvent).Kind)
+		}
+	})
+	return s, &kinds
+}
+
+func TestEvaluateSessionEmitsOnChange(t *testing.T) {
+	s, kinds := newSessionWarningRecorder()
+
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedIn, "")
+	assert.Empty(t, *kinds, "会话正常时不告警")
+
+	s.recordLoginStatus("a", xiaohongshu.LoginStateExpired, "")
+	s.recordLoginStatus("a", xiaohongshu.LoginStateExpired, "")
+	// 失效的原因变化但告警类型不变时不重复告警
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedOut, "")
+	assert.Equal(t, []SessionWarningKind{SessionWarningInvalid}, *kinds)
+
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedIn, "")
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedIn, "")
+	assert.Equal(t, []SessionWarningKind{SessionWarningInvalid, SessionWarningRecovered}, *kinds)
+	assert.Empty(t, s.SessionHealth()["a"].Warning)
+}
+
+func TestEvaluateSessionExpiring(t *testing.T) {
+	s, kinds := newSessionWarningRecorder()
+
+	expiresAt := time.Now().Add(time.Hour)
+	s.monitor.update("a", func(h *sessionHealth) {
+		h.CookieExpiresAt = &expiresAt
+	})
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedIn, "")
+	s.recordLoginStatus("a", xiaohongshu.LoginStateLoggedIn, "")
+	assert.Equal(t, []SessionWarningKind{SessionWarningExpiring}, *kinds)
+
+	// 重新登录后 cookie 变成会话 cookie，没有过期时间
+	s.monitor.update("a", func(h *sessionHealth) {
+		h.CookieExpiresAt = nil
+	})
+	s.evaluateSession("a")
+	s.evaluateSession("a")
+	assert.Equal(t, []SessionWarningKind{SessionWarningExpiring, SessionWarningRecovered}, *kinds)
+}
+
+func TestEvaluateSessionAccountsIndependent(t *testing.T) {
+	s, kinds := newSessionWarningRecorder()
+
+	s.recordLoginStatus("a", xiaohongshu.LoginStateExpired, "")
+	s.recordLoginStatus("b", xiaohongshu.LoginStateExpired, "")
+	assert.Equal(t, []SessionWarningKind{SessionWarningInvalid, SessionWarningInvalid}, *kinds)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stdioMaxMessageSize stdio 模式下单条 JSON-RPC 消息的最大长度
+const stdioMaxMessageSize = 16 * 1024 * 1024
+
+// stdioWriter 串行写出 JSON-RPC 消息，每条消息占一行
+type stdioWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Send 写出一条 JSON-RPC 消息
+func (w *stdioWriter) Send(message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal stdio message")
+		return
+	}
+
+	w.write(data)
+}
+
+// write 写出已序列化的消息并换行
+func (w *stdioWriter) write(data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.out.Write(append(data, '\n')); err != nil {
+		logrus.WithError(err).Error("Failed to write stdio message")
+	}
+}
+
+// ServeStdio 以 stdio 方式提供 MCP 服务
+// 从 in 逐行读取 JSON-RPC 消息，响应和通知写入 out，日志需要输出到 stderr
+func (s *AppServer) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := &stdioWriter{out: out}
+
+	// stdio 连接只有一个客户端，对应一个隐式会话
+	session := s.mcpSessions.Create()
+	defer s.mcpSessions.Delete(session.ID)
+	defer s.stopResourceWatcher()
+
+	ctx = withMCPSession(ctx, session)
+	ctx = withProgressSink(ctx, writer.Send)
+
+	// 服务端通知直接写到 stdout
+	_, events, unsubscribe := session.Events().Subscribe(0)
+	defer unsubscribe()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				writer.write(event.Data)
+			}
+		}
+	}()
+
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), stdioMaxMessageSize)
+
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			// scanner 会复用缓冲区，交给其他 goroutine 前需要复制
+			select {
+			case lines <- append([]byte(nil), line...):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		scanErr <- scanner.Err()
+	}()
+
+	logrus.Info("MCP stdio 服务已启动")
+
+	// 每条消息独立处理，长耗时的工具调用执行期间仍可以接收 notifications/cancelled
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				logrus.Info("stdin 已关闭，等待正在执行的请求完成")
+				wg.Wait()
+				return <-scanErr
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleStdioMessage(ctx, writer, line)
+			}()
+		}
+	}
+}
+
+// handleStdioMessage 处理 stdin 中的一行消息
+func (s *AppServer) handleStdioMessage(ctx context.Context, writer *stdioWriter, line []byte) {
+	messages, isBatch, err := parseJSONRPCMessages(line)
+	if err != nil {
+		writer.Send(&JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: -32700, Message: "Parse error"},
+		})
+		return
+	}
+	if len(messages) == 0 {
+		writer.Send(&JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error:   &JSONRPCError{Code: -32600, Message: "Invalid Request"},
+		})
+		return
+	}
+
+	var responses []*JSONRPCResponse
+	s.processJSONRPCMessages(ctx, messages, func(response *JSONRPCResponse) {
+		responses = append(responses, response)
+	})
+
+	switch {
+	case len(responses) == 0:
+		// 只包含通知，不需要回复
+	case isBatch:
+		writer.Send(responses)
+	default:
+		writer.Send(responses[0])
+	}
+}
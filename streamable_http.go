@@ -7,17 +7,23 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// sseKeepAliveInterval SSE 连接保活间隔
+const sseKeepAliveInterval = 30 * time.Second
+
 // StreamableHTTPHandler 处理 Streamable HTTP 协议的 MCP 请求
 func (s *AppServer) StreamableHTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 设置 CORS 头
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Mcp-Session-Id")
+		w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
 
 		// 处理 OPTIONS 请求
 		if r.Method == "OPTIONS" {
@@ -33,12 +39,51 @@ func (s *AppServer) StreamableHTTPHandler() http.HandlerFunc {
 		case "POST":
 			// POST 请求处理 JSON-RPC
 			s.handleJSONRPCRequest(w, r)
+		case "DELETE":
+			// DELETE 请求用于客户端主动结束会话
+			s.handleSessionDelete(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	}
 }
 
+// resolveSession 根据 Mcp-Session-Id 头查找会话
+// 缺少会话 ID 返回 400，会话不存在或已过期返回 404，客户端需要重新 initialize
+func (s *AppServer) resolveSession(w http.ResponseWriter, r *http.Request) (*MCPSession, bool) {
+	sessionID := r.Header.Get(mcpSessionHeader)
+	if sessionID == "" {
+		s.sendStreamableHTTPError(w, http.StatusBadRequest, nil, -32600, "Missing Mcp-Session-Id header")
+		return nil, false
+	}
+
+	session, ok := s.mcpSessions.Get(sessionID)
+	if !ok {
+		s.sendStreamableHTTPError(w, http.StatusNotFound, nil, -32001, "Session not found")
+		return nil, false
+	}
+
+	return session, true
+}
+
+// handleSessionDelete 处理会话结束请求
+func (s *AppServer) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(mcpSessionHeader)
+	if sessionID == "" {
+		s.sendStreamableHTTPError(w, http.StatusBadRequest, nil, -32600, "Missing Mcp-Session-Id header")
+		return
+	}
+
+	if !s.mcpSessions.Delete(sessionID) {
+		s.sendStreamableHTTPError(w, http.StatusNotFound, nil, -32001, "Session not found")
+		return
+	}
+	s.pruneNoteEngagement()
+
+	logrus.WithField("session", sessionID).Info("MCP 会话已结束")
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleSSEConnection 处理 SSE 连接（可选，用于服务器推送）
 func (s *AppServer) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 	// 检查是否支持 SSE
@@ -47,6 +92,25 @@ func (s *AppServer) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	session, ok := s.resolveSession(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// 订阅会话事件，携带 Last-Event-ID 的重连请求会先补发断线期间的事件
+	lastEventID := parseLastEventID(r.Header.Get("Last-Event-ID"))
+	replay, events, unsubscribe := session.Events().Subscribe(lastEventID)
+	defer unsubscribe()
+
+	// 连接打开期间会话不会因空闲被回收
+	defer session.openStream()()
+
 	// 设置 SSE 响应头
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -56,15 +120,47 @@ func (s *AppServer) handleSSEConnection(w http.ResponseWriter, r *http.Request)
 	fmt.Fprintf(w, "event: open\n")
 	fmt.Fprintf(w, "data: {\"type\":\"connection\",\"status\":\"connected\"}\n\n")
 
-	if f, ok := w.(http.Flusher); ok {
-		f.Flush()
+	for _, event := range replay {
+		writeSSEEvent(w, event)
 	}
+	flusher.Flush()
 
-	// 保持连接打开（实际使用中可以在这里推送通知）
-	<-r.Context().Done()
+	logrus.WithFields(logrus.Fields{
+		"session":     session.ID,
+		"lastEventID": lastEventID,
+		"replayed":    len(replay),
+	}).Info("MCP SSE 连接已建立")
+
+	// 定期发送注释行保活，避免中间代理断开空闲连接
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// 会话已结束
+				return
+			}
+			writeSSEEvent(w, event)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprintf(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSEEvent 按 SSE 格式写出带 ID 的事件
+func writeSSEEvent(w io.Writer, event MCPEvent) {
+	fmt.Fprintf(w, "id: %s\n", event.EventID())
+	fmt.Fprintf(w, "event: message\n")
+	fmt.Fprintf(w, "data: %s\n\n", event.Data)
 }
 
-// handleJSONRPCRequest 处理 JSON-RPC 请求
+// handleJSONRPCRequest 处理 JSON-RPC 请求，支持单条消息和批量数组
 func (s *AppServer) handleJSONRPCRequest(w http.ResponseWriter, r *http.Request) {
 	// 读取请求体
 	body, err := io.ReadAll(r.Body)
@@ -74,27 +170,71 @@ func (s *AppServer) handleJSONRPCRequest(w http.ResponseWriter, r *http.Request)
 	}
 	defer r.Body.Close()
 
-	// 解析 JSON-RPC 请求
-	var request JSONRPCRequest
-	if err := json.Unmarshal(body, &request); err != nil {
+	// 解析 JSON-RPC 消息
+	messages, isBatch, err := parseJSONRPCMessages(body)
+	if err != nil {
 		s.sendStreamableError(w, nil, -32700, "Parse error")
 		return
 	}
+	if len(messages) == 0 {
+		s.sendStreamableError(w, nil, -32600, "Invalid Request")
+		return
+	}
 
-	logrus.WithField("method", request.Method).Info("Received Streamable HTTP request")
+	for _, message := range messages {
+		if message.request != nil {
+			logrus.WithField("method", message.request.Method).Info("Received Streamable HTTP request")
+		}
+	}
+
+	// initialize 创建新会话，且不允许出现在批量请求中；其余请求必须携带已有的会话 ID
+	var session *MCPSession
+	if !isBatch && messages[0].request.Method == "initialize" {
+		session = s.mcpSessions.Create()
+		w.Header().Set(mcpSessionHeader, session.ID)
+	} else {
+		for _, message := range messages {
+			if message.request != nil && message.request.Method == "initialize" {
+				message.invalid = &JSONRPCError{Code: -32600, Message: "initialize must not be part of a batch"}
+			}
+		}
+
+		var ok bool
+		if session, ok = s.resolveSession(w, r); !ok {
+			return
+		}
+	}
 
 	// 检查 Accept 头，判断客户端是否支持 SSE
 	acceptSSE := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
 
-	// 处理请求
-	response := s.processJSONRPCRequest(&request, r.Context())
+	ctx := withMCPSession(r.Context(), session)
 
-	// 如果需要 SSE 且是支持流式的方法，使用 SSE 响应
-	if acceptSSE && s.isStreamableMethod(request.Method) {
-		s.sendSSEResponse(w, response)
-	} else {
-		// 否则使用普通 JSON 响应
-		s.sendJSONResponse(w, response)
+	// 如果需要 SSE 且包含支持流式的方法，使用 SSE 响应：先推送进度通知，再逐条写出响应
+	if acceptSSE && s.hasStreamableRequest(messages) {
+		stream := newSSEStream(w)
+		ctx = withProgressSink(ctx, stream.Send)
+
+		s.processJSONRPCMessages(ctx, messages, func(response *JSONRPCResponse) {
+			stream.Send(response)
+		})
+		return
+	}
+
+	// 否则使用普通 JSON 响应
+	var responses []*JSONRPCResponse
+	s.processJSONRPCMessages(ctx, messages, func(response *JSONRPCResponse) {
+		responses = append(responses, response)
+	})
+
+	switch {
+	case len(responses) == 0:
+		// 只包含通知或响应时，按规范返回 202 且没有响应体
+		w.WriteHeader(http.StatusAccepted)
+	case isBatch:
+		s.sendJSONResponse(w, responses)
+	default:
+		s.sendJSONResponse(w, responses[0])
 	}
 }
 
@@ -102,9 +242,12 @@ func (s *AppServer) handleJSONRPCRequest(w http.ResponseWriter, r *http.Request)
 func (s *AppServer) processJSONRPCRequest(request *JSONRPCRequest, ctx context.Context) *JSONRPCResponse {
 	switch request.Method {
 	case "initialize":
-		return s.processInitialize(request)
-	case "initialized":
+		return s.processInitialize(ctx, request)
+	case "initialized", "notifications/initialized":
 		// 客户端确认初始化完成
+		if session := mcpSessionFromContext(ctx); session != nil {
+			session.MarkInitialized()
+		}
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Result:  map[string]interface{}{},
@@ -120,7 +263,44 @@ func (s *AppServer) processJSONRPCRequest(request *JSONRPCRequest, ctx context.C
 	case "tools/list":
 		return s.processToolsList(request)
 	case "tools/call":
+		// 登记正在执行的请求，客户端可以通过 notifications/cancelled 中止
+		if session := mcpSessionFromContext(ctx); session != nil {
+			var done func()
+			ctx, done = session.Requests().Register(ctx, request.ID)
+			defer done()
+		}
 		return s.processToolCall(ctx, request)
+	case "resources/list":
+		return s.processResourcesList(request)
+	case "resources/templates/list":
+		return s.processResourceTemplatesList(request)
+	case "resources/read":
+		if session := mcpSessionFromContext(ctx); session != nil {
+			var done func()
+			ctx, done = session.Requests().Register(ctx, request.ID)
+			defer done()
+		}
+		return s.processResourcesRead(ctx, request)
+	case "prompts/list":
+		return s.processPromptsList(request)
+	case "prompts/get":
+		if session := mcpSessionFromContext(ctx); session != nil {
+			var done func()
+			ctx, done = session.Requests().Register(ctx, request.ID)
+			defer done()
+		}
+		return s.processPromptsGet(ctx, request)
+	case "resources/subscribe":
+		return s.processResourcesSubscribe(ctx, request)
+	case "resources/unsubscribe":
+		return s.processResourcesUnsubscribe(ctx, request)
+	case notificationCancelled:
+		s.processCancelled(ctx, request)
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Result:  map[string]interface{}{},
+			ID:      request.ID,
+		}
 	default:
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -133,12 +313,37 @@ func (s *AppServer) processJSONRPCRequest(request *JSONRPCRequest, ctx context.C
 	}
 }
 
-// processInitialize 处理初始化请求
-func (s *AppServer) processInitialize(request *JSONRPCRequest) *JSONRPCResponse {
+// processInitialize 处理初始化请求，协商协议版本并记录客户端信息
+func (s *AppServer) processInitialize(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	params, _ := request.Params.(map[string]interface{})
+	requestedVersion, _ := params["protocolVersion"].(string)
+	protocolVersion := negotiateProtocolVersion(requestedVersion)
+
+	if session := mcpSessionFromContext(ctx); session != nil {
+		clientInfo, _ := params["clientInfo"].(map[string]interface{})
+		capabilities, _ := params["capabilities"].(map[string]interface{})
+		session.setClientInfo(protocolVersion, clientInfo, capabilities)
+
+		logrus.WithFields(logrus.Fields{
+			"session":         session.ID,
+			"protocolVersion": protocolVersion,
+			"clientInfo":      clientInfo,
+		}).Info("MCP 会话初始化")
+	}
+
 	result := map[string]interface{}{
-		"protocolVersion": "2025-03-26", // 使用新的协议版本
+		"protocolVersion": protocolVersion,
 		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
+			"tools": map[string]interface{}{
+				"listChanged": false,
+			},
+			"resources": map[string]interface{}{
+				"subscribe":   true,
+				"listChanged": false,
+			},
+			"prompts": map[string]interface{}{
+				"listChanged": false,
+			},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    "xiaohongshu-mcp",
@@ -155,186 +360,10 @@ func (s *AppServer) processInitialize(request *JSONRPCRequest) *JSONRPCResponse
 
 // processToolsList 处理工具列表请求
 func (s *AppServer) processToolsList(request *JSONRPCRequest) *JSONRPCResponse {
-	tools := []map[string]interface{}{
-		{
-			"name":        "check_login_status",
-			"description": "检查小红书登录状态",
-			"inputSchema": map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
-		},
-		{
-			"name":        "publish_content",
-			"description": "发布小红书图文内容",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"title": map[string]interface{}{
-						"type":        "string",
-						"description": "内容标题（小红书限制：最多20个中文字或英文单词）",
-					},
-					"content": map[string]interface{}{
-						"type":        "string",
-						"description": "正文内容，支持话题标签",
-					},
-					"images": map[string]interface{}{
-						"type":        "array",
-						"description": "图片路径列表，支持绝对本地路径或URL（至少需要1张图片）",
-						"items": map[string]interface{}{
-							"type": "string",
-						},
-						"minItems": 1,
-					},
-					"publish_time": map[string]interface{}{
-						"type":        "string",
-						"description": "可选的定时发布时间，格式为 '2025-09-12 14:22'（北京时间），不提供则立即发布",
-					},
-				},
-				"required": []string{"title", "content", "images"},
-			},
-		},
-		{
-			"name":        "list_feeds",
-			"description": "获取用户发布的内容列表",
-			"inputSchema": map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
-			},
-		},
-		{
-			"name":        "search_feeds",
-			"description": "搜索小红书内容（需要已登录）",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"keyword": map[string]interface{}{
-						"type":        "string",
-						"description": "搜索关键词",
-					},
-				},
-				"required": []string{"keyword"},
-			},
-		},
-		{
-			"name":        "get_feed_detail",
-			"description": "获取小红书笔记详情，返回笔记内容、图片、作者信息、互动数据（点赞/收藏/分享数）及评论列表",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"feed_id": map[string]interface{}{
-						"type":        "string",
-						"description": "小红书笔记ID，从Feed列表获取",
-					},
-					"xsec_token": map[string]interface{}{
-						"type":        "string",
-						"description": "访问令牌，从Feed列表的xsecToken字段获取",
-					},
-				},
-				"required": []string{"feed_id", "xsec_token"},
-			},
-		},
-		{
-			"name":        "post_comment_to_feed",
-			"description": "发表评论到小红书笔记",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"feed_id": map[string]interface{}{
-						"type":        "string",
-						"description": "小红书笔记ID，从Feed列表获取",
-					},
-					"xsec_token": map[string]interface{}{
-						"type":        "string",
-						"description": "访问令牌，从Feed列表的xsecToken字段获取",
-					},
-					"content": map[string]interface{}{
-						"type":        "string",
-						"description": "评论内容",
-					},
-				},
-				"required": []string{"feed_id", "xsec_token", "content"},
-			},
-		},
-		{
-			"name":        "like_feed",
-			"description": "点赞或取消点赞小红书笔记",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"feed_id": map[string]interface{}{
-						"type":        "string",
-						"description": "小红书笔记ID，从Feed列表获取",
-					},
-					"xsec_token": map[string]interface{}{
-						"type":        "string",
-						"description": "访问令牌，从Feed列表的xsecToken字段获取",
-					},
-				},
-				"required": []string{"feed_id", "xsec_token"},
-			},
-		},
-		{
-			"name":        "collect_feed",
-			"description": "收藏或取消收藏小红书笔记",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"feed_id": map[string]interface{}{
-						"type":        "string",
-						"description": "小红书笔记ID，从Feed列表获取",
-					},
-					"xsec_token": map[string]interface{}{
-						"type":        "string",
-						"description": "访问令牌，从Feed列表的xsecToken字段获取",
-					},
-				},
-				"required": []string{"feed_id", "xsec_token"},
-			},
-		},
-		{
-			"name":        "publish_article",
-			"description": "发布小红书长文章内容",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"title": map[string]interface{}{
-						"type":        "string",
-						"description": "文章标题（最多64字符）",
-					},
-					"content": map[string]interface{}{
-						"type":        "string",
-						"description": "正文内容",
-					},
-					"tags": map[string]interface{}{
-						"type":        "array",
-						"description": "标签列表，与内容分开（可选）",
-						"items": map[string]interface{}{
-							"type": "string",
-						},
-					},
-					"images": map[string]interface{}{
-						"type":        "array",
-						"description": "图片路径列表，必须使用绝对路径或URL（至少需要1张图片）",
-						"items": map[string]interface{}{
-							"type": "string",
-						},
-						"minItems": 1,
-					},
-					"publish_time": map[string]interface{}{
-						"type":        "string",
-						"description": "可选的定时发布时间，格式为 '2025-09-12 14:22'（北京时间），不提供则立即发布",
-					},
-				},
-				"required": []string{"title", "content", "images"},
-			},
-		},
-	}
-
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		Result: map[string]interface{}{
-			"tools": tools,
+			"tools": s.tools.List(),
 		},
 		ID: request.ID,
 	}
@@ -358,28 +387,11 @@ func (s *AppServer) processToolCall(ctx context.Context, request *JSONRPCRequest
 	toolName, _ := params["name"].(string)
 	toolArgs, _ := params["arguments"].(map[string]interface{})
 
-	var result *MCPToolResult
-
-	switch toolName {
-	case "check_login_status":
-		result = s.handleCheckLoginStatus(ctx)
-	case "publish_content":
-		result = s.handlePublishContent(ctx, toolArgs)
-	case "publish_article":
-		result = s.handlePublishArticle(ctx, toolArgs)
-	case "list_feeds":
-		result = s.handleListFeeds(ctx)
-	case "search_feeds":
-		result = s.handleSearchFeeds(ctx, toolArgs)
-	case "get_feed_detail":
-		result = s.handleGetFeedDetail(ctx, toolArgs)
-	case "post_comment_to_feed":
-		result = s.handlePostComment(ctx, toolArgs)
-	case "like_feed":
-		result = s.handleLikeFeed(ctx, toolArgs)
-	case "collect_feed":
-		result = s.handleCollectFeed(ctx, toolArgs)
-	default:
+	// 携带 progressToken 时，发布等长耗时工具会推送步骤进度
+	ctx = s.withToolProgress(ctx, params)
+
+	tool, ok := s.tools.Get(toolName)
+	if !ok {
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &JSONRPCError{
@@ -390,6 +402,8 @@ func (s *AppServer) processToolCall(ctx context.Context, request *JSONRPCRequest
 		}
 	}
 
+	result := tool.call(ctx, toolArgs)
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		Result:  result,
@@ -398,14 +412,18 @@ func (s *AppServer) processToolCall(ctx context.Context, request *JSONRPCRequest
 }
 
 // isStreamableMethod 判断方法是否支持流式响应
-func (s *AppServer) isStreamableMethod(_ string) bool {
-	// 目前我们的方法都不需要流式响应
-	// 未来可以在这里添加支持流式的方法
-	return false
+// 目前只有携带 progressToken 的 tools/call 需要在最终结果前推送进度通知
+func (s *AppServer) isStreamableMethod(request *JSONRPCRequest) bool {
+	if request.Method != "tools/call" {
+		return false
+	}
+
+	params, _ := request.Params.(map[string]interface{})
+	return progressTokenFromParams(params) != nil
 }
 
-// sendJSONResponse 发送普通 JSON 响应
-func (s *AppServer) sendJSONResponse(w http.ResponseWriter, response *JSONRPCResponse) {
+// sendJSONResponse 发送普通 JSON 响应，批量请求时 response 为响应数组
+func (s *AppServer) sendJSONResponse(w http.ResponseWriter, response any) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -413,23 +431,41 @@ func (s *AppServer) sendJSONResponse(w http.ResponseWriter, response *JSONRPCRes
 	}
 }
 
-// sendSSEResponse 发送 SSE 响应
-func (s *AppServer) sendSSEResponse(w http.ResponseWriter, response *JSONRPCResponse) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+// sseStream POST 请求的 SSE 响应流，可以在最终响应之前写出多条通知
+type sseStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	started bool
+}
 
-	// 将响应转换为 JSON
-	data, err := json.Marshal(response)
+// newSSEStream 创建 SSE 响应流
+func newSSEStream(w http.ResponseWriter) *sseStream {
+	return &sseStream{w: w}
+}
+
+// Send 以 SSE 事件写出一条 JSON-RPC 消息
+func (st *sseStream) Send(message any) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.started {
+		st.w.Header().Set("Content-Type", "text/event-stream")
+		st.w.Header().Set("Cache-Control", "no-cache")
+		st.w.Header().Set("Connection", "keep-alive")
+		st.started = true
+	}
+
+	// 将消息转换为 JSON
+	data, err := json.Marshal(message)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal SSE response")
+		logrus.WithError(err).Error("Failed to marshal SSE message")
 		return
 	}
 
-	// 发送 SSE 格式的响应
-	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	// 发送 SSE 格式的消息
+	fmt.Fprintf(st.w, "data: %s\n\n", string(data))
 
-	if f, ok := w.(http.Flusher); ok {
+	if f, ok := st.w.(http.Flusher); ok {
 		f.Flush()
 	}
 }
@@ -446,3 +482,22 @@ func (s *AppServer) sendStreamableError(w http.ResponseWriter, id interface{}, c
 	}
 	s.sendJSONResponse(w, response)
 }
+
+// sendStreamableHTTPError 发送带 HTTP 状态码的错误响应
+func (s *AppServer) sendStreamableHTTPError(w http.ResponseWriter, statusCode int, id interface{}, code int, message string) {
+	response := &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error: &JSONRPCError{
+			Code:    code,
+			Message: message,
+		},
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingToolRequest 测试用阻塞工具的参数
+type blockingToolRequest struct{}
+
+// blockingToolResponse 测试用阻塞工具的结果
+type blockingToolResponse struct{}
+
+// newTestAppServer 创建不启动浏览器的 MCP 服务，并注册一个阻塞到 context 结束的测试工具
+// 工具开始执行时向 started 发送，结束时把 context 的错误发送到 finished
+func newTestAppServer(t *testing.T) (*AppServer, *httptest.Server, chan struct{}, chan error) {
+	t.Helper()
+
+	s := NewAppServer(&XiaohongshuService{})
+	t.Cleanup(s.mcpSessions.Close)
+
+	started := make(chan struct{}, 1)
+	finished := make(chan error, 1)
+	addTool(s.tools, "test_block", "阻塞到请求被取消", func(ctx context.Context, _ *blockingToolRequest) (*blockingToolResponse, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		finished <- ctx.Err()
+		return nil, ctx.Err()
+	})
+
+	srv := httptest.NewServer(s.StreamableHTTPHandler())
+	t.Cleanup(srv.Close)
+	return s, srv, started, finished
+}
+
+// postMCP 以 JSON 发送 JSON-RPC 消息，sessionID 为空时不带会话头
+func postMCP(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(mcpSessionHeader, sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initializeSession 完成 initialize 握手并返回会话 ID
+func initializeSession(t *testing.T, url string) string {
+	t.Helper()
+
+	resp := postMCP(t, url, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out JSONRPCResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Nil(t, out.Error)
+	assert.Equal(t, "2025-03-26", out.Result.(map[string]any)["protocolVersion"])
+
+	sessionID := resp.Header.Get(mcpSessionHeader)
+	require.NotEmpty(t, sessionID)
+	return sessionID
+}
+
+func TestStreamableHTTPRequiresSession(t *testing.T) {
+	s, srv, _, _ := newTestAppServer(t)
+
+	resp := postMCP(t, srv.URL, "", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postMCP(t, srv.URL, "unknown", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	sessionID := initializeSession(t, srv.URL)
+	session, ok := s.mcpSessions.Get(sessionID)
+	require.True(t, ok)
+	session.mu.Lock()
+	assert.Equal(t, "2025-03-26", session.ProtocolVersion)
+	assert.Equal(t, "test", session.ClientInfo["name"])
+	session.mu.Unlock()
+
+	resp = postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var out JSONRPCResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Nil(t, out.Error)
+	assert.Equal(t, float64(2), out.ID)
+}
+
+func TestStreamableHTTPNotificationAccepted(t *testing.T) {
+	_, srv, _, _ := newTestAppServer(t)
+	sessionID := initializeSession(t, srv.URL)
+
+	resp := postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+
+	// 只包含通知的批量请求同样返回 202
+	resp = postMCP(t, srv.URL, sessionID, `[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestStreamableHTTPBatchMixesNotificationsAndRequests(t *testing.T) {
+	_, srv, _, _ := newTestAppServer(t)
+	sessionID := initializeSession(t, srv.URL)
+
+	resp := postMCP(t, srv.URL, sessionID, `[
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":"a","method":"ping"},
+		{"jsonrpc":"2.0","id":"b","method":"no/such/method"},
+		{"jsonrpc":"2.0","id":"c","method":"initialize"}
+	]`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out []JSONRPCResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out, 3)
+
+	assert.Equal(t, "a", out[0].ID)
+	assert.Nil(t, out[0].Error)
+	assert.Equal(t, "b", out[1].ID)
+	require.NotNil(t, out[1].Error)
+	assert.Equal(t, -32601, out[1].Error.Code)
+	assert.Equal(t, "c", out[2].ID)
+	require.NotNil(t, out[2].Error)
+	assert.Equal(t, -32600, out[2].Error.Code)
+}
+
+func TestStreamableHTTPCancelledReachesRequestContext(t *testing.T) {
+	_, srv, started, finished := newTestAppServer(t)
+	sessionID := initializeSession(t, srv.URL)
+
+	result := make(chan JSONRPCResponse, 1)
+	go func() {
+		resp := postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"test_block","arguments":{}}}`)
+		var out JSONRPCResponse
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		result <- out
+	}()
+
+	waitFor(t, started, "工具没有开始执行")
+
+	resp := postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"test"}}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	assert.ErrorIs(t, waitFor(t, finished, "取消没有传递到请求的 context"), context.Canceled)
+	out := waitFor(t, result, "工具调用没有返回")
+	assert.Equal(t, float64(7), out.ID)
+}
+
+func TestStreamableHTTPDeleteEndsSession(t *testing.T) {
+	s, srv, started, finished := newTestAppServer(t)
+	sessionID := initializeSession(t, srv.URL)
+
+	// 打开 GET SSE 连接，读到连接建立事件
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(mcpSessionHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	reader := bufio.NewReader(stream.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: open\n", line)
+
+	session, ok := s.mcpSessions.Get(sessionID)
+	require.True(t, ok)
+	assert.Zero(t, session.idleSince(time.Now().Add(time.Hour)), "打开 SSE 连接的会话不算空闲")
+
+	go postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"test_block","arguments":{}}}`)
+	waitFor(t, started, "工具没有开始执行")
+
+	del, err := http.NewRequest(http.MethodDelete, srv.URL, nil)
+	require.NoError(t, err)
+	del.Header.Set(mcpSessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(del)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.ErrorIs(t, waitFor(t, finished, "会话结束后请求没有被取消"), context.Canceled)
+
+	// SSE 连接随会话结束而关闭
+	closed := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, reader)
+		closed <- err
+	}()
+	assert.NoError(t, waitFor(t, closed, "会话结束后 SSE 连接没有关闭"))
+
+	resp = postMCP(t, srv.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.DefaultClient.Do(del)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMCPSessionReapIdle(t *testing.T) {
+	m := NewMCPSessionManager()
+	defer m.Close()
+
+	idle := m.Create()
+	streaming := m.Create()
+	closeStream := streaming.openStream()
+	defer closeStream()
+
+	m.reapIdle(time.Now().Add(mcpSessionIdleTimeout + time.Minute))
+
+	_, ok := m.Get(idle.ID)
+	assert.False(t, ok)
+	_, ok = m.Get(streaming.ID)
+	assert.True(t, ok)
+}
+
+func TestServeStdio(t *testing.T) {
+	s, _, started, finished := newTestAppServer(t)
+
+	in, inWriter := io.Pipe()
+	outReader, out := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeStdio(context.Background(), in, out)
+		out.Close()
+	}()
+
+	lines := bufio.NewScanner(outReader)
+	send := func(msg string) {
+		_, err := io.WriteString(inWriter, msg+"\n")
+		require.NoError(t, err)
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	require.True(t, lines.Scan())
+	assert.Contains(t, lines.Text(), `"protocolVersion"`)
+
+	// 批量中的通知不产生响应
+	send(`[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"method":"ping"}]`)
+	require.True(t, lines.Scan())
+	var batch []JSONRPCResponse
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &batch))
+	require.Len(t, batch, 1)
+	assert.Equal(t, float64(2), batch[0].ID)
+
+	send(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"test_block","arguments":{}}}`)
+	waitFor(t, started, "工具没有开始执行")
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":3}}`)
+	assert.ErrorIs(t, waitFor(t, finished, "取消没有传递到请求的 context"), context.Canceled)
+	require.True(t, lines.Scan())
+	assert.Contains(t, lines.Text(), `"id":3`)
+
+	inWriter.Close()
+	assert.NoError(t, waitFor(t, done, "stdin 关闭后服务没有退出"))
+}
+
+// waitFor 等待 channel 中的值，超时时测试失败
+func waitFor[T any](t *testing.T, ch <-chan T, message string) T {
+	t.Helper()
+
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal(message)
+		var zero T
+		return zero
+	}
+}
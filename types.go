@@ -1,12 +1,19 @@
 package main
 
+import (
+	"encoding/base64"
+
+	"github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
+)
+
 // HTTP API 响应类型
 
 // ErrorResponse 错误响应
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code"`
-	Details any    `json:"details,omitempty"`
+	Error    string       `json:"error"`
+	Code     string       `json:"code"`
+	Details  any          `json:"details,omitempty"`
+	Artifact *ArtifactRef `json:"artifact,omitempty"`
 }
 
 // SuccessResponse 成功响应
@@ -34,6 +41,13 @@ type JSONRPCResponse struct {
 	ID      any           `json:"id"`
 }
 
+// JSONRPCNotification JSON-RPC 通知，没有 ID，客户端无需响应
+type JSONRPCNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // JSONRPCError JSON-RPC 错误
 type JSONRPCError struct {
 	Code    int    `json:"code"`
@@ -43,6 +57,33 @@ type JSONRPCError struct {
 
 // MCP 相关类型
 
+// MCPResource MCP 资源描述
+type MCPResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// MCPResourceTemplate MCP 资源 URI 模板（RFC 6570）
+type MCPResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPResourceContents MCP 资源内容，文本资源使用 Text，二进制资源使用 base64 编码的 Blob
+type MCPResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
 // MCPToolCall MCP 工具调用
 type MCPToolCall struct {
 	Name      string                 `json:"name"`
@@ -51,33 +92,91 @@ type MCPToolCall struct {
 
 // MCPToolResult MCP 工具结果
 type MCPToolResult struct {
-	Content []MCPContent `json:"content"`
-	IsError bool         `json:"isError,omitempty"`
+	Content           []MCPContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+	Meta              any          `json:"_meta,omitempty"`
 }
 
-// MCPContent MCP 内容
+// MCPContent MCP 内容，Type 为 text 时使用 Text，为 resource 时使用 Resource 嵌入资源，
+// 为 image 时使用 Data（base64）和 MIMEType
 type MCPContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string               `json:"type"`
+	Text     string               `json:"text,omitempty"`
+	Resource *MCPResourceContents `json:"resource,omitempty"`
+	Data     string               `json:"data,omitempty"`
+	MIMEType string               `json:"mimeType,omitempty"`
+}
+
+// MCPPromptResult prompts/get 的结果
+type MCPPromptResult struct {
+	Description string             `json:"description,omitempty"`
+	Messages    []MCPPromptMessage `json:"messages"`
+}
+
+// MCPPromptMessage MCP 提示词消息
+type MCPPromptMessage struct {
+	Role    string     `json:"role"` // user 或 assistant
+	Content MCPContent `json:"content"`
+}
+
+// EmptyRequest 无参数请求
+type EmptyRequest struct{}
+
+// AccountRequest 只有账号参数的请求
+type AccountRequest struct {
+	Account string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
+}
+
+// SwitchAccountRequest 切换默认账号请求
+type SwitchAccountRequest struct {
+	Account string `json:"account" binding:"required,account" description:"账号名，从 list_accounts 获取"`
+}
+
+// ListFeedsRequest 获取Feeds列表请求
+type ListFeedsRequest struct {
+	Limit   int    `json:"limit,omitempty" form:"limit" binding:"omitempty,min=1,max=200" description:"需要的笔记数量，超过首屏数量时滚动加载更多，不填只返回首屏"`
+	Account string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
+}
+
+// SearchFeedsRequest 搜索请求
+type SearchFeedsRequest struct {
+	Keyword string `json:"keyword" form:"keyword" binding:"required" description:"搜索关键词"`
+	Limit   int    `json:"limit,omitempty" form:"limit" binding:"omitempty,min=1,max=200" description:"需要的笔记数量，超过首屏数量时滚动加载更多，不填只返回首屏"`
+	Account string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // FeedDetailRequest Feed详情请求
 type FeedDetailRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
+	FeedID    string `json:"feed_id" binding:"required" description:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" description:"访问令牌，从Feed列表的xsecToken字段获取"`
+	// MaxComments 大于首屏评论数时滚动评论区加载更多
+	MaxComments     int    `json:"max_comments,omitempty" binding:"omitempty,min=1,max=1000" description:"需要的评论数量，超过首屏数量时滚动加载更多，不填只返回首屏评论"`
+	LoadSubComments bool   `json:"load_sub_comments,omitempty" description:"是否展开评论的回复"`
+	Account         string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
+}
+
+// options 笔记详情加载选项
+func (r *FeedDetailRequest) options() xiaohongshu.FeedDetailOptions {
+	return xiaohongshu.FeedDetailOptions{
+		MaxComments:     r.MaxComments,
+		LoadSubComments: r.LoadSubComments,
+	}
 }
 
 // FeedDetailResponse Feed详情响应
 type FeedDetailResponse struct {
-	FeedID string `json:"feed_id"`
-	Data   any    `json:"data"`
+	FeedID string                          `json:"feed_id"`
+	Data   *xiaohongshu.FeedDetailResponse `json:"data"`
 }
 
 // PostCommentRequest 发表评论请求
 type PostCommentRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
-	Content   string `json:"content" binding:"required"`
+	FeedID    string `json:"feed_id" binding:"required" description:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" description:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Content   string `json:"content" binding:"required" description:"评论内容"`
+	Humanize  string `json:"humanize,omitempty" binding:"omitempty,oneof=fast normal cautious" enum:"fast,normal,cautious" description:"可选的操作节奏：fast、normal 或 cautious，控制点击、输入的速度和停顿，不提供时使用服务默认值"`
+	Account   string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // PostCommentResponse 发表评论响应
@@ -89,8 +188,10 @@ type PostCommentResponse struct {
 
 // LikeFeedRequest 点赞请求
 type LikeFeedRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
+	FeedID    string `json:"feed_id" binding:"required" description:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" description:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Humanize  string `json:"humanize,omitempty" binding:"omitempty,oneof=fast normal cautious" enum:"fast,normal,cautious" description:"可选的操作节奏：fast、normal 或 cautious，控制点击、输入的速度和停顿，不提供时使用服务默认值"`
+	Account   string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // LikeFeedResponse 点赞响应
@@ -104,8 +205,10 @@ type LikeFeedResponse struct {
 
 // CollectFeedRequest 收藏请求
 type CollectFeedRequest struct {
-	FeedID    string `json:"feed_id" binding:"required"`
-	XsecToken string `json:"xsec_token" binding:"required"`
+	FeedID    string `json:"feed_id" binding:"required" description:"小红书笔记ID，从Feed列表获取"`
+	XsecToken string `json:"xsec_token" binding:"required" description:"访问令牌，从Feed列表的xsecToken字段获取"`
+	Humanize  string `json:"humanize,omitempty" binding:"omitempty,oneof=fast normal cautious" enum:"fast,normal,cautious" description:"可选的操作节奏：fast、normal 或 cautious，控制点击、输入的速度和停顿，不提供时使用服务默认值"`
+	Account   string `json:"account,omitempty" form:"account" binding:"omitempty,account" description:"可选的账号名，从 list_accounts 获取，不提供时使用默认账号"`
 }
 
 // CollectFeedResponse 收藏响应
@@ -113,6 +216,34 @@ type CollectFeedResponse struct {
 	FeedID       string `json:"feed_id"`
 	Success      bool   `json:"success"`
 	Message      string `json:"message"`
-	Collected    bool   `json:"collected"`      // Current collect status
+	Collected    bool   `json:"collected"`     // Current collect status
 	CollectCount string `json:"collect_count"` // Updated collect count
 }
+
+// ActionRequest 指定进行中动作的请求
+type ActionRequest struct {
+	ActionID string `json:"action_id" binding:"required" description:"动作ID，从 list_actions 获取"`
+}
+
+// ActionsListResponse 进行中的动作列表响应
+type ActionsListResponse struct {
+	Actions []ActionInfo `json:"actions"`
+	Count   int          `json:"count"`
+}
+
+// ActionScreenshotResponse 动作页面截图响应，图片以 image 内容返回
+type ActionScreenshotResponse struct {
+	ActionID string `json:"action_id"`
+	MIMEType string `json:"mime_type"`
+	Size     int    `json:"size"`
+	Data     []byte `json:"-"`
+}
+
+// toolContent 截图作为 image 内容返回，不放进 structuredContent
+func (r *ActionScreenshotResponse) toolContent() []MCPContent {
+	return []MCPContent{{
+		Type:     "image",
+		Data:     base64.StdEncoding.EncodeToString(r.Data),
+		MIMEType: r.MIMEType,
+	}}
+}
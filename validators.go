@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/mattn/go-runewidth"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators 注册自定义校验规则，HTTP 接口和 MCP 工具共用 gin 的校验器
+func registerValidators() {
+	registerValidatorsOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			logrus.Warn("binding validator is not go-playground/validator, skip custom validators")
+			return
+		}
+
+		// 校验错误中使用 json 字段名，便于客户端定位参数
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+
+		// maxwidth 按显示宽度限制字符串长度：中文/日文/韩文占2个单位，英文/数字占1个单位
+		if err := v.RegisterValidation("maxwidth", validateMaxWidth); err != nil {
+			logrus.Errorf("failed to register maxwidth validator: %v", err)
+		}
+
+		// account 账号必须已经配置
+		if err := v.RegisterValidation("account", validateAccount); err != nil {
+			logrus.Errorf("failed to register account validator: %v", err)
+		}
+	})
+}
+
+// validateMaxWidth maxwidth 校验规则
+func validateMaxWidth(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	return runewidth.StringWidth(fl.Field().String()) <= limit
+}
+
+// maxWidthOf 结构体字段 binding tag 中的 maxwidth 限制，没有限制时返回 0
+// 需要向用户说明限制时从 tag 读取，限制只在 tag 中声明一次
+func maxWidthOf[T any](field string) int {
+	f, ok := reflect.TypeOf((*T)(nil)).Elem().FieldByName(field)
+	if !ok {
+		return 0
+	}
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		if key, value, _ := strings.Cut(rule, "="); key == "maxwidth" {
+			n, _ := strconv.Atoi(value)
+			return n
+		}
+	}
+	return 0
+}
+
+// validateAccount account 校验规则
+func validateAccount(fl validator.FieldLevel) bool {
+	return configs.HasAccount(fl.Field().String())
+}
+
+// validateRequest 校验请求结构体
+func validateRequest(req any) error {
+	registerValidators()
+	return binding.Validator.ValidateStruct(req)
+}
@@ -0,0 +1,401 @@
+package xiaohongshu
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// 小红书网页端数据接口路径
+const (
+	apiHomeFeed    = "/api/sns/web/v1/homefeed"
+	apiSearchNotes = "/api/sns/web/v1/search/notes"
+	apiNoteFeed    = "/api/sns/web/v1/feed"
+	apiComments    = "/api/sns/web/v2/comment/page"
+	apiSubComments = "/api/sns/web/v2/comment/sub/page"
+)
+
+// 接口响应使用下划线命名，与 __INITIAL_STATE__ 的驼峰命名不同，先解码为以下结构再转换为 Feed、Comment 等类型
+
+// apiEnvelope 接口响应的外层结构
+type apiEnvelope struct {
+	Code    int             `json:"code"`
+	Success bool            `json:"success"`
+	Msg     string          `json:"msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type apiUser struct {
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	NickName  string `json:"nick_name"`
+	Avatar    string `json:"avatar"`
+	Image     string `json:"image"`
+	XsecToken string `json:"xsec_token"`
+}
+
+type apiInteractInfo struct {
+	Liked          bool   `json:"liked"`
+	LikedCount     string `json:"liked_count"`
+	SharedCount    string `json:"share_count"`
+	CommentCount   string `json:"comment_count"`
+	CollectedCount string `json:"collected_count"`
+	Collected      bool   `json:"collected"`
+}
+
+type apiImageInfo struct {
+	ImageScene string `json:"image_scene"`
+	URL        string `json:"url"`
+}
+
+type apiCover struct {
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	URL        string         `json:"url"`
+	FileID     string         `json:"file_id"`
+	URLPre     string         `json:"url_pre"`
+	URLDefault string         `json:"url_default"`
+	InfoList   []apiImageInfo `json:"info_list"`
+}
+
+type apiVideo struct {
+	Capa struct {
+		Duration int `json:"duration"`
+	} `json:"capa"`
+}
+
+type apiNoteCard struct {
+	Type         string          `json:"type"`
+	DisplayTitle string          `json:"display_title"`
+	User         apiUser         `json:"user"`
+	InteractInfo apiInteractInfo `json:"interact_info"`
+	Cover        apiCover        `json:"cover"`
+	Video        *apiVideo       `json:"video"`
+
+	// 以下字段只在笔记详情接口中返回
+	NoteID     string `json:"note_id"`
+	Title      string `json:"title"`
+	Desc       string `json:"desc"`
+	Time       int64  `json:"time"`
+	IPLocation string `json:"ip_location"`
+	ImageList  []struct {
+		Width      int    `json:"width"`
+		Height     int    `json:"height"`
+		URLDefault string `json:"url_default"`
+		URLPre     string `json:"url_pre"`
+		LivePhoto  bool   `json:"live_photo"`
+	} `json:"image_list"`
+}
+
+type apiFeedItem struct {
+	ID        string       `json:"id"`
+	ModelType string       `json:"model_type"`
+	XsecToken string       `json:"xsec_token"`
+	NoteCard  *apiNoteCard `json:"note_card"`
+}
+
+// apiFeedPage 首页推荐、搜索和笔记详情接口的 data
+type apiFeedPage struct {
+	Items   []apiFeedItem `json:"items"`
+	HasMore bool          `json:"has_more"`
+}
+
+type apiComment struct {
+	ID              string       `json:"id"`
+	NoteID          string       `json:"note_id"`
+	Content         string       `json:"content"`
+	LikeCount       string       `json:"like_count"`
+	CreateTime      int64        `json:"create_time"`
+	IPLocation      string       `json:"ip_location"`
+	Liked           bool         `json:"liked"`
+	UserInfo        apiUser      `json:"user_info"`
+	SubCommentCount string       `json:"sub_comment_count"`
+	SubComments     []apiComment `json:"sub_comments"`
+	ShowTags        []string     `json:"show_tags"`
+}
+
+// apiCommentPage 评论和子评论接口的 data
+type apiCommentPage struct {
+	Comments []apiComment `json:"comments"`
+	Cursor   string       `json:"cursor"`
+	HasMore  bool         `json:"has_more"`
+}
+
+// decodeAPIData 解析接口响应的外层结构，接口返回失败时返回错误
+func decodeAPIData(body []byte, data any) error {
+	var envelope apiEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return errors.Wrap(err, "解析接口响应失败")
+	}
+	if !envelope.Success && envelope.Code != 0 {
+		return errors.Errorf("接口返回错误 %d: %s", envelope.Code, envelope.Msg)
+	}
+	if len(envelope.Data) == 0 {
+		return errors.New("接口响应缺少 data")
+	}
+	return errors.Wrap(json.Unmarshal(envelope.Data, data), "解析接口数据失败")
+}
+
+// decodeAPIFeeds 将首页推荐或搜索接口的响应转换为 Feed 列表，跳过推荐搜索词等非笔记条目
+func decodeAPIFeeds(body []byte) ([]Feed, bool, error) {
+	var page apiFeedPage
+	if err := decodeAPIData(body, &page); err != nil {
+		return nil, false, err
+	}
+
+	feeds := make([]Feed, 0, len(page.Items))
+	for _, item := range page.Items {
+		if item.NoteCard == nil || item.ID == "" {
+			continue
+		}
+		feeds = append(feeds, item.toFeed())
+	}
+	return feeds, page.HasMore, nil
+}
+
+// decodeAPINoteDetail 将笔记详情接口的响应转换为 FeedDetail，没有对应笔记时返回 false
+func decodeAPINoteDetail(body []byte, feedID string) (FeedDetail, bool, error) {
+	var page apiFeedPage
+	if err := decodeAPIData(body, &page); err != nil {
+		return FeedDetail{}, false, err
+	}
+
+	for _, item := range page.Items {
+		if item.NoteCard == nil || (item.ID != feedID && item.NoteCard.NoteID != feedID) {
+			continue
+		}
+		return item.toFeedDetail(), true, nil
+	}
+	return FeedDetail{}, false, nil
+}
+
+// decodeAPIComments 将评论或子评论接口的响应转换为评论列表
+func decodeAPIComments(body []byte) (CommentList, error) {
+	var page apiCommentPage
+	if err := decodeAPIData(body, &page); err != nil {
+		return CommentList{}, err
+	}
+
+	list := CommentList{
+		List:    make([]Comment, 0, len(page.Comments)),
+		Cursor:  page.Cursor,
+		HasMore: page.HasMore,
+	}
+	for _, c := range page.Comments {
+		list.List = append(list.List, c.toComment())
+	}
+	return list, nil
+}
+
+func (u apiUser) toUser() User {
+	avatar := u.Avatar
+	if avatar == "" {
+		avatar = u.Image
+	}
+	return User{
+		UserID:    u.UserID,
+		Nickname:  u.Nickname,
+		NickName:  u.NickName,
+		Avatar:    avatar,
+		XsecToken: u.XsecToken,
+	}
+}
+
+func (i apiInteractInfo) toInteractInfo() InteractInfo {
+	return InteractInfo(i)
+}
+
+func (item apiFeedItem) toFeed() Feed {
+	card := item.NoteCard
+
+	cover := Cover{
+		Width:      card.Cover.Width,
+		Height:     card.Cover.Height,
+		URL:        card.Cover.URL,
+		FileID:     card.Cover.FileID,
+		URLPre:     card.Cover.URLPre,
+		URLDefault: card.Cover.URLDefault,
+	}
+	for _, info := range card.Cover.InfoList {
+		cover.InfoList = append(cover.InfoList, ImageInfo(info))
+	}
+
+	var video *Video
+	if card.Video != nil {
+		video = &Video{Capa: VideoCapability{Duration: card.Video.Capa.Duration}}
+	}
+
+	return Feed{
+		XsecToken: item.XsecToken,
+		ID:        item.ID,
+		ModelType: item.ModelType,
+		NoteCard: NoteCard{
+			Type:         card.Type,
+			DisplayTitle: card.DisplayTitle,
+			User:         card.User.toUser(),
+			InteractInfo: card.InteractInfo.toInteractInfo(),
+			Cover:        cover,
+			Video:        video,
+		},
+	}
+}
+
+func (item apiFeedItem) toFeedDetail() FeedDetail {
+	card := item.NoteCard
+
+	noteID := card.NoteID
+	if noteID == "" {
+		noteID = item.ID
+	}
+
+	detail := FeedDetail{
+		NoteID:       noteID,
+		XsecToken:    item.XsecToken,
+		Title:        card.Title,
+		Desc:         card.Desc,
+		Type:         card.Type,
+		Time:         card.Time,
+		IPLocation:   card.IPLocation,
+		User:         card.User.toUser(),
+		InteractInfo: card.InteractInfo.toInteractInfo(),
+	}
+	for _, img := range card.ImageList {
+		detail.ImageList = append(detail.ImageList, DetailImageInfo(img))
+	}
+	return detail
+}
+
+func (c apiComment) toComment() Comment {
+	comment := Comment{
+		ID:              c.ID,
+		NoteID:          c.NoteID,
+		Content:         c.Content,
+		LikeCount:       c.LikeCount,
+		CreateTime:      c.CreateTime,
+		IPLocation:      c.IPLocation,
+		Liked:           c.Liked,
+		UserInfo:        c.UserInfo.toUser(),
+		SubCommentCount: c.SubCommentCount,
+		ShowTags:        c.ShowTags,
+	}
+	for _, sub := range c.SubComments {
+		comment.SubComments = append(comment.SubComments, sub.toComment())
+	}
+	return comment
+}
+
+// collectAPIFeeds 按接收顺序解析已记录的首页推荐或搜索响应，返回所有笔记和最后一页是否还有更多
+func collectAPIFeeds(responses []APIResponse) ([]Feed, bool) {
+	var feeds []Feed
+	hasMore := true
+	for _, resp := range responses {
+		page, more, err := decodeAPIFeeds(resp.Body)
+		if err != nil {
+			continue
+		}
+		feeds = append(feeds, page...)
+		hasMore = more
+	}
+	return feeds, hasMore
+}
+
+// mergeFeeds 按 ID 去重合并 Feed，保持 base 的顺序，新增的 Feed 追加到末尾并重新编号
+func mergeFeeds(base []Feed, extra []Feed) []Feed {
+	seen := make(map[string]bool, len(base)+len(extra))
+	merged := make([]Feed, 0, len(base)+len(extra))
+	for _, list := range [][]Feed{base, extra} {
+		for _, feed := range list {
+			if feed.ID == "" || seen[feed.ID] {
+				continue
+			}
+			seen[feed.ID] = true
+			merged = append(merged, feed)
+		}
+	}
+	for i := len(base); i < len(merged); i++ {
+		merged[i].Index = i
+	}
+	return merged
+}
+
+// mergeAPIComments 把已记录的评论和子评论响应合并到首屏评论中
+// 顶层评论按 ID 去重追加，子评论按 root_comment_id 归入对应的顶层评论；游标和是否还有更多取最后一页评论的值
+func mergeAPIComments(base CommentList, noteID string, comments, subComments []APIResponse) CommentList {
+	merged := CommentList{
+		List:    append([]Comment(nil), base.List...),
+		Cursor:  base.Cursor,
+		HasMore: base.HasMore,
+	}
+
+	index := make(map[string]int, len(merged.List))
+	for i, c := range merged.List {
+		index[c.ID] = i
+	}
+
+	for _, resp := range comments {
+		if !responseForNote(resp, noteID) {
+			continue
+		}
+		page, err := decodeAPIComments(resp.Body)
+		if err != nil {
+			continue
+		}
+		for _, c := range page.List {
+			if _, ok := index[c.ID]; ok {
+				continue
+			}
+			index[c.ID] = len(merged.List)
+			merged.List = append(merged.List, c)
+		}
+		merged.Cursor = page.Cursor
+		merged.HasMore = page.HasMore
+	}
+
+	for _, resp := range subComments {
+		if !responseForNote(resp, noteID) {
+			continue
+		}
+		i, ok := index[queryParam(resp.URL, "root_comment_id")]
+		if !ok {
+			continue
+		}
+		page, err := decodeAPIComments(resp.Body)
+		if err != nil {
+			continue
+		}
+		merged.List[i].SubComments = mergeComments(merged.List[i].SubComments, page.List)
+	}
+
+	return merged
+}
+
+// mergeComments 按 ID 去重追加评论
+func mergeComments(base, extra []Comment) []Comment {
+	seen := make(map[string]bool, len(base))
+	merged := append([]Comment(nil), base...)
+	for _, c := range base {
+		seen[c.ID] = true
+	}
+	for _, c := range extra {
+		if !seen[c.ID] {
+			seen[c.ID] = true
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// responseForNote 评论接口响应是否属于该笔记
+func responseForNote(resp APIResponse, noteID string) bool {
+	id := queryParam(resp.URL, "note_id")
+	return id == "" || id == noteID
+}
+
+func queryParam(rawURL, key string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(key)
+}
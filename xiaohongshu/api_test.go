@@ -0,0 +1,175 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHomeFeedBody = `{
+  "code": 0,
+  "success": true,
+  "msg": "成功",
+  "data": {
+    "cursor_score": "1.7",
+    "items": [
+      {
+        "id": "note1",
+        "model_type": "note",
+        "xsec_token": "token1",
+        "note_card": {
+          "type": "video",
+          "display_title": "第一篇",
+          "user": {"user_id": "u1", "nickname": "作者", "avatar": "https://avatar/u1", "xsec_token": "ut1"},
+          "interact_info": {"liked": true, "liked_count": "12"},
+          "cover": {"width": 100, "height": 200, "url_default": "https://cover/1", "info_list": [{"image_scene": "WB_DFT", "url": "https://cover/1"}]},
+          "video": {"capa": {"duration": 30}}
+        }
+      },
+      {"id": "query1", "model_type": "rec_query", "xsec_token": ""}
+    ],
+    "has_more": true
+  }
+}`
+
+func TestDecodeAPIFeeds(t *testing.T) {
+	feeds, hasMore, err := decodeAPIFeeds([]byte(testHomeFeedBody))
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, feeds, 1)
+
+	feed := feeds[0]
+	assert.Equal(t, "note1", feed.ID)
+	assert.Equal(t, "token1", feed.XsecToken)
+	assert.Equal(t, "第一篇", feed.NoteCard.DisplayTitle)
+	assert.Equal(t, "作者", feed.NoteCard.User.Nickname)
+	assert.Equal(t, "ut1", feed.NoteCard.User.XsecToken)
+	assert.True(t, feed.NoteCard.InteractInfo.Liked)
+	assert.Equal(t, "12", feed.NoteCard.InteractInfo.LikedCount)
+	assert.Equal(t, "https://cover/1", feed.NoteCard.Cover.URLDefault)
+	assert.Equal(t, []ImageInfo{{ImageScene: "WB_DFT", URL: "https://cover/1"}}, feed.NoteCard.Cover.InfoList)
+	require.NotNil(t, feed.NoteCard.Video)
+	assert.Equal(t, 30, feed.NoteCard.Video.Capa.Duration)
+}
+
+func TestDecodeAPIFeedsError(t *testing.T) {
+	_, _, err := decodeAPIFeeds([]byte(`{"code": -100, "success": false, "msg": "登录已过期"}`))
+	assert.ErrorContains(t, err, "登录已过期")
+
+	_, _, err = decodeAPIFeeds([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDecodeAPINoteDetail(t *testing.T) {
+	body := `{"code": 0, "success": true, "data": {"items": [{
+		"id": "note1",
+		"xsec_token": "token1",
+		"note_card": {
+			"note_id": "note1",
+			"type": "normal",
+			"title": "标题",
+			"desc": "正文",
+			"time": 1700000000000,
+			"ip_location": "上海",
+			"user": {"user_id": "u1", "nickname": "作者"},
+			"interact_info": {"comment_count": "3"},
+			"image_list": [{"width": 10, "height": 20, "url_default": "https://img/1", "live_photo": true}]
+		}
+	}]}}`
+
+	detail, ok, err := decodeAPINoteDetail([]byte(body), "note1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "note1", detail.NoteID)
+	assert.Equal(t, "标题", detail.Title)
+	assert.Equal(t, "正文", detail.Desc)
+	assert.Equal(t, int64(1700000000000), detail.Time)
+	assert.Equal(t, "上海", detail.IPLocation)
+	assert.Equal(t, "3", detail.InteractInfo.CommentCount)
+	assert.Equal(t, []DetailImageInfo{{Width: 10, Height: 20, URLDefault: "https://img/1", LivePhoto: true}}, detail.ImageList)
+
+	_, ok, err = decodeAPINoteDetail([]byte(body), "other")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMergeFeeds(t *testing.T) {
+	base := []Feed{{ID: "a", Index: 0}, {ID: "b", Index: 1}}
+	extra := []Feed{{ID: "b"}, {ID: "c"}, {ID: ""}, {ID: "d"}}
+
+	merged := mergeFeeds(base, extra)
+	require.Len(t, merged, 4)
+	for i, id := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, id, merged[i].ID)
+		assert.Equal(t, i, merged[i].Index)
+	}
+}
+
+func TestCollectAPIFeeds(t *testing.T) {
+	last := `{"code": 0, "success": true, "data": {"items": [{"id": "note2", "note_card": {}}], "has_more": false}}`
+
+	feeds, hasMore := collectAPIFeeds([]APIResponse{
+		{Body: []byte(testHomeFeedBody)},
+		{Body: []byte(`{"code": 300012, "success": false}`)},
+		{Body: []byte(last)},
+	})
+	require.Len(t, feeds, 2)
+	assert.Equal(t, "note1", feeds[0].ID)
+	assert.Equal(t, "note2", feeds[1].ID)
+	assert.False(t, hasMore)
+}
+
+func TestMergeAPIComments(t *testing.T) {
+	base := CommentList{
+		List:    []Comment{{ID: "c1", Content: "首屏评论"}},
+		Cursor:  "c1",
+		HasMore: true,
+	}
+
+	comments := []APIResponse{
+		{
+			URL: "https://edith.xiaohongshu.com/api/sns/web/v2/comment/page?note_id=note1&cursor=c1",
+			Body: []byte(`{"code": 0, "success": true, "data": {"comments": [
+				{"id": "c1", "content": "重复"},
+				{"id": "c2", "note_id": "note1", "content": "第二条", "like_count": "5", "user_info": {"user_id": "u2", "nickname": "读者", "image": "https://avatar/u2"}, "sub_comment_count": "1"}
+			], "cursor": "c2", "has_more": false}}`),
+		},
+		{
+			// 其他笔记的评论不合并
+			URL:  "https://edith.xiaohongshu.com/api/sns/web/v2/comment/page?note_id=note2",
+			Body: []byte(`{"code": 0, "success": true, "data": {"comments": [{"id": "x1"}]}}`),
+		},
+	}
+	subComments := []APIResponse{
+		{
+			URL:  "https://edith.xiaohongshu.com/api/sns/web/v2/comment/sub/page?note_id=note1&root_comment_id=c2",
+			Body: []byte(`{"code": 0, "success": true, "data": {"comments": [{"id": "s1", "content": "回复"}]}}`),
+		},
+		{
+			URL:  "https://edith.xiaohongshu.com/api/sns/web/v2/comment/sub/page?note_id=note1&root_comment_id=unknown",
+			Body: []byte(`{"code": 0, "success": true, "data": {"comments": [{"id": "s2"}]}}`),
+		},
+	}
+
+	merged := mergeAPIComments(base, "note1", comments, subComments)
+	require.Len(t, merged.List, 2)
+	assert.Equal(t, "首屏评论", merged.List[0].Content)
+	assert.Equal(t, "c2", merged.List[1].ID)
+	assert.Equal(t, "5", merged.List[1].LikeCount)
+	assert.Equal(t, "读者", merged.List[1].UserInfo.Nickname)
+	assert.Equal(t, "https://avatar/u2", merged.List[1].UserInfo.Avatar)
+	require.Len(t, merged.List[1].SubComments, 1)
+	assert.Equal(t, "s1", merged.List[1].SubComments[0].ID)
+	assert.Equal(t, "c2", merged.Cursor)
+	assert.False(t, merged.HasMore)
+
+	// 原始列表不受影响
+	assert.Len(t, base.List, 1)
+}
+
+func TestIsAPIURL(t *testing.T) {
+	assert.True(t, isAPIURL("https://edith.xiaohongshu.com/api/sns/web/v1/homefeed"))
+	assert.False(t, isAPIURL("https://www.xiaohongshu.com/explore"))
+	assert.False(t, isAPIURL("https://example.com/api/sns/web/v1/homefeed"))
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -23,7 +22,7 @@ func NewCollectFeedAction(page *rod.Page) *CollectFeedAction {
 
 // CollectPost 收藏或取消收藏 Feed
 func (c *CollectFeedAction) CollectPost(ctx context.Context, feedID, xsecToken string) (*CollectResult, error) {
-	page := c.page.Context(ctx).Timeout(60 * time.Second)
+	page := withTimeout(c.page.Context(ctx), 60*time.Second)
 
 	// 构建详情页 URL
 	url := makeFeedDetailURL(feedID, xsecToken)
@@ -31,10 +30,16 @@ func (c *CollectFeedAction) CollectPost(ctx context.Context, feedID, xsecToken s
 	logrus.Infof("Opening feed detail page for collect action: %s", url)
 
 	// 导航到详情页
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
+	if err := navigate(page, url); err != nil {
+		return nil, err
+	}
+	if err := waitDOMStable(page); err != nil {
+		return nil, err
+	}
 
-	time.Sleep(2 * time.Second)
+	if err := humanPause(ctx); err != nil {
+		return nil, err
+	}
 
 	// 获取当前收藏状态（从页面数据获取）
 	currentCollected, currentCount, err := c.getCurrentCollectStatus(page)
@@ -47,50 +52,26 @@ func (c *CollectFeedAction) CollectPost(ctx context.Context, feedID, xsecToken s
 
 	logrus.Infof("Current collect status - Collected: %v, Count: %s", currentCollected, currentCount)
 
-	// 使用更精确的选择器策略，类似于like功能
-	var collectButton *rod.Element
-	
-	// 首先尝试最常见的小红书收藏按钮选择器
-	specificSelectors := []string{
-		// 小红书特定的收藏按钮结构
-		".interact-container .collect-wrapper",
-		".interact-container .collect-btn",
-		".interact-container button[class*='collect']",
-		".interact-container div[class*='collect']",
-		// 基于交互区域的精确查找
-		".note-interact .collect-wrapper",
-		".note-interact .collect-btn", 
-		".note-interact button:nth-child(3)",  // 收藏通常是第三个按钮
-		".note-interact div:nth-child(3)",
-		// 更通用但仍然精确的选择器
-		".interact-info button:nth-child(3)",
-		".interact-info div:nth-child(3)",
-	}
-
-	for _, selector := range specificSelectors {
-		if element, err := page.Element(selector); err == nil {
-			collectButton = element
-			logrus.Infof("Found collect button with selector: %s", selector)
-			break
-		}
+	// 按选择器注册表中的候选优先级查找可点击的收藏按钮
+	collectButton, err := c.findCollectButton(page)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果精确选择器失败，尝试更广泛的搜索
-	if collectButton == nil {
-		var err error
-		collectButton, err = c.findCollectButton(page)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to find collect button")
-		}
+	// 点击前检查是否已取消，避免取消后仍然收藏
+	if err := checkContext(ctx); err != nil {
+		return nil, err
 	}
 
 	// 点击收藏按钮
-	if err := collectButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := clickElement(collectButton); err != nil {
 		return nil, errors.Wrap(err, "failed to click collect button")
 	}
 
 	// 等待页面更新
-	time.Sleep(3 * time.Second)
+	if err := sleepContext(ctx, 3*time.Second); err != nil {
+		return nil, err
+	}
 
 	// 获取更新后的收藏状态
 	newCollected, newCount, err := c.getCurrentCollectStatus(page)
@@ -117,85 +98,17 @@ type CollectResult struct {
 	Action       string `json:"action"` // "collected" or "uncollected"
 }
 
-// findCollectButton 查找收藏按钮
+// findCollectButton 查找可点击的收藏按钮，所有候选都未匹配时输出页面结构，便于调整选择器
 func (c *CollectFeedAction) findCollectButton(page *rod.Page) (*rod.Element, error) {
 	// 设置超时时间，避免无限等待
-	timeout := time.After(30 * time.Second)
-
-	// 按优先级排序的选择器列表
-	prioritizedSelectors := []string{
-		// 最高优先级：小红书特定的交互容器选择器
-		".interact-container .collect-wrapper",
-		".interact-container .collect-btn",
-		".interact-container button[class*='collect']",
-		".interact-container div[class*='collect']",
-		
-		// 高优先级：基于交互区域的精确查找
-		".note-interact .collect-wrapper",
-		".note-interact .collect-btn",
-		".note-interact button:nth-child(3)",  // 收藏通常是第三个按钮
-		".note-interact div:nth-child(3)",
-		".interact-info button:nth-child(3)", 
-		".interact-info div:nth-child(3)",
-		
-		// 中等优先级：小红书特定的收藏按钮选择器
-		"button[class*='collect-lottie']",
-		"button[class*='collect']",
-		"div[class*='collect-lottie']", 
-		"div[class*='collect']",
-		"span[class*='collect-lottie']",
-		"span[class*='collect']",
-		
-		// 较低优先级：基于交互区域的通用查找
-		".interact-info button[class*='collect']",
-		".interact-info div[class*='collect']",
-		".note-interact button[class*='collect']",
-		".note-interact div[class*='collect']",
-		
-		// 基于图标的查找
-		"button svg[class*='star']",
-		"div svg[class*='star']",
-		"span svg[class*='star']",
-		"button svg[class*='bookmark']",
-		"div svg[class*='bookmark']",
-		"span svg[class*='bookmark']",
-		
-		// 通用选择器
-		".collect-btn",
-		".collect-button", 
-		"[data-testid*='collect']",
-		"[data-testid*='bookmark']",
-		
-		// 基于文本的查找（最后尝试，因为可能不稳定）
-		"button:contains('收藏')",
-		"div:contains('收藏')",
-		"span:contains('收藏')",
-	}
-
-	for i, selector := range prioritizedSelectors {
-		select {
-		case <-timeout:
-			return nil, errors.New("timeout while searching for collect button")
-		default:
-			if element, err := page.Element(selector); err == nil {
-				logrus.Infof("Found collect button with selector (priority %d): %s", i+1, selector)
-				
-				// 验证元素是否可点击
-				if c.isElementClickable(element) {
-					return element, nil
-				} else {
-					logrus.Warnf("Element found but not clickable with selector: %s", selector)
-					continue
-				}
-			}
-		}
+	button, err := findNamedWithin(page, 30*time.Second, SelectorCollectButton, c.isElementClickable)
+	if err != nil {
+		logrus.Warn("All collect button selectors failed, attempting to debug page structure")
+		c.debugPageStructure(page)
+		return nil, err
 	}
 
-	// 如果所有选择器都失败，尝试调试页面结构
-	logrus.Warn("All collect button selectors failed, attempting to debug page structure")
-	c.debugPageStructure(page)
-
-	return nil, errors.New("collect button not found with any known selector")
+	return button, nil
 }
 
 // isElementClickable 检查元素是否可点击
@@ -205,26 +118,26 @@ func (c *CollectFeedAction) isElementClickable(element *rod.Element) bool {
 	if err != nil || !visible {
 		return false
 	}
-	
+
 	// 检查元素是否有有效的边界框
 	shape, err := element.Shape()
 	if err != nil {
 		return false
 	}
-	
+
 	// 获取边界框
 	box := shape.Box()
 	if box == nil || box.Width == 0 || box.Height == 0 {
 		return false
 	}
-	
+
 	return true
 }
 
 // getCurrentCollectStatus 获取当前收藏状态
 func (c *CollectFeedAction) getCurrentCollectStatus(page *rod.Page) (bool, string, error) {
 	// 方法1: 从 __INITIAL_STATE__ 获取（最可靠的方法）
-	result := page.MustEval(`() => {
+	result, err := evalString(page, `() => {
 		try {
 			if (window.__INITIAL_STATE__ && window.__INITIAL_STATE__.note) {
 				const noteDetailMap = window.__INITIAL_STATE__.note.noteDetailMap;
@@ -243,7 +156,10 @@ func (c *CollectFeedAction) getCurrentCollectStatus(page *rod.Page) (bool, strin
 			console.error("Error getting collect status:", e);
 			return null;
 		}
-	}`).String()
+	}`)
+	if err != nil {
+		return false, "", err
+	}
 
 	if result != "null" && result != "" && result != "undefined" {
 		// 尝试解析JSON结果
@@ -261,36 +177,11 @@ func (c *CollectFeedAction) getCurrentCollectStatus(page *rod.Page) (bool, strin
 
 	// 方法2: 从DOM元素获取收藏状态
 	// 检查收藏按钮是否有激活状态的class
-	collectStatusSelectors := []string{
-		// 小红书特定的激活状态选择器
-		".interact-container .collect-wrapper.active",
-		".interact-container .collect-btn.active", 
-		".interact-container button[class*='collect'][class*='active']",
-		".interact-container div[class*='collect'][class*='active']",
-		".note-interact .collect-wrapper.active",
-		".note-interact .collect-btn.active",
-		".interact-info button[class*='active']:nth-child(3)",
-		".interact-info div[class*='active']:nth-child(3)",
-		// 通用激活状态选择器
-		"button[class*='collect'][class*='active']",
-		"div[class*='collect'][class*='active']",
-		"span[class*='collect'][class*='active']",
-		".collect-btn.active",
-		".collect-button.active",
-	}
-
-	isCollected := false
-	for _, selector := range collectStatusSelectors {
-		if _, err := page.Element(selector); err == nil {
-			isCollected = true
-			logrus.Infof("Found active collect button with selector: %s", selector)
-			break
-		}
-	}
+	_, isCollected := findNamedNow(page, SelectorCollectActive, nil)
 
 	// 获取收藏数
 	count := c.extractCollectCount(page)
-	
+
 	logrus.Infof("Got collect status from DOM: collected=%v, count=%s", isCollected, count)
 	return isCollected, count, nil
 }
@@ -298,7 +189,8 @@ func (c *CollectFeedAction) getCurrentCollectStatus(page *rod.Page) (bool, strin
 // extractCollectCount 从页面中提取收藏数
 func (c *CollectFeedAction) extractCollectCount(page *rod.Page) string {
 	// 首先尝试从 __INITIAL_STATE__ 获取收藏数（最准确）
-	result := page.MustEval(`() => {
+	// 脚本执行失败时 result 为空，继续尝试从 DOM 获取
+	result, _ := evalString(page, `() => {
 		try {
 			if (window.__INITIAL_STATE__ && window.__INITIAL_STATE__.note) {
 				const noteDetailMap = window.__INITIAL_STATE__.note.noteDetailMap;
@@ -314,7 +206,7 @@ func (c *CollectFeedAction) extractCollectCount(page *rod.Page) string {
 			console.error("Error getting collect count:", e);
 			return "0";
 		}
-	}`).String()
+	}`)
 
 	if result != "" && result != "null" && result != "undefined" {
 		logrus.Infof("Got collect count from __INITIAL_STATE__: %s", result)
@@ -322,34 +214,10 @@ func (c *CollectFeedAction) extractCollectCount(page *rod.Page) string {
 	}
 
 	// 如果从 __INITIAL_STATE__ 获取失败，尝试从DOM获取
-	countSelectors := []string{
-		// 小红书特定的收藏数选择器
-		".interact-container .collect-wrapper .count",
-		".interact-container .collect-btn .count",
-		".interact-container button[class*='collect'] .count",
-		".interact-container div[class*='collect'] .count",
-		".note-interact .collect-wrapper .count",
-		".note-interact .collect-btn .count",
-		".interact-info button:nth-child(3) .count",
-		".interact-info div:nth-child(3) .count",
-		// 通用收藏数选择器
-		".collect-count",
-		".collect-count span",
-		"[class*='collect'][class*='count']",
-		".interact-info .collect-count",
-		".note-interact .collect-count",
-		// 更广泛的搜索
-		"button[class*='collect'] span",
-		"div[class*='collect'] span",
-	}
-
-	for _, selector := range countSelectors {
-		if element, err := page.Element(selector); err == nil {
-			if text, err := element.Text(); err == nil && text != "" {
-				logrus.Infof("Got collect count from DOM selector %s: %s", selector, text)
-				return text
-			}
-		}
+	if elem, ok := findNamedNow(page, SelectorCollectCount, hasText); ok {
+		text, _ := elem.Text()
+		logrus.Infof("Got collect count from DOM: %s", text)
+		return text
 	}
 
 	logrus.Warn("Could not extract collect count, returning default value")
@@ -371,15 +239,16 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 	logrus.Info("=== Debugging page structure for collect button ===")
 
 	// 首先检查页面URL和标题
-	url := page.MustInfo().URL
-	title := page.MustEval("() => document.title").String()
-	logrus.Infof("Page URL: %s", url)
+	if info, err := page.Info(); err == nil {
+		logrus.Infof("Page URL: %s", info.URL)
+	}
+	title, _ := evalString(page, "() => document.title")
 	logrus.Infof("Page Title: %s", title)
 
 	// 查找所有可能的交互容器
 	interactSelectors := []string{
 		".interact-container",
-		".interact-info", 
+		".interact-info",
 		".note-interact",
 		".interact",
 		".actions",
@@ -399,7 +268,7 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 					}
 					logrus.Infof("Element %d HTML: %s", i, htmlStr)
 				}
-				
+
 				// 获取元素的class属性
 				if className, err := elem.Attribute("class"); err == nil && className != nil {
 					logrus.Infof("Element %d class: %s", i, *className)
@@ -416,12 +285,12 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 			if className, err := elem.Attribute("class"); err == nil && className != nil {
 				logrus.Infof("Collect element %d class: %s", i, *className)
 			}
-			
+
 			// 检查元素的标签名
 			if tagName, err := elem.Eval("() => this.tagName"); err == nil {
 				logrus.Infof("Collect element %d tag: %s", i, tagName.Value)
 			}
-			
+
 			// 检查是否可见和可点击
 			if visible, err := elem.Visible(); err == nil {
 				logrus.Infof("Collect element %d visible: %v", i, visible)
@@ -437,7 +306,7 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 			if className, err := button.Attribute("class"); err == nil && className != nil {
 				logrus.Infof("Button %d class: %s", i, *className)
 			}
-			
+
 			// 获取按钮文本
 			if text, err := button.Text(); err == nil && text != "" {
 				logrus.Infof("Button %d text: %s", i, text)
@@ -457,7 +326,7 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 	}
 
 	// 尝试获取页面的 __INITIAL_STATE__ 信息
-	initialStateInfo := page.MustEval(`() => {
+	initialStateInfo, _ := evalString(page, `() => {
 		try {
 			if (window.__INITIAL_STATE__) {
 				return "Found __INITIAL_STATE__";
@@ -466,8 +335,8 @@ func (c *CollectFeedAction) debugPageStructure(page *rod.Page) {
 		} catch (e) {
 			return "Error accessing __INITIAL_STATE__: " + e.message;
 		}
-	}`).String()
+	}`)
 	logrus.Infof("Initial state info: %s", initialStateInfo)
 
 	logrus.Info("=== End debugging ===")
-}
\ No newline at end of file
+}
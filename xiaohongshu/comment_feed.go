@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,7 +21,7 @@ func NewCommentFeedAction(page *rod.Page) *CommentFeedAction {
 
 // PostComment 发表评论到 Feed
 func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken, content string) error {
-	page := f.page.Context(ctx).Timeout(60 * time.Second)
+	page := withTimeout(f.page.Context(ctx), 60*time.Second)
 
 	// 构建详情页 URL
 	url := makeFeedDetailURL(feedID, xsecToken)
@@ -28,23 +29,50 @@ func (f *CommentFeedAction) PostComment(ctx context.Context, feedID, xsecToken,
 	logrus.Infof("Opening feed detail page: %s", url)
 
 	// 导航到详情页
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
+	if err := navigate(page, url); err != nil {
+		return err
+	}
+	if err := waitDOMStable(page); err != nil {
+		return err
+	}
 
-	time.Sleep(1 * time.Second)
+	if err := humanPause(ctx); err != nil {
+		return err
+	}
 
-	elem := page.MustElement("div.input-box div.content-edit span")
-	elem.MustClick()
+	elem, err := findNamed(page, SelectorCommentTrigger)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(elem); err != nil {
+		return err
+	}
 
-	elem2 := page.MustElement("div.input-box div.content-edit p.content-input")
-	elem2.MustInput(content)
+	elem2, err := findNamed(page, SelectorCommentInput)
+	if err != nil {
+		return err
+	}
+	if err := inputText(elem2, content); err != nil {
+		return err
+	}
 
-	time.Sleep(1 * time.Second)
+	// 提交前最后一次检查，已取消的请求不能再发出评论
+	if err := humanPause(ctx); err != nil {
+		return err
+	}
 
-	submitButton := page.MustElement("div.bottom button.submit")
-	submitButton.MustClick()
+	submitButton, err := findNamed(page, SelectorCommentSubmit)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(submitButton); err != nil {
+		return err
+	}
 
-	time.Sleep(1 * time.Second)
+	// 等待页面响应，评论已经提交，取消时的错误需要说明这一点
+	if err := sleepContext(ctx, 1*time.Second); err != nil {
+		return errors.Wrap(err, "评论已提交")
+	}
 
 	return nil
 }
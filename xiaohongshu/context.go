@@ -0,0 +1,30 @@
+package xiaohongshu
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// checkContext 在动作步骤边界检查 context 是否已取消或超时
+// 浏览器操作一旦被取消，不应继续点击或输入，避免留下半提交的内容
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "动作已取消")
+	}
+	return nil
+}
+
+// sleepContext 可被取消的等待，替代 time.Sleep
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "动作已取消")
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// 动作失败的类型化错误，调用方可以通过 errors.Is / errors.As 判断失败原因
+var (
+	// ErrNotLoggedIn 未登录或登录已失效
+	ErrNotLoggedIn = errors.New("未登录或登录已失效")
+
+	// ErrNavigationTimeout 页面导航或加载超时
+	ErrNavigationTimeout = errors.New("页面加载超时")
+
+	// ErrCaptchaRequired 触发了验证码，需要人工处理
+	ErrCaptchaRequired = errors.New("触发验证码，需要人工处理")
+
+	// ErrRateLimited 操作过于频繁或账号/IP 被风控限制
+	ErrRateLimited = errors.New("操作过于频繁，已被限流")
+)
+
+// ErrElementNotFound 页面上找不到期望的元素，通常意味着页面结构变化或页面没有正确加载
+type ErrElementNotFound struct {
+	Selector string
+}
+
+func (e *ErrElementNotFound) Error() string {
+	return fmt.Sprintf("页面元素未找到: %s", e.Selector)
+}
+
+// classifyError 将 rod 返回的错误转换为类型化错误
+// context 超时视为 timeoutErr，主动取消保持为取消错误，其他错误附加 action 描述
+func classifyError(err error, action string, timeoutErr error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errors.Wrap(err, "动作已取消")
+	case errors.Is(err, context.DeadlineExceeded):
+		return errors.Wrap(timeoutErr, action)
+	default:
+		return errors.Wrap(err, action)
+	}
+}
@@ -0,0 +1,52 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockedPageError(t *testing.T) {
+	tests := []struct {
+		url    string
+		target error
+	}{
+		{"https://www.xiaohongshu.com/website-login/captcha?redirectPath=x", ErrCaptchaRequired},
+		{"https://www.xiaohongshu.com/website-login/error?error_code=300013", ErrRateLimited},
+		{"https://creator.xiaohongshu.com/login", ErrNotLoggedIn},
+		{"https://www.xiaohongshu.com/explore", nil},
+		// 查询参数中的关键词和跳转地址不影响判断
+		{"https://www.xiaohongshu.com/search_result?keyword=captcha&source=web_explore_feed", nil},
+		{"https://www.xiaohongshu.com/search_result?keyword=%E9%AA%8C%E8%AF%81captcha", nil},
+		{"https://www.xiaohongshu.com/explore?redirect_uri=https%3A%2F%2Fwww.xiaohongshu.com%2Flogin", nil},
+		{"https://www.xiaohongshu.com/explore?redirect_uri=/website-login/error", nil},
+		{"https://captcha.xiaohongshu.com/verify", ErrCaptchaRequired},
+		{"https://www.xiaohongshu.com/website-login/verify?redirectPath=/login", ErrCaptchaRequired},
+	}
+
+	for _, test := range tests {
+		err := blockedPageError(test.url)
+		if test.target == nil {
+			assert.NoError(t, err, test.url)
+			continue
+		}
+		assert.ErrorIs(t, err, test.target, test.url)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	err := classifyError(context.DeadlineExceeded, "查找元素", &ErrElementNotFound{Selector: "div.submit"})
+
+	var notFound *ErrElementNotFound
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "div.submit", notFound.Selector)
+
+	err = classifyError(context.DeadlineExceeded, "打开页面", ErrNavigationTimeout)
+	assert.ErrorIs(t, err, ErrNavigationTimeout)
+
+	err = classifyError(context.Canceled, "打开页面", ErrNavigationTimeout)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrNavigationTimeout)
+}
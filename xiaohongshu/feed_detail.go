@@ -19,21 +19,44 @@ func NewFeedDetailAction(page *rod.Page) *FeedDetailAction {
 	return &FeedDetailAction{page: page}
 }
 
+// maxSubCommentExpands 一次获取详情最多展开回复的次数
+const maxSubCommentExpands = 50
+
+// FeedDetailOptions 获取笔记详情时加载评论的选项
+type FeedDetailOptions struct {
+	// MaxComments 大于首屏评论数量时滚动评论区加载更多一级评论，最多返回该数量；为 0 时只返回已经加载的评论
+	MaxComments int
+	// LoadSubComments 展开一级评论下折叠的回复
+	LoadSubComments bool
+}
+
 // GetFeedDetail 获取 Feed 详情页数据
-func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*FeedDetailResponse, error) {
-	page := f.page.Context(ctx).Timeout(60 * time.Second)
+// 除了 __INITIAL_STATE__ 中的首屏数据，还会合并页面通过评论接口加载的评论和回复，按 opts 滚动评论区或展开回复加载更多
+func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken string, opts FeedDetailOptions) (*FeedDetailResponse, error) {
+	session := f.page.Context(ctx)
+	page := withTimeout(session, 60*time.Second)
+
+	// 评论由页面请求评论接口加载，在导航之前开始记录
+	api := recordAPI(session)
+	defer api.stop()
 
 	// 构建详情页 URL
 	url := makeFeedDetailURL(feedID, xsecToken)
 
 	// 导航到详情页
-	page.MustNavigate(url)
-	page.MustWaitDOMStable()
-	time.Sleep(1 * time.Second)
+	if err := navigate(page, url); err != nil {
+		return nil, err
+	}
+	if err := waitDOMStable(page); err != nil {
+		return nil, err
+	}
+	if err := sleepContext(ctx, 1*time.Second); err != nil {
+		return nil, err
+	}
 
 	// 获取 window.__INITIAL_STATE__.note.noteDetailMap[feedID] 并转换为 JSON 字符串
 	// 直接提取特定 feedID 的数据，避免 Vue.js 响应式对象的循环引用
-	result := page.MustEval(fmt.Sprintf(`() => {
+	result, err := evalString(page, fmt.Sprintf(`() => {
 		if (window.__INITIAL_STATE__ && 
 			window.__INITIAL_STATE__.note && 
 			window.__INITIAL_STATE__.note.noteDetailMap &&
@@ -45,21 +68,96 @@ func (f *FeedDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken
 			});
 		}
 		return "";
-	}`, feedID, feedID)).String()
-
-	if result == "" {
-		return nil, fmt.Errorf("feed detail not found for feedID: %s", feedID)
+	}`, feedID, feedID))
+	if err != nil {
+		return nil, err
 	}
 
 	// 直接解析为 FeedDetailResponse
 	var response FeedDetailResponse
-	if err := json.Unmarshal([]byte(result), &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal feed detail: %w", err)
+	if result != "" {
+		if err := json.Unmarshal([]byte(result), &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal feed detail: %w", err)
+		}
+	} else if note, ok := noteFromAPI(api, feedID); ok {
+		// 页面没有服务端渲染详情时，使用笔记详情接口的数据
+		response.Note = note
+	} else {
+		return nil, fmt.Errorf("feed detail not found for feedID: %s", feedID)
+	}
+
+	initial := response.Comments
+	collect := func() bool {
+		response.Comments = mergeAPIComments(initial, feedID, api.list(apiComments), api.list(apiSubComments))
+		return !response.Comments.HasMore || len(response.Comments.List) >= opts.MaxComments
+	}
+
+	if !collect() && opts.MaxComments > 0 {
+		scroll := func() error { return scrollNoteToBottom(withTimeout(session, loadMoreWait)) }
+		if err := loadMore(ctx, api, apiComments, scroll, collect); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.LoadSubComments {
+		if err := expandSubComments(ctx, session, api); err != nil {
+			return nil, err
+		}
+		collect()
+	}
+
+	if opts.MaxComments > 0 && len(response.Comments.List) > opts.MaxComments {
+		response.Comments.List = response.Comments.List[:opts.MaxComments]
 	}
 
 	return &response, nil
 }
 
+// noteFromAPI 从已记录的笔记详情接口响应中取出笔记
+func noteFromAPI(api *apiRecorder, feedID string) (FeedDetail, bool) {
+	for _, resp := range api.list(apiNoteFeed) {
+		if note, ok, err := decodeAPINoteDetail(resp.Body, feedID); err == nil && ok {
+			return note, true
+		}
+	}
+	return FeedDetail{}, false
+}
+
+// scrollNoteToBottom 把详情页的内容区滚动到底部，触发评论区加载下一页
+func scrollNoteToBottom(page *rod.Page) error {
+	scroller, ok := findNamedNow(page, SelectorNoteScroller, nil)
+	if !ok {
+		return scrollWindowToBottom(page)
+	}
+	_, err := scroller.Eval(`() => this.scrollTo(0, this.scrollHeight)`)
+	return classifyError(err, "滚动评论区", ErrNavigationTimeout)
+}
+
+// expandSubComments 依次点击"展开回复"，每次点击后等待回复接口响应，没有可展开的回复或达到次数上限时停止
+func expandSubComments(ctx context.Context, page *rod.Page, api *apiRecorder) error {
+	for i := 0; i < maxSubCommentExpands; i++ {
+		// 点击包含模拟真人的滚动和鼠标移动，超时比等待接口响应长
+		more, ok := findNamedNow(withTimeout(page, 2*loadMoreWait), SelectorCommentShowMore, isVisible)
+		if !ok {
+			return nil
+		}
+
+		before := api.count(apiSubComments)
+		if err := clickElement(more); err != nil {
+			return err
+		}
+		if !api.waitFor(ctx, apiSubComments, before, loadMoreWait) {
+			// 点击后没有请求回复接口，可能是按钮已经失效，停止展开避免重复点击
+			return checkContext(ctx)
+		}
+
+		if err := humanPause(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func makeFeedDetailURL(feedID, xsecToken string) string {
 	return fmt.Sprintf("https://www.xiaohongshu.com/explore/%s?xsec_token=%s&xsec_source=pc_feed", feedID, xsecToken)
 }
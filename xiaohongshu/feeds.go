@@ -11,6 +11,7 @@ import (
 
 type FeedsListAction struct {
 	page *rod.Page
+	api  *apiRecorder
 }
 
 // FeedsResult 定义页面初始状态结构
@@ -18,32 +19,46 @@ type FeedsResult struct {
 	Feed FeedData `json:"feed"`
 }
 
-func NewFeedsListAction(page *rod.Page) *FeedsListAction {
-	pp := page.Timeout(60 * time.Second)
+func NewFeedsListAction(page *rod.Page) (*FeedsListAction, error) {
+	pp := withTimeout(page, 60*time.Second)
 
-	pp.MustNavigate("https://www.xiaohongshu.com")
-	pp.MustWaitDOMStable()
+	// 在导航之前开始记录，首页推荐接口在首屏渲染时就会请求
+	api := recordAPI(page)
 
-	return &FeedsListAction{page: pp}
+	if err := navigate(pp, "https://www.xiaohongshu.com"); err != nil {
+		api.stop()
+		return nil, err
+	}
+	if err := waitDOMStable(pp); err != nil {
+		api.stop()
+		return nil, err
+	}
+
+	return &FeedsListAction{page: pp, api: api}, nil
 }
 
 // GetFeedsList 获取页面的 Feed 列表数据
-func (f *FeedsListAction) GetFeedsList(ctx context.Context) ([]Feed, error) {
+// 除了 __INITIAL_STATE__ 中的首屏数据，还会合并页面通过首页推荐接口加载的数据；
+// limit 大于首屏数量时向下滚动页面加载更多，最多返回 limit 条，为 0 时只返回已经加载的数据
+func (f *FeedsListAction) GetFeedsList(ctx context.Context, limit int) ([]Feed, error) {
+	defer f.api.stop()
+
 	page := f.page.Context(ctx)
 
-	time.Sleep(1 * time.Second)
+	if err := sleepContext(ctx, 1*time.Second); err != nil {
+		return nil, err
+	}
 
 	// 获取 window.__INITIAL_STATE__.feed.feeds._value 并转换为 JSON 字符串
 	// 直接提取 feeds 数组，避免 Vue.js 响应式对象的循环引用
-	result := page.MustEval(`() => {
+	result, err := evalString(page, `() => {
 		if (window.__INITIAL_STATE__ && window.__INITIAL_STATE__.feed && window.__INITIAL_STATE__.feed.feeds) {
 			return JSON.stringify(window.__INITIAL_STATE__.feed.feeds._value);
 		}
 		return "[]";
-	}`).String()
-
-	if result == "[]" {
-		return []Feed{}, nil
+	}`)
+	if err != nil {
+		return nil, err
 	}
 
 	// 直接解析为 Feed 数组
@@ -52,5 +67,26 @@ func (f *FeedsListAction) GetFeedsList(ctx context.Context) ([]Feed, error) {
 		return nil, fmt.Errorf("failed to unmarshal feeds: %w", err)
 	}
 
+	hasMore := true
+	collect := func() bool {
+		var extra []Feed
+		extra, hasMore = collectAPIFeeds(f.api.list(apiHomeFeed))
+		feeds = mergeFeeds(feeds, extra)
+		return !hasMore || len(feeds) >= limit
+	}
+
+	if !collect() && limit > 0 {
+		scroll := func() error { return scrollWindowToBottom(withTimeout(page, loadMoreWait)) }
+		if err := loadMore(ctx, f.api, apiHomeFeed, scroll, collect); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(feeds) > limit {
+		feeds = feeds[:limit]
+	}
+	if feeds == nil {
+		feeds = []Feed{}
+	}
 	return feeds, nil
 }
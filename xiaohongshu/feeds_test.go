@@ -14,16 +14,18 @@ func TestGetFeedsList(t *testing.T) {
 
 	t.Skip("SKIP: 测试发布")
 
-	b := browser.NewBrowser(false)
+	b, err := browser.Launch(false)
+	require.NoError(t, err)
 	defer b.Close()
 
-	page := b.NewPage()
+	page := b.MustNewPage()
 	defer page.Close()
 
 	// NewFeedsListAction 内部已经处理导航
-	action := NewFeedsListAction(page)
+	action, err := NewFeedsListAction(page)
+	require.NoError(t, err)
 
-	feeds, err := action.GetFeedsList(context.Background())
+	feeds, err := action.GetFeedsList(context.Background(), 0)
 	require.NoError(t, err)
 	require.NotEmpty(t, feeds, "feeds should not be empty")
 
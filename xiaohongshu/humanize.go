@@ -0,0 +1,348 @@
+package xiaohongshu
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// HumanizePreset 模拟真人操作的节奏预设
+type HumanizePreset string
+
+const (
+	// HumanizeFast 停顿较短，适合批量的只读或低风险操作
+	HumanizeFast HumanizePreset = "fast"
+	// HumanizeNormal 默认节奏
+	HumanizeNormal HumanizePreset = "normal"
+	// HumanizeCautious 停顿和输入都更慢，适合新账号或风控敏感的操作
+	HumanizeCautious HumanizePreset = "cautious"
+)
+
+// ParseHumanizePreset 解析节奏预设，空字符串返回 ""，表示使用默认预设
+func ParseHumanizePreset(s string) (HumanizePreset, error) {
+	switch p := HumanizePreset(strings.ToLower(strings.TrimSpace(s))); p {
+	case "", HumanizeFast, HumanizeNormal, HumanizeCautious:
+		return p, nil
+	default:
+		return "", errors.Errorf("未知的操作节奏 %q，可选 fast、normal、cautious", s)
+	}
+}
+
+// durationRange 随机时长区间
+type durationRange struct {
+	min, max time.Duration
+}
+
+// random 区间内的随机时长
+func (r durationRange) random() time.Duration {
+	if r.max <= r.min {
+		return r.min
+	}
+	return r.min + time.Duration(rand.Int63n(int64(r.max-r.min)))
+}
+
+// humanizeProfile 节奏预设的具体参数
+type humanizeProfile struct {
+	// step 动作步骤之间的停顿
+	step durationRange
+	// key 逐字输入时每个字符之间的停顿
+	key durationRange
+	// punctuation 输入标点和换行后的额外停顿
+	punctuation durationRange
+	// maxTyping 单次输入的最长时间，长文本按块输入，避免发布长文时耗时过久
+	maxTyping time.Duration
+	// mouseSteps 鼠标移动轨迹的点数
+	mouseSteps [2]int
+	// mouseStep 鼠标轨迹上每个点之间的停顿
+	mouseStep durationRange
+	// hover 鼠标移动到元素后点击前的停顿
+	hover durationRange
+	// scrollSteps 滚动到元素时分几次滚动
+	scrollSteps int
+}
+
+var humanizeProfiles = map[HumanizePreset]humanizeProfile{
+	HumanizeFast: {
+		step:        durationRange{150 * time.Millisecond, 400 * time.Millisecond},
+		key:         durationRange{20 * time.Millisecond, 60 * time.Millisecond},
+		punctuation: durationRange{50 * time.Millisecond, 150 * time.Millisecond},
+		maxTyping:   2 * time.Second,
+		mouseSteps:  [2]int{5, 10},
+		mouseStep:   durationRange{5 * time.Millisecond, 10 * time.Millisecond},
+		hover:       durationRange{50 * time.Millisecond, 150 * time.Millisecond},
+		scrollSteps: 3,
+	},
+	HumanizeNormal: {
+		step:        durationRange{500 * time.Millisecond, 1500 * time.Millisecond},
+		key:         durationRange{60 * time.Millisecond, 180 * time.Millisecond},
+		punctuation: durationRange{200 * time.Millisecond, 600 * time.Millisecond},
+		maxTyping:   6 * time.Second,
+		mouseSteps:  [2]int{12, 25},
+		mouseStep:   durationRange{8 * time.Millisecond, 20 * time.Millisecond},
+		hover:       durationRange{100 * time.Millisecond, 400 * time.Millisecond},
+		scrollSteps: 6,
+	},
+	HumanizeCautious: {
+		step:        durationRange{1200 * time.Millisecond, 3 * time.Second},
+		key:         durationRange{120 * time.Millisecond, 350 * time.Millisecond},
+		punctuation: durationRange{400 * time.Millisecond, 1200 * time.Millisecond},
+		maxTyping:   10 * time.Second,
+		mouseSteps:  [2]int{20, 40},
+		mouseStep:   durationRange{10 * time.Millisecond, 30 * time.Millisecond},
+		hover:       durationRange{300 * time.Millisecond, 800 * time.Millisecond},
+		scrollSteps: 10,
+	},
+}
+
+var (
+	defaultHumanizeMu     sync.RWMutex
+	defaultHumanizePreset = HumanizeNormal
+)
+
+// SetDefaultHumanizePreset 设置请求没有指定节奏时使用的预设
+func SetDefaultHumanizePreset(p HumanizePreset) {
+	if _, ok := humanizeProfiles[p]; !ok {
+		return
+	}
+
+	defaultHumanizeMu.Lock()
+	defaultHumanizePreset = p
+	defaultHumanizeMu.Unlock()
+}
+
+// DefaultHumanizePreset 当前的默认预设
+func DefaultHumanizePreset() HumanizePreset {
+	defaultHumanizeMu.RLock()
+	defer defaultHumanizeMu.RUnlock()
+	return defaultHumanizePreset
+}
+
+type humanizeKey struct{}
+
+// WithHumanize 为本次请求指定节奏预设，p 为空时使用默认预设
+// 页面绑定该 context 后，动作中的点击、输入和步骤停顿都按该预设执行
+func WithHumanize(ctx context.Context, p HumanizePreset) context.Context {
+	if p == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, humanizeKey{}, p)
+}
+
+// humanizeFrom 取出 context 中的节奏预设对应的参数
+func humanizeFrom(ctx context.Context) humanizeProfile {
+	p, _ := ctx.Value(humanizeKey{}).(HumanizePreset)
+	if profile, ok := humanizeProfiles[p]; ok {
+		return profile
+	}
+	return humanizeProfiles[DefaultHumanizePreset()]
+}
+
+// humanPause 动作步骤之间的随机停顿，替代固定的 time.Sleep
+func humanPause(ctx context.Context) error {
+	return sleepContext(ctx, humanizeFrom(ctx).step.random())
+}
+
+// humanClick 模拟真人点击：滚动到元素、沿曲线移动鼠标到元素内的随机位置、短暂停留后点击
+func humanClick(elem *rod.Element) error {
+	ctx := elem.GetContext()
+	profile := humanizeFrom(ctx)
+	page := elem.Page().Context(ctx)
+
+	if err := humanScrollIntoView(page, elem, profile); err != nil {
+		return err
+	}
+
+	// 等待元素可交互（未被遮挡），同时拿到元素位置
+	if _, err := elem.WaitInteractable(); err != nil {
+		return err
+	}
+	shape, err := elem.Shape()
+	if err != nil {
+		return err
+	}
+	box := shape.Box()
+	if box == nil {
+		return errors.New("元素没有可见区域")
+	}
+
+	if err := humanMoveMouse(page, randomPointIn(box), profile); err != nil {
+		return err
+	}
+	if err := pause(ctx, profile.hover.random()); err != nil {
+		return err
+	}
+
+	if err := elem.WaitEnabled(); err != nil {
+		return err
+	}
+	return page.Mouse.Click(proto.InputMouseButtonLeft, 1)
+}
+
+// humanScrollIntoView 元素不在视口内时，分几次滚动鼠标滚轮把元素移到视口中部
+// 元素在可滚动的容器中时滚轮不一定生效，最后再用 ScrollIntoView 兜底
+func humanScrollIntoView(page *rod.Page, elem *rod.Element, profile humanizeProfile) error {
+	res, err := elem.Eval(`() => {
+		const r = this.getBoundingClientRect();
+		if (r.top >= 0 && r.bottom <= window.innerHeight) return 0;
+		return r.top + r.height / 2 - window.innerHeight / 2;
+	}`)
+	if err != nil {
+		return err
+	}
+
+	if dy := res.Value.Num(); dy != 0 {
+		if err := page.Mouse.Scroll(0, dy, profile.scrollSteps); err != nil {
+			return err
+		}
+		if err := pause(page.GetContext(), profile.hover.random()); err != nil {
+			return err
+		}
+	}
+
+	return elem.ScrollIntoView()
+}
+
+// humanMoveMouse 沿带随机弯曲的贝塞尔曲线把鼠标移动到 to，先加速后减速
+func humanMoveMouse(page *rod.Page, to proto.Point, profile humanizeProfile) error {
+	from := page.Mouse.Position()
+	if from.X == 0 && from.Y == 0 {
+		// 页面刚创建时鼠标位置为原点，从目标附近的随机位置开始移动
+		from = proto.Point{X: to.X + float64(rand.Intn(401)-200), Y: to.Y + float64(rand.Intn(301)-150)}
+		if from.X < 0 {
+			from.X = 0
+		}
+		if from.Y < 0 {
+			from.Y = 0
+		}
+	}
+
+	steps := profile.mouseSteps[0] + rand.Intn(profile.mouseSteps[1]-profile.mouseSteps[0]+1)
+	for _, pt := range mousePath(from, to, steps) {
+		if err := page.Mouse.MoveTo(pt); err != nil {
+			return err
+		}
+		if err := pause(page.GetContext(), profile.mouseStep.random()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mousePath 从 from 到 to 的鼠标轨迹，最后一个点为 to
+func mousePath(from, to proto.Point, steps int) []proto.Point {
+	if steps < 1 {
+		steps = 1
+	}
+
+	// 控制点在两点连线的中点附近，沿垂直方向随机偏移，使轨迹略带弧度
+	dx, dy := to.X-from.X, to.Y-from.Y
+	dist := math.Hypot(dx, dy)
+	offset := (rand.Float64() - 0.5) * dist * 0.4
+	ctrl := proto.Point{X: from.X + dx/2, Y: from.Y + dy/2}
+	if dist > 0 {
+		ctrl.X += -dy / dist * offset
+		ctrl.Y += dx / dist * offset
+	}
+
+	path := make([]proto.Point, 0, steps)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		// easeInOut：起步和停下时慢，中间快
+		t = t * t * (3 - 2*t)
+		pt := proto.Point{
+			X: (1-t)*(1-t)*from.X + 2*(1-t)*t*ctrl.X + t*t*to.X,
+			Y: (1-t)*(1-t)*from.Y + 2*(1-t)*t*ctrl.Y + t*t*to.Y,
+		}
+		if i < steps {
+			// 轨迹上的手抖
+			pt.X += rand.Float64()*2 - 1
+			pt.Y += rand.Float64()*2 - 1
+		}
+		path = append(path, pt)
+	}
+	path[len(path)-1] = to
+	return path
+}
+
+// randomPointIn 元素中间区域内的随机点，避开边缘
+func randomPointIn(box *proto.DOMRect) proto.Point {
+	return proto.Point{
+		X: box.X + box.Width*(0.3+rand.Float64()*0.4),
+		Y: box.Y + box.Height*(0.3+rand.Float64()*0.4),
+	}
+}
+
+// humanType 模拟真人输入：逐字输入，字符之间随机停顿，标点和换行后停顿更久
+// 文本较长时按块输入，使总耗时不超过预设的 maxTyping
+func humanType(elem *rod.Element, text string) error {
+	ctx := elem.GetContext()
+	profile := humanizeFrom(ctx)
+
+	if err := elem.Focus(); err != nil {
+		return err
+	}
+
+	page := elem.Page().Context(ctx)
+	for _, chunk := range typingChunks(text, profile) {
+		if err := page.InsertText(chunk); err != nil {
+			return err
+		}
+
+		delay := profile.key.random()
+		if r, _ := utf8.DecodeLastRuneInString(chunk); strings.ContainsRune("，。！？；、,.!?;\n", r) {
+			delay += profile.punctuation.random()
+		}
+		if err := pause(ctx, delay); err != nil {
+			return err
+		}
+	}
+
+	// 与 rod 的 Input 一致，输入完成后触发 input 和 change 事件
+	return elem.Input("")
+}
+
+// typingChunks 按预设把文本拆成逐次输入的块，短文本每个字符一块
+func typingChunks(text string, profile humanizeProfile) []string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil
+	}
+
+	size := 1
+	if avg := (profile.key.min + profile.key.max) / 2; avg > 0 && profile.maxTyping > 0 {
+		if keys := int(profile.maxTyping / avg); keys > 0 && len(runes) > keys {
+			size = (len(runes) + keys - 1) / keys
+		}
+	}
+
+	chunks := make([]string, 0, (len(runes)+size-1)/size)
+	for i := 0; i < len(runes); i += size {
+		end := i + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[i:end]))
+	}
+	return chunks
+}
+
+// pause 可被取消的等待，返回未包装的 context 错误，由调用方统一分类
+func pause(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package xiaohongshu
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHumanizePreset(t *testing.T) {
+	p, err := ParseHumanizePreset(" Cautious ")
+	require.NoError(t, err)
+	assert.Equal(t, HumanizeCautious, p)
+
+	p, err = ParseHumanizePreset("")
+	require.NoError(t, err)
+	assert.Equal(t, HumanizePreset(""), p)
+
+	_, err = ParseHumanizePreset("slow")
+	assert.Error(t, err)
+}
+
+func TestHumanizeFromContext(t *testing.T) {
+	assert.Equal(t, humanizeProfiles[HumanizeNormal], humanizeFrom(context.Background()))
+
+	ctx := WithHumanize(context.Background(), HumanizeFast)
+	assert.Equal(t, humanizeProfiles[HumanizeFast], humanizeFrom(ctx))
+
+	// 空预设不覆盖外层 context 中的预设
+	assert.Equal(t, humanizeProfiles[HumanizeFast], humanizeFrom(WithHumanize(ctx, "")))
+}
+
+func TestTypingChunks(t *testing.T) {
+	profile := humanizeProfiles[HumanizeNormal]
+
+	assert.Equal(t, []string{"你", "好", "!"}, typingChunks("你好!", profile))
+	assert.Empty(t, typingChunks("", profile))
+
+	// 长文本按块输入，块数不超过 maxTyping 内能输入的字符数
+	long := strings.Repeat("字", 1000)
+	chunks := typingChunks(long, profile)
+	keys := int(profile.maxTyping / ((profile.key.min + profile.key.max) / 2))
+	assert.LessOrEqual(t, len(chunks), keys)
+	assert.Equal(t, long, strings.Join(chunks, ""))
+}
+
+func TestMousePath(t *testing.T) {
+	from, to := proto.Point{X: 10, Y: 20}, proto.Point{X: 300, Y: 400}
+
+	path := mousePath(from, to, 15)
+	require.Len(t, path, 15)
+	assert.Equal(t, to, path[len(path)-1])
+
+	for _, pt := range path {
+		// 轨迹的弯曲和抖动有限，不会偏离两点所在的区域太远
+		assert.InDelta(t, 155, pt.X, 300)
+		assert.InDelta(t, 210, pt.Y, 300)
+	}
+}
+
+func TestDurationRangeRandom(t *testing.T) {
+	r := humanizeProfiles[HumanizeCautious].step
+	for i := 0; i < 100; i++ {
+		d := r.random()
+		assert.GreaterOrEqual(t, d, r.min)
+		assert.Less(t, d, r.max)
+	}
+}
@@ -2,10 +2,23 @@ package xiaohongshu
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// loginPollInterval 等待扫码登录时检查登录状态的间隔
+	loginPollInterval = 2 * time.Second
+
+	// loginQRCodeTimeout 等待登录弹窗中二维码出现的时间
+	loginQRCodeTimeout = 30 * time.Second
 )
 
 type LoginAction struct {
@@ -16,42 +29,337 @@ func NewLogin(page *rod.Page) *LoginAction {
 	return &LoginAction{page: page}
 }
 
-func (a *LoginAction) CheckLoginStatus(ctx context.Context) (bool, error) {
+// LoginState 登录状态
+type LoginState string
+
+const (
+	// LoginStateLoggedIn 已登录
+	LoginStateLoggedIn LoginState = "logged_in"
+	// LoginStateLoggedOut 未登录：浏览器中没有登录 cookie
+	LoginStateLoggedOut LoginState = "logged_out"
+	// LoginStateExpired 登录已失效：浏览器中有登录 cookie，但页面仍为未登录状态
+	LoginStateExpired LoginState = "expired"
+	// LoginStateRiskControlled 被风控：页面跳转到安全验证、风控页面或弹出验证码，无法判断是否登录
+	LoginStateRiskControlled LoginState = "risk_controlled"
+)
+
+// sessionCookieName 小红书网页端的登录 cookie
+const sessionCookieName = "web_session"
+
+// LoginUser 当前登录的用户
+type LoginUser struct {
+	UserID   string `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar,omitempty"`
+	RedID    string `json:"red_id,omitempty"`
+	// 以下字段来自用户主页，只在 LoginStatusOptions.WithCounts 时获取
+	Follows     string `json:"follows,omitempty"`     // 关注数
+	Fans        string `json:"fans,omitempty"`        // 粉丝数
+	Interaction string `json:"interaction,omitempty"` // 获赞与收藏数
+}
+
+// LoginStatus 登录状态详情
+type LoginStatus struct {
+	State LoginState `json:"state"`
+	// Reason 未登录或被风控时的说明
+	Reason string     `json:"reason,omitempty"`
+	User   *LoginUser `json:"user,omitempty"`
+	// CookieExpiresAt 登录 cookie 的过期时间，没有登录 cookie 或为会话 cookie 时为空
+	CookieExpiresAt *time.Time `json:"cookie_expires_at,omitempty"`
+}
+
+// LoggedIn 是否已登录
+func (s *LoginStatus) LoggedIn() bool {
+	return s.State == LoginStateLoggedIn
+}
+
+// LoginStatusOptions 检查登录状态的选项
+type LoginStatusOptions struct {
+	// WithCounts 登录时再打开用户主页获取关注、粉丝和获赞与收藏数
+	WithCounts bool
+}
+
+// pageLoginState 首页 __INITIAL_STATE__.user 中的登录信息
+type pageLoginState struct {
+	LoggedIn bool `json:"loggedIn"`
+	UserInfo struct {
+		UserID   string `json:"userId"`
+		Nickname string `json:"nickname"`
+		Images   string `json:"images"`
+		Image    string `json:"image"`
+		RedID    string `json:"redId"`
+		Guest    bool   `json:"guest"`
+	} `json:"userInfo"`
+}
+
+// CheckLoginStatus 打开首页，根据页面状态判断登录状态并读取当前登录的用户
+// 遇到验证码或风控页面时返回 LoginStateRiskControlled，不等待人工处理
+func (a *LoginAction) CheckLoginStatus(ctx context.Context, opts LoginStatusOptions) (*LoginStatus, error) {
 	pp := a.page.Context(ctx)
-	pp.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
 
-	time.Sleep(1 * time.Second)
+	// 导航之前读取 cookie，页面可能会清除失效的登录 cookie
+	session, err := sessionCookie(pp)
+	if err != nil {
+		return nil, err
+	}
+	status := &LoginStatus{}
+	if session != nil && !session.Session {
+		expires := session.Expires.Time()
+		status.CookieExpiresAt = &expires
+	}
+
+	if err := navigate(pp, "https://www.xiaohongshu.com/explore"); err != nil {
+		return nil, err
+	}
+	if err := pp.WaitLoad(); err != nil {
+		return nil, classifyError(err, "等待页面加载", ErrNavigationTimeout)
+	}
+	if err := sleepContext(ctx, 1*time.Second); err != nil {
+		return nil, err
+	}
+
+	if blocked, err := riskControlReason(pp); err != nil {
+		return nil, err
+	} else if blocked != "" {
+		status.State = LoginStateRiskControlled
+		status.Reason = blocked
+		return status, nil
+	}
+
+	state, err := readPageLoginState(pp)
+	if err != nil {
+		return nil, err
+	}
+
+	loggedIn := state.LoggedIn || (state.UserInfo.UserID != "" && !state.UserInfo.Guest)
+	if !loggedIn {
+		// 页面状态结构变化时退回到检查登录后才显示的元素
+		_, loggedIn = findNamedNow(pp, SelectorLoginUserChannel, nil)
+	}
+
+	switch {
+	case loggedIn:
+		status.State = LoginStateLoggedIn
+	case session != nil:
+		status.State = LoginStateExpired
+		status.Reason = "浏览器中有登录 cookie，但页面为未登录状态，登录已失效"
+		return status, nil
+	default:
+		status.State = LoginStateLoggedOut
+		status.Reason = "浏览器中没有登录 cookie"
+		return status, nil
+	}
+
+	if state.UserInfo.UserID == "" {
+		// 通过页面元素判断为已登录，页面状态中没有用户信息
+		return status, nil
+	}
+
+	avatar := state.UserInfo.Images
+	if avatar == "" {
+		avatar = state.UserInfo.Image
+	}
+	status.User = &LoginUser{
+		UserID:   state.UserInfo.UserID,
+		Nickname: state.UserInfo.Nickname,
+		Avatar:   avatar,
+		RedID:    state.UserInfo.RedID,
+	}
+
+	if opts.WithCounts {
+		a.loadProfile(ctx, status)
+	}
+
+	return status, nil
+}
+
+// sessionCookie 浏览器中小红书的登录 cookie，不存在时返回 nil
+func sessionCookie(page *rod.Page) (*proto.NetworkCookie, error) {
+	cks, err := page.Browser().GetCookies()
+	if err != nil {
+		return nil, classifyError(err, "读取 cookies", ErrNavigationTimeout)
+	}
+	return SessionCookie(cks), nil
+}
+
+// SessionCookie 从浏览器 cookies 中找出小红书的登录 cookie，不存在时返回 nil
+func SessionCookie(cks []*proto.NetworkCookie) *proto.NetworkCookie {
+	for _, ck := range cks {
+		if ck.Name == sessionCookieName && strings.HasSuffix(ck.Domain, "xiaohongshu.com") && ck.Value != "" {
+			return ck
+		}
+	}
+	return nil
+}
+
+// riskControlReason 页面被重定向到验证码、风控页面或弹出验证码时返回原因，否则返回空字符串
+func riskControlReason(page *rod.Page) (string, error) {
+	v, err := DetectVerification(page)
+	if err != nil {
+		return "", err
+	}
+	if v != nil {
+		return v.Reason, nil
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return "", classifyError(err, "获取页面信息", ErrNavigationTimeout)
+	}
+	if err := blockedPageError(info.URL); errors.Is(err, ErrRateLimited) {
+		return err.Error(), nil
+	}
+	return "", nil
+}
 
-	exists, _, err := pp.Has(`.main-container .user .link-wrapper .channel`)
+// readPageLoginState 从 window.__INITIAL_STATE__.user 中读取登录信息
+func readPageLoginState(page *rod.Page) (*pageLoginState, error) {
+	result, err := evalString(page, `() => {
+		const state = window.__INITIAL_STATE__;
+		if (!state || !state.user) {
+			return "{}";
+		}
+		const unwrap = (v) => (v && v._value !== undefined) ? v._value : v;
+		return JSON.stringify({
+			loggedIn: !!unwrap(state.user.loggedIn),
+			userInfo: unwrap(state.user.userInfo) || {}
+		});
+	}`)
 	if err != nil {
-		return false, errors.Wrap(err, "check login status failed")
+		return nil, err
+	}
+
+	var state pageLoginState
+	if err := json.Unmarshal([]byte(result), &state); err != nil {
+		return nil, errors.Wrap(err, "解析登录状态失败")
 	}
+	return &state, nil
+}
 
-	if !exists {
-		return false, errors.Wrap(err, "login status element not found")
+// loadProfile 打开用户主页补充关注、粉丝和获赞与收藏数，失败时只记录日志
+// 主页出现验证时不等待人工处理，登录状态改为 LoginStateRiskControlled
+func (a *LoginAction) loadProfile(ctx context.Context, status *LoginStatus) {
+	user := status.User
+	profile, err := NewUserProfileAction(a.page).GetUserProfile(withoutVerificationHandler(ctx), user.UserID, "")
+	if errors.Is(err, ErrCaptchaRequired) {
+		status.State = LoginStateRiskControlled
+		status.Reason = err.Error()
+		return
+	}
+	if err != nil {
+		logrus.Warnf("获取当前用户 %s 的主页信息失败: %v", user.UserID, err)
+		return
 	}
 
-	return true, nil
+	if user.RedID == "" {
+		user.RedID = profile.UserBasicInfo.RedID
+	}
+	if user.Avatar == "" {
+		user.Avatar = profile.UserBasicInfo.Images
+	}
+	for _, interaction := range profile.Interactions {
+		switch interaction.Type {
+		case "follows":
+			user.Follows = interaction.Count
+		case "fans":
+			user.Fans = interaction.Count
+		case "interaction":
+			user.Interaction = interaction.Count
+		}
+	}
 }
 
 func (a *LoginAction) Login(ctx context.Context) error {
 	pp := a.page.Context(ctx)
 
 	// 导航到小红书首页，这会触发二维码弹窗
-	pp.MustNavigate("https://www.xiaohongshu.com/explore").MustWaitLoad()
+	if err := navigate(pp, "https://www.xiaohongshu.com/explore"); err != nil {
+		return err
+	}
+	if err := pp.WaitLoad(); err != nil {
+		return classifyError(err, "等待页面加载", ErrNavigationTimeout)
+	}
 
 	// 等待一小段时间让页面完全加载
-	time.Sleep(2 * time.Second)
+	if err := sleepContext(ctx, 2*time.Second); err != nil {
+		return err
+	}
 
 	// 检查是否已经登录
-	if exists, _, _ := pp.Has(".main-container .user .link-wrapper .channel"); exists {
+	if _, exists := findNamedNow(pp, SelectorLoginUserChannel, nil); exists {
 		// 已经登录，直接返回
 		return nil
 	}
 
 	// 等待扫码成功提示或者登录完成
 	// 这里我们等待登录成功的元素出现，这样更简单可靠
-	pp.MustElement(".main-container .user .link-wrapper .channel")
+	_, err := findNamed(pp, SelectorLoginUserChannel)
+	return err
+}
 
-	return nil
+// FetchQRCode 打开首页触发登录弹窗，返回登录二维码的 PNG 图片
+// 已经登录时返回 true 且不返回二维码；页面需要保持打开，之后在同一页面上调用 WaitForLogin 等待扫码
+func (a *LoginAction) FetchQRCode(ctx context.Context) ([]byte, bool, error) {
+	pp := a.page.Context(ctx)
+	if err := navigate(pp, "https://www.xiaohongshu.com/explore"); err != nil {
+		return nil, false, err
+	}
+	if err := pp.WaitLoad(); err != nil {
+		return nil, false, classifyError(err, "等待页面加载", ErrNavigationTimeout)
+	}
+
+	if err := sleepContext(ctx, 2*time.Second); err != nil {
+		return nil, false, err
+	}
+
+	if _, exists := findNamedNow(pp, SelectorLoginUserChannel, nil); exists {
+		return nil, true, nil
+	}
+
+	elem, err := findNamedWithin(pp, loginQRCodeTimeout, SelectorLoginQRCode, isVisible)
+	if err != nil {
+		return nil, false, err
+	}
+
+	png, err := qrCodeImage(elem)
+	if err != nil {
+		return nil, false, err
+	}
+	return png, false, nil
+}
+
+// qrCodeImage 读取二维码图片：src 为 base64 的 data URL 时直接解码，否则截取元素画面
+func qrCodeImage(elem *rod.Element) ([]byte, error) {
+	src, err := elem.Attribute("src")
+	if err == nil && src != nil {
+		if _, data, ok := strings.Cut(*src, ";base64,"); ok && strings.HasPrefix(*src, "data:image/png") {
+			if png, err := base64.StdEncoding.DecodeString(data); err == nil {
+				return png, nil
+			}
+		}
+	}
+
+	png, err := elem.Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
+	if err != nil {
+		return nil, classifyError(err, "截取登录二维码", ErrNavigationTimeout)
+	}
+	return png, nil
+}
+
+// WaitForLogin 在 FetchQRCode 打开的页面上等待扫码登录完成，超时仍未登录时返回 false
+func (a *LoginAction) WaitForLogin(ctx context.Context, timeout time.Duration) (bool, error) {
+	pp := a.page.Context(ctx)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if _, exists := findNamedNow(pp, SelectorLoginUserChannel, nil); exists {
+			return true, nil
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+		if err := sleepContext(ctx, loginPollInterval); err != nil {
+			return false, err
+		}
+	}
 }
@@ -0,0 +1,22 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionCookie(t *testing.T) {
+	session := &proto.NetworkCookie{Name: "web_session", Value: "abc", Domain: ".xiaohongshu.com"}
+	cks := []*proto.NetworkCookie{
+		{Name: "a1", Value: "x", Domain: ".xiaohongshu.com"},
+		{Name: "web_session", Value: "other", Domain: ".example.com"},
+		{Name: "web_session", Value: "", Domain: ".xiaohongshu.com"},
+		session,
+	}
+
+	assert.Same(t, session, SessionCookie(cks))
+	assert.Nil(t, SessionCookie(cks[:3]))
+	assert.Nil(t, SessionCookie(nil))
+}
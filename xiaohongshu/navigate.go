@@ -17,9 +17,13 @@ func NewNavigate(page *rod.Page) *NavigateAction {
 func (n *NavigateAction) ToExplorePage(ctx context.Context) error {
 	page := n.page.Context(ctx)
 
-	page.MustNavigate("https://www.xiaohongshu.com/explore").
-		MustWaitLoad().
-		MustElement(`div#app`)
+	if err := navigate(page, "https://www.xiaohongshu.com/explore"); err != nil {
+		return err
+	}
+	if err := waitLoad(page); err != nil {
+		return err
+	}
 
-	return nil
+	_, err := findElement(page, `div#app`)
+	return err
 }
@@ -0,0 +1,244 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxAPIResponseSize 单个接口响应的最大记录大小，超过的响应不记录
+	maxAPIResponseSize = 8 << 20
+
+	// maxAPIResponses 一次页面会话最多记录的接口响应数量，超过时丢弃最早的响应
+	maxAPIResponses = 500
+)
+
+// APIResponse 页面请求小红书网页端数据接口得到的 JSON 响应
+type APIResponse struct {
+	URL        string          `json:"url"`
+	Path       string          `json:"path"`
+	Status     int             `json:"status"`
+	Body       json.RawMessage `json:"body"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+// apiRecorder 通过 CDP Network 事件记录页面会话期间小红书数据接口（/api/sns/web/）的 JSON 响应
+// 只监听不拦截，不影响页面自身的请求；响应体在请求加载完成后通过 Network.getResponseBody 读取
+type apiRecorder struct {
+	page   *rod.Page
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	stopped   bool
+	pending   map[proto.NetworkRequestID]APIResponse
+	responses []APIResponse
+	received  map[string]int // 按路径统计收到的响应数量，不受记录上限影响
+	updated   chan struct{}  // 每记录一条响应关闭并替换，用于等待新响应
+}
+
+// recordAPI 开始记录页面的数据接口响应，应在导航之前调用，页面 context 结束或调用 stop 时停止记录
+func recordAPI(page *rod.Page) *apiRecorder {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	r := &apiRecorder{
+		page:     page.Context(ctx),
+		cancel:   cancel,
+		pending:  make(map[proto.NetworkRequestID]APIResponse),
+		received: make(map[string]int),
+		updated:  make(chan struct{}),
+	}
+
+	wait := r.page.EachEvent(
+		func(e *proto.NetworkResponseReceived) {
+			if e.Response == nil || !isAPIURL(e.Response.URL) || !strings.Contains(e.Response.MIMEType, "json") {
+				return
+			}
+
+			u, _ := url.Parse(e.Response.URL)
+			r.mu.Lock()
+			r.pending[e.RequestID] = APIResponse{
+				URL:    e.Response.URL,
+				Path:   u.Path,
+				Status: e.Response.Status,
+			}
+			r.mu.Unlock()
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			r.mu.Lock()
+			resp, ok := r.pending[e.RequestID]
+			delete(r.pending, e.RequestID)
+			if !ok || r.stopped || e.EncodedDataLength > maxAPIResponseSize {
+				r.mu.Unlock()
+				return
+			}
+			r.wg.Add(1)
+			r.mu.Unlock()
+
+			// 读取响应体需要调用 CDP，不能阻塞事件循环
+			go func() {
+				defer r.wg.Done()
+				r.fetchBody(e.RequestID, resp)
+			}()
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			r.mu.Lock()
+			delete(r.pending, e.RequestID)
+			r.mu.Unlock()
+		},
+	)
+	go wait()
+
+	return r
+}
+
+// fetchBody 读取响应体并记录
+func (r *apiRecorder) fetchBody(id proto.NetworkRequestID, resp APIResponse) {
+	body, err := proto.NetworkGetResponseBody{RequestID: id}.Call(r.page)
+	if err != nil {
+		logrus.Debugf("读取接口响应 %s 失败: %v", resp.Path, err)
+		return
+	}
+
+	data := []byte(body.Body)
+	if body.Base64Encoded {
+		if data, err = base64.StdEncoding.DecodeString(body.Body); err != nil {
+			return
+		}
+	}
+	if !json.Valid(data) {
+		return
+	}
+
+	resp.Body = data
+	resp.ReceivedAt = time.Now()
+	r.add(resp)
+}
+
+// add 记录一条响应并通知等待者
+func (r *apiRecorder) add(resp APIResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.responses) >= maxAPIResponses {
+		r.responses = r.responses[1:]
+	}
+	r.responses = append(r.responses, resp)
+	r.received[resp.Path]++
+
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// stop 停止记录，已记录的响应仍然可以读取
+func (r *apiRecorder) stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.cancel()
+	r.wg.Wait()
+}
+
+// list 路径为 path 的已记录响应，按接收顺序排列
+func (r *apiRecorder) list(path string) []APIResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var list []APIResponse
+	for _, resp := range r.responses {
+		if resp.Path == path {
+			list = append(list, resp)
+		}
+	}
+	return list
+}
+
+// count 收到的路径为 path 的响应数量
+func (r *apiRecorder) count(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received[path]
+}
+
+// waitFor 等待路径为 path 的响应数量超过 after，超时或 ctx 结束时返回 false
+func (r *apiRecorder) waitFor(ctx context.Context, path string, after int, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		r.mu.Lock()
+		updated := r.updated
+		n := r.received[path]
+		r.mu.Unlock()
+
+		if n > after {
+			return true
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// isAPIURL 判断是否为小红书网页端数据接口
+func isAPIURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(u.Hostname(), "xiaohongshu.com") && strings.HasPrefix(u.Path, "/api/sns/web/")
+}
+
+const (
+	// maxLoadMoreRounds 滚动加载更多数据的最大轮数
+	maxLoadMoreRounds = 30
+
+	// loadMoreWait 每次滚动后等待下一页接口响应的时间
+	loadMoreWait = 5 * time.Second
+
+	// maxIdleLoadRounds 连续多少轮滚动没有新的接口响应时认为已经没有更多数据
+	maxIdleLoadRounds = 2
+)
+
+// loadMore 反复调用 scroll 触发页面加载下一页，直到 enough 返回 true、
+// 连续 maxIdleLoadRounds 轮没有新的 path 接口响应或达到最大轮数
+func loadMore(ctx context.Context, api *apiRecorder, path string, scroll func() error, enough func() bool) error {
+	idle := 0
+	for round := 0; round < maxLoadMoreRounds && !enough(); round++ {
+		before := api.count(path)
+		if err := scroll(); err != nil {
+			return err
+		}
+
+		if api.waitFor(ctx, path, before, loadMoreWait) {
+			idle = 0
+		} else if idle++; idle >= maxIdleLoadRounds {
+			break
+		}
+
+		if err := humanPause(ctx); err != nil {
+			return err
+		}
+	}
+	return checkContext(ctx)
+}
+
+// scrollWindowToBottom 把页面滚动到底部，触发瀑布流加载下一页
+func scrollWindowToBottom(page *rod.Page) error {
+	_, err := page.Eval(`() => window.scrollTo(0, document.documentElement.scrollHeight)`)
+	return classifyError(err, "滚动页面", ErrNavigationTimeout)
+}
@@ -0,0 +1,106 @@
+package xiaohongshu
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+)
+
+// 以下是 rod Must* 方法的非 panic 版本，失败时返回 errors.go 中的类型化错误
+
+// navigate 导航到 url，之后应调用 wait* 等待页面加载
+func navigate(page *rod.Page, url string) error {
+	return classifyError(page.Navigate(url), "打开页面 "+url, ErrNavigationTimeout)
+}
+
+// waitLoad 等待页面 load 事件，并检查是否被重定向到登录、验证码或风控页面
+func waitLoad(page *rod.Page) error {
+	if err := page.WaitLoad(); err != nil {
+		return classifyError(err, "等待页面加载", ErrNavigationTimeout)
+	}
+	return checkPageBlocked(page)
+}
+
+// waitDOMStable 等待 DOM 稳定，并检查是否被重定向到登录、验证码或风控页面
+func waitDOMStable(page *rod.Page) error {
+	if err := page.WaitDOMStable(time.Second, 0); err != nil {
+		return classifyError(err, "等待页面稳定", ErrNavigationTimeout)
+	}
+	return checkPageBlocked(page)
+}
+
+// waitStable 等待页面完全稳定（网络、DOM、渲染），并检查是否被重定向到登录、验证码或风控页面
+func waitStable(page *rod.Page) error {
+	if err := page.WaitStable(time.Second); err != nil {
+		return classifyError(err, "等待页面稳定", ErrNavigationTimeout)
+	}
+	return checkPageBlocked(page)
+}
+
+// checkPageBlocked 根据当前 URL 判断页面是否被重定向到登录、验证码或风控页面
+// 遇到验证码时先交给验证处理函数等待人工处理，处理完成后再检查
+func checkPageBlocked(page *rod.Page) error {
+	if err := checkVerification(page); err != nil {
+		return err
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return classifyError(err, "获取页面信息", ErrNavigationTimeout)
+	}
+
+	return blockedPageError(info.URL)
+}
+
+// blockedPageError 识别小红书的验证码、风控和登录跳转页面
+// 只匹配域名和路径，查询参数中的搜索关键词或 redirect 地址不影响判断
+func blockedPageError(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.ToLower(u.Path)
+
+	switch {
+	case strings.Contains(host, "captcha") || strings.Contains(path, "captcha") || strings.Contains(path, "/website-login/verify"):
+		return errors.Wrapf(ErrCaptchaRequired, "当前页面 %s", rawURL)
+	case strings.Contains(path, "/website-login/error"):
+		return errors.Wrapf(ErrRateLimited, "当前页面 %s", rawURL)
+	case strings.Contains(path, "/login"):
+		return errors.Wrapf(ErrNotLoggedIn, "当前页面 %s", rawURL)
+	}
+
+	return nil
+}
+
+// evalString 执行页面脚本并返回字符串结果
+func evalString(page *rod.Page, js string, args ...interface{}) (string, error) {
+	result, err := page.Eval(js, args...)
+	if err != nil {
+		return "", classifyError(err, "执行页面脚本", ErrNavigationTimeout)
+	}
+	return result.Value.String(), nil
+}
+
+// findElement 等待并返回匹配 selector 的元素，超时返回 ErrElementNotFound
+func findElement(page *rod.Page, selector string) (*rod.Element, error) {
+	elem, err := page.Element(selector)
+	if err != nil {
+		return nil, classifyError(err, "查找元素", &ErrElementNotFound{Selector: selector})
+	}
+	return elem, nil
+}
+
+// clickElement 按请求的节奏预设模拟真人左键单击元素，见 humanClick
+func clickElement(elem *rod.Element) error {
+	return classifyError(humanClick(elem), "点击元素", ErrNavigationTimeout)
+}
+
+// inputText 按请求的节奏预设模拟真人向元素输入文本，见 humanType
+func inputText(elem *rod.Element, text string) error {
+	return classifyError(humanType(elem, text), "输入文本", ErrNavigationTimeout)
+}
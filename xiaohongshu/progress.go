@@ -0,0 +1,68 @@
+package xiaohongshu
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ProgressFunc 进度回调，progress 为当前已进行到的步骤，total 为总步骤数
+type ProgressFunc func(progress, total int, message string)
+
+type progressKey struct{}
+
+// WithProgress 在 context 中设置进度回调，长耗时的动作会在每个步骤开始时回调
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressTracker 按步骤上报动作进度，并在步骤边界检查 context 是否已取消
+type progressTracker struct {
+	ctx     context.Context
+	fn      ProgressFunc
+	total   int
+	current int
+}
+
+// newProgressTracker 创建进度上报器，context 中没有进度回调时只记录日志
+func newProgressTracker(ctx context.Context, total int) *progressTracker {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return &progressTracker{ctx: ctx, fn: fn, total: total}
+}
+
+// Step 进入下一个步骤，context 已取消时返回错误，调用方应立即停止
+func (p *progressTracker) Step(message string) error {
+	if err := checkContext(p.ctx); err != nil {
+		slog.Warn("动作已取消，停止执行", "step", message, "error", err)
+		return err
+	}
+
+	if p.current < p.total {
+		p.current++
+	}
+
+	slog.Info("执行步骤", "step", p.current, "total", p.total, "message", message)
+
+	if p.fn != nil {
+		p.fn(p.current, p.total, message)
+	}
+
+	return nil
+}
+
+// Done 上报最后一步完成，此时动作已经生效，不再检查取消
+func (p *progressTracker) Done(message string) {
+	p.current = p.total
+
+	slog.Info("执行步骤", "step", p.current, "total", p.total, "message", message)
+
+	if p.fn != nil {
+		p.fn(p.current, p.total, message)
+	}
+}
+
+// Skip 跳过一个步骤（例如未设置定时发布），保持总数不变
+func (p *progressTracker) Skip() {
+	if p.current < p.total {
+		p.current++
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 )
 
@@ -26,39 +25,51 @@ type PublishAction struct {
 
 const (
 	urlOfPublic = `https://creator.xiaohongshu.com/publish/publish?source=official`
+
+	// publishImageSteps 图文发布的步骤数：上传图片、输入标题、输入正文和话题、定时发布、提交、完成
+	publishImageSteps = 6
+
+	// publishTimeout 一次 Publish 的超时时间，从上传开始计时，等待人工验证时暂停
+	publishTimeout = 5 * time.Minute
 )
 
 func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 
-	pp := page.Timeout(60 * time.Second)
+	pp := withTimeout(page, 60*time.Second)
 
-	pp.MustNavigate(urlOfPublic)
+	if err := navigate(pp, urlOfPublic); err != nil {
+		return nil, err
+	}
 
-	pp.MustElement(`div.upload-content`).MustWaitVisible()
+	uploadContent, err := findNamed(pp, SelectorPublishUploadArea)
+	if err != nil {
+		// 创作中心未登录时会跳转到登录页，优先返回更明确的错误
+		if blockedErr := checkPageBlocked(pp); blockedErr != nil {
+			return nil, blockedErr
+		}
+		return nil, err
+	}
+	if err := uploadContent.WaitVisible(); err != nil {
+		return nil, classifyError(err, "等待上传区域可见", &ErrElementNotFound{Selector: SelectorPublishUploadArea})
+	}
 	slog.Info("wait for upload-content visible success")
 
 	// 等待一段时间确保页面完全加载
-	time.Sleep(1 * time.Second)
-
-	createElems := pp.MustElements("div.creator-tab")
-	slog.Info("foundcreator-tab elements", "count", len(createElems))
-	for _, elem := range createElems {
-		text, err := elem.Text()
-		if err != nil {
-			slog.Error("获取元素文本失败", "error", err)
-			continue
-		}
+	if err := humanPause(pp.GetContext()); err != nil {
+		return nil, err
+	}
 
-		if text == "上传图文" {
-			if err := elem.Click(proto.InputMouseButtonLeft, 1); err != nil {
-				slog.Error("点击元素失败", "error", err)
-				continue
-			}
-			break
+	if tab, ok := findNamedNow(pp, SelectorPublishImageTab, nil); ok {
+		if err := clickElement(tab); err != nil {
+			slog.Error("点击元素失败", "error", err)
 		}
+	} else {
+		slog.Warn("未找到'上传图文'标签")
 	}
 
-	time.Sleep(1 * time.Second)
+	if err := humanPause(pp.GetContext()); err != nil {
+		return nil, err
+	}
 
 	return &PublishAction{
 		page: pp,
@@ -70,34 +81,46 @@ func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent
 		return errors.New("图片不能为空")
 	}
 
-	page := p.page.Context(ctx)
+	page := withTimeout(p.page.Context(ctx), publishTimeout)
+	progress := newProgressTracker(ctx, publishImageSteps)
 
+	if err := progress.Step("上传图片"); err != nil {
+		return err
+	}
 	if err := uploadImages(page, content.ImagePaths); err != nil {
 		return errors.Wrap(err, "小红书上传图片失败")
 	}
 
-	if err := submitPublish(page, content.Title, content.Content, content.PublishTime); err != nil {
+	if err := submitPublish(page, progress, content.Title, content.Content, content.PublishTime); err != nil {
 		return errors.Wrap(err, "小红书发布失败")
 	}
 
+	progress.Done("发布完成")
 	return nil
 }
 
 func uploadImages(page *rod.Page, imagesPaths []string) error {
-	pp := page.Timeout(30 * time.Second)
+	pp := withTimeout(page, 30*time.Second)
 
 	slog.Info("开始上传图片", "paths", imagesPaths)
 
 	// 等待上传输入框出现
-	uploadInput := pp.MustElement(".upload-input")
+	uploadInput, err := findNamed(pp, SelectorPublishUploadInput)
+	if err != nil {
+		return err
+	}
 	slog.Info("找到上传输入框")
 
 	// 上传多个文件
-	uploadInput.MustSetFiles(imagesPaths...)
+	if err := uploadInput.SetFiles(imagesPaths); err != nil {
+		return classifyError(err, "设置上传文件", ErrNavigationTimeout)
+	}
 	slog.Info("文件已设置到上传输入框")
 
 	// 等待上传完成，增加等待时间
-	time.Sleep(5 * time.Second)
+	if err := sleepContext(pp.GetContext(), 5*time.Second); err != nil {
+		return err
+	}
 
 	// 检查是否有上传错误提示
 	if hasError, err := checkUploadErrors(pp); err != nil {
@@ -113,33 +136,18 @@ func uploadImages(page *rod.Page, imagesPaths []string) error {
 // checkUploadErrors 检查页面上是否有上传错误提示
 func checkUploadErrors(page *rod.Page) (bool, error) {
 	// 检查常见的错误提示元素
-	errorSelectors := []string{
-		".upload-error",
-		".error-message", 
-		".upload-failed",
-		"[class*='error']",
-		"[class*='fail']",
-	}
-
-	for _, selector := range errorSelectors {
-		exists, _, err := page.Has(selector)
-		if err != nil {
-			continue // 忽略选择器错误，继续检查下一个
-		}
-		if exists {
-			// 尝试获取错误信息
-			if elem, err := page.Element(selector); err == nil {
-				if text, err := elem.Text(); err == nil {
-					slog.Error("发现上传错误", "selector", selector, "message", text)
-					return true, nil
-				}
-			}
-			return true, nil
-		}
+	if elem, ok := findNamedNow(page, SelectorPublishUploadError, nil); ok {
+		text, _ := elem.Text()
+		slog.Error("发现上传错误", "message", text)
+		return true, nil
 	}
 
 	// 检查页面文本中是否包含失败相关的中文提示
-	pageText, err := page.MustElement("body").Text()
+	body, err := findElement(page, "body")
+	if err != nil {
+		return false, err
+	}
+	pageText, err := body.Text()
 	if err == nil {
 		failureKeywords := []string{"上传失败", "文件上传失败", "部分文件上传失败", "格式不支持", "文件过大"}
 		for _, keyword := range failureKeywords {
@@ -153,147 +161,170 @@ func checkUploadErrors(page *rod.Page) (bool, error) {
 	return false, nil
 }
 
-func submitPublish(page *rod.Page, title, content, publishTime string) error {
+func submitPublish(page *rod.Page, progress *progressTracker, title, content, publishTime string) error {
 
-	titleElem := page.MustElement("div.d-input input")
-	titleElem.MustInput(title)
+	if err := progress.Step("输入标题"); err != nil {
+		return err
+	}
+	titleElem, err := findNamed(page, SelectorPublishTitleInput)
+	if err != nil {
+		return err
+	}
+	if err := inputText(titleElem, title); err != nil {
+		return err
+	}
 
-	time.Sleep(1 * time.Second)
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
 
-	contentElem, ok := getContentElement(page)
-	if !ok {
-		return errors.New("没有找到内容输入框")
+	contentElem, err := getContentElement(page)
+	if err != nil {
+		return err
 	}
 
 	// 处理带话题的内容
+	if err := progress.Step("输入正文和话题"); err != nil {
+		return err
+	}
 	if err := inputContentWithTopics(page, contentElem, content); err != nil {
 		return errors.Wrap(err, "输入内容和话题失败")
 	}
 
-	time.Sleep(1 * time.Second)
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
 
 	// 如果提供了发布时间，设置定时发布
 	if publishTime != "" {
+		if err := progress.Step("设置定时发布"); err != nil {
+			return err
+		}
 		if err := setScheduledPublish(page, publishTime); err != nil {
 			return errors.Wrap(err, "设置定时发布失败")
 		}
+	} else {
+		progress.Skip()
 	}
 
-	submitButton := page.MustElement("div.submit div.d-button-content")
-	submitButton.MustClick()
-
-	time.Sleep(3 * time.Second)
+	if err := progress.Step("提交发布"); err != nil {
+		return err
+	}
+	submitButton, err := findNamed(page, SelectorPublishSubmit)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(submitButton); err != nil {
+		return err
+	}
 
-	return nil
+	// 等待发布请求完成
+	return sleepContext(page.GetContext(), 3*time.Second)
 }
 
 // setScheduledPublish 设置定时发布
 func setScheduledPublish(page *rod.Page, publishTime string) error {
 	slog.Info("设置定时发布", "publishTime", publishTime)
-	
+
 	// 查找并点击"定时发布"单选按钮
-	scheduledRadio, err := page.Element("span.el-radio__label")
+	scheduledRadio, err := findNamedWithin(page, 10*time.Second, SelectorPublishScheduleRadio, nil)
 	if err != nil {
-		return errors.Wrap(err, "未找到定时发布单选按钮")
-	}
-	
-	// 检查是否是"定时发布"标签
-	labelText, err := scheduledRadio.Text()
-	if err != nil || labelText != "定时发布" {
-		// 如果第一个不是，尝试查找所有的单选按钮标签
-		radioLabels, err := page.Elements("span.el-radio__label")
-		if err != nil {
-			return errors.Wrap(err, "未找到单选按钮标签")
-		}
-		
-		var foundScheduledRadio *rod.Element
-		for _, label := range radioLabels {
-			text, err := label.Text()
-			if err != nil {
-				continue
-			}
-			if text == "定时发布" {
-				foundScheduledRadio = label
-				break
-			}
-		}
-		
-		if foundScheduledRadio == nil {
-			return errors.New("未找到'定时发布'单选按钮")
-		}
-		scheduledRadio = foundScheduledRadio
+		return errors.Wrap(err, "未找到'定时发布'单选按钮")
 	}
-	
+
 	// 点击定时发布单选按钮
-	if err := scheduledRadio.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := clickElement(scheduledRadio); err != nil {
 		return errors.Wrap(err, "点击定时发布单选按钮失败")
 	}
-	
+
 	slog.Info("已点击定时发布单选按钮")
-	time.Sleep(1 * time.Second)
-	
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
+
 	// 查找时间输入框
-	timeInput, err := page.Element("input.el-input__inner[placeholder*='选择日期和时间']")
+	timeInput, err := findNamedWithin(page, 10*time.Second, SelectorPublishScheduleInput, nil)
 	if err != nil {
 		return errors.Wrap(err, "未找到时间输入框")
 	}
-	
+
 	// 点击时间输入框并输入时间
-	if err := timeInput.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := clickElement(timeInput); err != nil {
 		return errors.Wrap(err, "点击时间输入框失败")
 	}
-	
+
 	// 清空输入框并输入新时间
-	timeInput.MustSelectAllText()
-	timeInput.MustInput(publishTime)
-	
+	if err := timeInput.SelectAllText(); err != nil {
+		return errors.Wrap(err, "选中时间输入框文本失败")
+	}
+	if err := inputText(timeInput, publishTime); err != nil {
+		return err
+	}
+
 	slog.Info("已输入发布时间", "time", publishTime)
-	
+
 	// 点击输入框外部以确认时间选择
-	page.MustElement("body").MustClick()
-	time.Sleep(500 * time.Millisecond)
-	
-	return nil
+	body, err := findElement(page, "body")
+	if err != nil {
+		return err
+	}
+	if err := clickElement(body); err != nil {
+		return err
+	}
+
+	return humanPause(page.GetContext())
 }
 
 // inputContentWithTopics 输入内容并处理话题选择
 func inputContentWithTopics(page *rod.Page, contentElem *rod.Element, content string) error {
 	slog.Info("开始输入内容并处理话题", "content", content)
-	
+
 	// 点击内容框获得焦点
-	contentElem.MustClick()
-	
+	if err := clickElement(contentElem); err != nil {
+		return err
+	}
+
 	// 使用正则表达式找到所有话题
 	topicRegex := regexp.MustCompile(`#([^\s#]+)`)
 	topics := topicRegex.FindAllString(content, -1)
-	
+
 	// 移除原内容中的话题，得到纯文本
 	contentWithoutTopics := topicRegex.ReplaceAllString(content, "")
-	
+
 	// 先输入纯文本内容
 	slog.Info("输入纯文本内容", "content", contentWithoutTopics)
-	contentElem.MustInput(contentWithoutTopics)
-	
+	if err := inputText(contentElem, contentWithoutTopics); err != nil {
+		return err
+	}
+
 	// 如果有话题，在新行添加话题
 	if len(topics) > 0 {
 		// 添加两个换行符，创建空行分隔
-		contentElem.MustInput("\n\n")
-		
+		if err := inputText(contentElem, "\n\n"); err != nil {
+			return err
+		}
+
 		// 逐个添加话题
 		for i, topic := range topics {
 			slog.Info("输入话题", "topic", topic)
-			
+
 			// 如果不是第一个话题，添加空格分隔
 			if i > 0 {
-				contentElem.MustInput(" ")
+				if err := inputText(contentElem, " "); err != nil {
+					return err
+				}
 			}
-			
+
 			// 输入话题（包含#号）
-			contentElem.MustInput(topic)
-			
+			if err := inputText(contentElem, topic); err != nil {
+				return err
+			}
+
 			// 等待话题选择弹窗出现
-			time.Sleep(2 * time.Second)
-			
+			if err := sleepContext(page.GetContext(), 2*time.Second); err != nil {
+				return err
+			}
+
 			// 查找并点击话题选择容器中的第一个项目
 			if err := selectTopicFromPopup(page); err != nil {
 				slog.Warn("选择话题失败，继续处理", "topic", topic, "error", err)
@@ -301,134 +332,37 @@ func inputContentWithTopics(page *rod.Page, contentElem *rod.Element, content st
 			}
 		}
 	}
-	
+
 	slog.Info("内容和话题输入完成")
 	return nil
 }
 
 // selectTopicFromPopup 从话题选择弹窗中选择第一个话题
 func selectTopicFromPopup(page *rod.Page) error {
-	// 查找话题选择容器
-	containerSelector := "#creator-editor-topic-container"
-	
-	// 等待容器出现，设置较短的超时时间
-	container, err := page.Timeout(3 * time.Second).Element(containerSelector)
+	// 等待话题选择容器中的项目出现，设置较短的超时时间
+	selectedItem, err := findNamedWithin(page, 3*time.Second, SelectorPublishTopicItem, nil)
 	if err != nil {
-		return errors.Wrap(err, "话题选择容器未找到")
-	}
-	
-	// 查找第一个话题项目
-	itemSelectors := []string{
-		".item.is-selected", // 优先选择已选中的
-		".item:first-child", // 或者第一个项目
-		".item",             // 或者任意项目
-	}
-	
-	var selectedItem *rod.Element
-	for _, selector := range itemSelectors {
-		if item, err := container.Element(selector); err == nil {
-			selectedItem = item
-			break
-		}
+		return errors.Wrap(err, "未找到可选择的话题项目")
 	}
-	
-	if selectedItem == nil {
-		return errors.New("未找到可选择的话题项目")
-	}
-	
+
 	// 点击选择话题
-	if err := selectedItem.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := clickElement(selectedItem); err != nil {
 		return errors.Wrap(err, "点击话题项目失败")
 	}
-	
-	slog.Info("成功选择话题")
-	
-	// 等待弹窗消失
-	time.Sleep(500 * time.Millisecond)
-	
-	return nil
-}
-
-// 查找内容输入框 - 使用Race方法处理两种样式
-func getContentElement(page *rod.Page) (*rod.Element, bool) {
-	var foundElement *rod.Element
-	var found bool
-
-	page.Race().
-		Element("div.ql-editor").MustHandle(func(e *rod.Element) {
-		foundElement = e
-		found = true
-	}).
-		ElementFunc(func(page *rod.Page) (*rod.Element, error) {
-			return findTextboxByPlaceholder(page)
-		}).MustHandle(func(e *rod.Element) {
-		foundElement = e
-		found = true
-	}).
-		MustDo()
-
-	if found {
-		return foundElement, true
-	}
-
-	slog.Warn("no content element found by any method")
-	return nil, false
-}
-
-func findTextboxByPlaceholder(page *rod.Page) (*rod.Element, error) {
-	elements := page.MustElements("p")
-	if elements == nil {
-		return nil, errors.New("no p elements found")
-	}
 
-	// 查找包含指定placeholder的元素
-	placeholderElem := findPlaceholderElement(elements, "输入正文描述")
-	if placeholderElem == nil {
-		return nil, errors.New("no placeholder element found")
-	}
-
-	// 向上查找textbox父元素
-	textboxElem := findTextboxParent(placeholderElem)
-	if textboxElem == nil {
-		return nil, errors.New("no textbox parent found")
-	}
+	slog.Info("成功选择话题")
 
-	return textboxElem, nil
+	// 等待弹窗消失
+	return humanPause(page.GetContext())
 }
 
-func findPlaceholderElement(elements []*rod.Element, searchText string) *rod.Element {
-	for _, elem := range elements {
-		placeholder, err := elem.Attribute("data-placeholder")
-		if err != nil || placeholder == nil {
-			continue
-		}
-
-		if strings.Contains(*placeholder, searchText) {
-			return elem
-		}
+// getContentElement 查找内容输入框，兼容旧版 ql-editor 和新版 textbox 两种样式
+func getContentElement(page *rod.Page) (*rod.Element, error) {
+	elem, err := findNamed(page, SelectorPublishContentEditor)
+	if err != nil {
+		slog.Warn("no content element found by any method")
+		return nil, err
 	}
-	return nil
-}
-
-func findTextboxParent(elem *rod.Element) *rod.Element {
-	currentElem := elem
-	for i := 0; i < 5; i++ {
-		parent, err := currentElem.Parent()
-		if err != nil {
-			break
-		}
 
-		role, err := parent.Attribute("role")
-		if err != nil || role == nil {
-			currentElem = parent
-			continue
-		}
-
-		if *role == "textbox" {
-			return parent
-		}
-
-		currentElem = parent
-	}
-	return nil
+	return elem, nil
 }
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 )
 
@@ -26,46 +25,62 @@ type PublishArticleAction struct {
 
 const (
 	urlOfArticlePublish = `https://creator.xiaohongshu.com/publish/publish?source=official&from=menu&target=article`
+
+	// publishArticleSteps 长文发布的步骤数：标题、正文、一键排版、模板、下一步、图片、标签、定时发布、提交、完成
+	publishArticleSteps = 10
 )
 
 func NewPublishArticleAction(page *rod.Page) (*PublishArticleAction, error) {
-	pp := page.Timeout(60 * time.Second)
+	pp := withTimeout(page, 60*time.Second)
 
-	pp.MustNavigate(urlOfArticlePublish)
-	pp.MustWaitLoad()
+	if err := navigate(pp, urlOfArticlePublish); err != nil {
+		return nil, err
+	}
+	if err := waitLoad(pp); err != nil {
+		return nil, err
+	}
 
 	slog.Info("导航到文章发布页面，等待页面加载完成")
 
 	// 等待页面完全加载和渲染
-	time.Sleep(5 * time.Second)
+	if err := sleepContext(pp.GetContext(), 5*time.Second); err != nil {
+		return nil, err
+	}
 
-	// 使用正则表达式匹配包含"新的创作"的span元素
+	// 查找"新的创作"按钮，找不到时刷新页面重试一次
 	found := false
 	for attempt := 0; attempt < 2; attempt++ {
 		slog.Info("尝试查找'新的创作'按钮", "attempt", attempt+1)
-		
-		// 使用MustElementR正则匹配
-		elem, err := pp.Timeout(10*time.Second).ElementR("span", "新的创作")
-		
+
+		elem, err := findNamedWithin(pp, 10*time.Second, SelectorArticleNewCreation, nil)
+
 		if err != nil {
-			slog.Warn("使用正则查找失败", "error", err, "attempt", attempt+1)
+			slog.Warn("查找'新的创作'按钮失败", "error", err, "attempt", attempt+1)
 			if attempt == 0 {
 				slog.Info("第一次查找失败，刷新页面")
-				pp.MustReload()
-				pp.MustWaitLoad()
-				time.Sleep(5 * time.Second)
+				if err := pp.Reload(); err != nil {
+					return nil, classifyError(err, "刷新页面", ErrNavigationTimeout)
+				}
+				if err := waitLoad(pp); err != nil {
+					return nil, err
+				}
+				if err := sleepContext(pp.GetContext(), 5*time.Second); err != nil {
+					return nil, err
+				}
 			}
 			continue
 		}
-		
+
 		// 找到元素
 		text, _ := elem.Text()
 		slog.Info("找到'新的创作'按钮", "text", text)
 		found = true
-		
-		// 等待1秒后点击
-		time.Sleep(1 * time.Second)
-		if err := elem.Click(proto.InputMouseButtonLeft, 1); err != nil {
+
+		// 停顿后再点击
+		if err := humanPause(pp.GetContext()); err != nil {
+			return nil, err
+		}
+		if err := clickElement(elem); err != nil {
 			slog.Error("点击'新的创作'失败", "error", err)
 			return nil, errors.Wrap(err, "点击'新的创作'失败")
 		}
@@ -78,11 +93,19 @@ func NewPublishArticleAction(page *rod.Page) (*PublishArticleAction, error) {
 	}
 
 	// 等待页面加载，寻找creator-container
-	pp.MustElement(".creator-container").MustWaitVisible()
+	container, err := findNamed(pp, SelectorArticleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if err := container.WaitVisible(); err != nil {
+		return nil, classifyError(err, "等待编辑器可见", &ErrElementNotFound{Selector: SelectorArticleEditor})
+	}
 	slog.Info("文章编辑器加载成功")
 
 	// 额外等待确保页面完全加载
-	time.Sleep(1 * time.Second)
+	if err := humanPause(pp.GetContext()); err != nil {
+		return nil, err
+	}
 
 	return &PublishArticleAction{
 		page: pp,
@@ -94,67 +117,107 @@ func (p *PublishArticleAction) Publish(ctx context.Context, content PublishArtic
 		return errors.New("图片不能为空")
 	}
 
-	page := p.page.Context(ctx)
+	page := withTimeout(p.page.Context(ctx), publishTimeout)
+	progress := newProgressTracker(ctx, publishArticleSteps)
 
 	// 输入标题
+	if err := progress.Step("输入标题"); err != nil {
+		return err
+	}
 	if err := inputTitle(page, content.Title); err != nil {
 		return errors.Wrap(err, "输入标题失败")
 	}
 
 	// 输入正文内容
+	if err := progress.Step("输入正文"); err != nil {
+		return err
+	}
 	if err := inputMainContent(page, content.Content); err != nil {
 		return errors.Wrap(err, "输入正文内容失败")
 	}
 
 	// 点击一键排版
+	if err := progress.Step("一键排版"); err != nil {
+		return err
+	}
 	if err := clickAutoFormat(page); err != nil {
 		return errors.Wrap(err, "点击一键排版失败")
 	}
 
 	// 选择模板
+	if err := progress.Step("选择模板"); err != nil {
+		return err
+	}
 	if err := selectTemplate(page); err != nil {
 		return errors.Wrap(err, "选择模板失败")
 	}
 
 	// 点击下一步
+	if err := progress.Step("点击下一步"); err != nil {
+		return err
+	}
 	if err := clickNextStep(page); err != nil {
 		return errors.Wrap(err, "点击下一步失败")
 	}
 
 	// 上传图片
+	if err := progress.Step("上传图片"); err != nil {
+		return err
+	}
 	if err := uploadArticleImages(page, content.ImagePaths); err != nil {
 		return errors.Wrap(err, "上传图片失败")
 	}
 
 	// 输入标签
+	if err := progress.Step("输入标签"); err != nil {
+		return err
+	}
 	if err := inputTags(page, content.Tags); err != nil {
 		return errors.Wrap(err, "输入标签失败")
 	}
 
 	// 设置定时发布（如果提供）
 	if content.PublishTime != "" {
+		if err := progress.Step("设置定时发布"); err != nil {
+			return err
+		}
 		if err := setScheduledPublish(page, content.PublishTime); err != nil {
 			return errors.Wrap(err, "设置定时发布失败")
 		}
+	} else {
+		progress.Skip()
 	}
 
 	// 提交发布
+	if err := progress.Step("提交发布"); err != nil {
+		return err
+	}
 	if err := submitArticlePublish(page); err != nil {
 		return errors.Wrap(err, "提交发布失败")
 	}
 
+	progress.Done("发布完成")
 	return nil
 }
 
 // inputTitle 输入标题
 func inputTitle(page *rod.Page, title string) error {
 	slog.Info("开始输入标题", "title", title)
-	
-	titleInput := page.MustElement(`textarea.d-text[placeholder="输入标题"]`)
-	titleInput.MustClick()
-	titleInput.MustInput(title)
-	
-	time.Sleep(1 * time.Second)
+
+	titleInput, err := findNamed(page, SelectorArticleTitleInput)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(titleInput); err != nil {
+		return err
+	}
+	if err := inputText(titleInput, title); err != nil {
+		return err
+	}
+
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
 	slog.Info("标题输入完成")
 	return nil
 }
@@ -162,13 +225,22 @@ func inputTitle(page *rod.Page, title string) error {
 // inputMainContent 输入正文内容
 func inputMainContent(page *rod.Page, content string) error {
 	slog.Info("开始输入正文内容")
-	
+
 	// 查找可编辑的div
-	contentDiv := page.MustElement(`div.tiptap.ProseMirror[contenteditable="true"]`)
-	contentDiv.MustClick()
-	contentDiv.MustInput(content)
-	
-	time.Sleep(1 * time.Second)
+	contentDiv, err := findNamed(page, SelectorArticleContentEditor)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(contentDiv); err != nil {
+		return err
+	}
+	if err := inputText(contentDiv, content); err != nil {
+		return err
+	}
+
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
 	slog.Info("正文内容输入完成")
 	return nil
 }
@@ -176,130 +248,114 @@ func inputMainContent(page *rod.Page, content string) error {
 // clickAutoFormat 点击一键排版
 func clickAutoFormat(page *rod.Page) error {
 	slog.Info("点击一键排版")
-	
+
 	// 查找"一键排版"按钮
-	spans, err := page.Elements(`span.next-btn-text`)
+	button, err := findNamedWithin(page, 10*time.Second, SelectorArticleAutoFormat, nil)
 	if err != nil {
-		return errors.Wrap(err, "未找到一键排版按钮")
+		return errors.Wrap(err, "未找到'一键排版'按钮")
 	}
-	
-	for _, span := range spans {
-		text, err := span.Text()
-		if err != nil {
-			continue
-		}
-		if strings.Contains(text, "一键排版") {
-			if err := span.Click(proto.InputMouseButtonLeft, 1); err != nil {
-				return errors.Wrap(err, "点击一键排版失败")
-			}
-			slog.Info("已点击一键排版")
-			time.Sleep(5 * time.Second)
-			return nil
-		}
+	if err := clickElement(button); err != nil {
+		return errors.Wrap(err, "点击一键排版失败")
 	}
-	
-	return errors.New("未找到'一键排版'按钮")
+	slog.Info("已点击一键排版")
+
+	return sleepContext(page.GetContext(), 5*time.Second)
 }
 
 // selectTemplate 选择模板
 func selectTemplate(page *rod.Page) error {
 	slog.Info("开始选择模板")
-	
+
 	// 查找tab-panel
-	tabPanel := page.MustElement(".tab-panel")
+	tabPanel, err := findNamed(page, SelectorArticleTemplatePanel)
+	if err != nil {
+		return err
+	}
 	slog.Info("找到tab-panel")
-	
+
 	// 滚动tab-panel
 	slog.Info("开始滚动查找模板")
 	maxScrollAttempts := 10
 	for i := 0; i < maxScrollAttempts; i++ {
 		// 查找"轻感明快"模板
-		spans, err := tabPanel.Elements(`span.template-title`)
-		if err == nil {
-			for _, span := range spans {
-				text, err := span.Text()
-				if err != nil {
-					continue
-				}
-				if text == "轻感明快" {
-					slog.Info("找到'轻感明快'模板")
-					if err := span.Click(proto.InputMouseButtonLeft, 1); err != nil {
-						return errors.Wrap(err, "点击模板失败")
-					}
-					time.Sleep(5 * time.Second)
-					slog.Info("模板选择完成")
-					return nil
-				}
+		if template, ok := findNamedNow(page, SelectorArticleTemplate, nil); ok {
+			slog.Info("找到'轻感明快'模板")
+			if err := clickElement(template); err != nil {
+				return errors.Wrap(err, "点击模板失败")
+			}
+			if err := sleepContext(page.GetContext(), 5*time.Second); err != nil {
+				return err
 			}
+			slog.Info("模板选择完成")
+			return nil
 		}
-		
+
 		// 如果未找到，滚动600px
 		slog.Info("未找到模板，继续滚动", "attempt", i+1)
-		tabPanel.MustEval(`() => this.scrollTop += 600`)
-		time.Sleep(500 * time.Millisecond)
+		if _, err := tabPanel.Eval(`() => this.scrollTop += 600`); err != nil {
+			return errors.Wrap(err, "滚动模板列表失败")
+		}
+		if err := sleepContext(page.GetContext(), 500*time.Millisecond); err != nil {
+			return err
+		}
 	}
-	
+
 	return errors.New("未找到'轻感明快'模板")
 }
 
 // clickNextStep 点击下一步
 func clickNextStep(page *rod.Page) error {
 	slog.Info("点击下一步")
-	
-	spans, err := page.Elements(`span.d-text`)
+
+	button, err := findNamedWithin(page, 10*time.Second, SelectorArticleNextStep, nil)
 	if err != nil {
-		return errors.Wrap(err, "未找到下一步按钮")
+		return errors.Wrap(err, "未找到'下一步'按钮")
 	}
-	
-	for _, span := range spans {
-		text, err := span.Text()
-		if err != nil {
-			continue
-		}
-		if text == "下一步" {
-			if err := span.Click(proto.InputMouseButtonLeft, 1); err != nil {
-				return errors.Wrap(err, "点击下一步失败")
-			}
-			slog.Info("已点击下一步")
-			time.Sleep(5 * time.Second)
-			return nil
-		}
+	if err := clickElement(button); err != nil {
+		return errors.Wrap(err, "点击下一步失败")
 	}
-	
-	return errors.New("未找到'下一步'按钮")
+	slog.Info("已点击下一步")
+
+	return sleepContext(page.GetContext(), 5*time.Second)
 }
 
 // uploadArticleImages 上传图片
 func uploadArticleImages(page *rod.Page, imagePaths []string) error {
 	slog.Info("开始上传图片", "count", len(imagePaths))
-	
+
 	// 查找添加按钮（带有加号图标的div）
-	addButton, err := page.Element(`div.entry`)
+	addButton, err := findNamed(page, SelectorArticleImageEntry)
 	if err != nil {
 		return errors.Wrap(err, "未找到添加图片按钮")
 	}
-	
+
 	// 点击添加按钮
-	if err := addButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+	if err := clickElement(addButton); err != nil {
 		return errors.Wrap(err, "点击添加按钮失败")
 	}
-	
+
 	slog.Info("已点击添加按钮，等待上传控件")
-	time.Sleep(1 * time.Second)
-	
+	if err := sleepContext(page.GetContext(), 1*time.Second); err != nil {
+		return err
+	}
+
 	// 查找文件上传输入框
-	uploadInput, err := page.Element(`input[type="file"]`)
+	uploadInput, err := findNamed(page, SelectorArticleImageInput)
 	if err != nil {
 		return errors.Wrap(err, "未找到文件上传输入框")
 	}
-	
+
 	// 上传所有图片
-	uploadInput.MustSetFiles(imagePaths...)
+	if err := uploadInput.SetFiles(imagePaths); err != nil {
+		return errors.Wrap(err, "设置上传文件失败")
+	}
 	slog.Info("文件已设置到上传输入框")
-	
+
 	// 等待上传完成
-	time.Sleep(5 * time.Second)
-	
+	if err := sleepContext(page.GetContext(), 5*time.Second); err != nil {
+		return err
+	}
+
 	slog.Info("图片上传完成")
 	return nil
 }
@@ -310,45 +366,55 @@ func inputTags(page *rod.Page, tags []string) error {
 		slog.Info("没有标签需要输入")
 		return nil
 	}
-	
+
 	slog.Info("开始输入标签", "tags", tags)
-	
+
 	// 查找正文描述输入框
-	descDiv, err := page.Element(`div.tiptap.ProseMirror[contenteditable="true"][role="textbox"]`)
+	descDiv, err := findNamed(page, SelectorArticleTagEditor)
 	if err != nil {
 		return errors.Wrap(err, "未找到标签输入框")
 	}
-	
+
 	// 点击输入框获得焦点
-	descDiv.MustClick()
-	time.Sleep(500 * time.Millisecond)
-	
+	if err := clickElement(descDiv); err != nil {
+		return err
+	}
+	if err := humanPause(page.GetContext()); err != nil {
+		return err
+	}
+
 	// 逐个输入标签
 	for i, tag := range tags {
 		// 确保标签以#开头
 		if !strings.HasPrefix(tag, "#") {
 			tag = "#" + tag
 		}
-		
+
 		slog.Info("输入标签", "tag", tag)
-		
+
 		// 如果不是第一个标签，添加空格分隔
 		if i > 0 {
-			descDiv.MustInput(" ")
+			if err := inputText(descDiv, " "); err != nil {
+				return err
+			}
 		}
-		
+
 		// 输入标签
-		descDiv.MustInput(tag)
-		
+		if err := inputText(descDiv, tag); err != nil {
+			return err
+		}
+
 		// 等待话题选择弹窗出现
-		time.Sleep(2 * time.Second)
-		
+		if err := sleepContext(page.GetContext(), 2*time.Second); err != nil {
+			return err
+		}
+
 		// 尝试选择话题
 		if err := selectTopicFromPopup(page); err != nil {
 			slog.Warn("选择话题失败，继续处理", "tag", tag, "error", err)
 		}
 	}
-	
+
 	slog.Info("标签输入完成")
 	return nil
 }
@@ -356,13 +422,21 @@ func inputTags(page *rod.Page, tags []string) error {
 // submitArticlePublish 提交发布
 func submitArticlePublish(page *rod.Page) error {
 	slog.Info("提交发布")
-	
+
 	// 查找发布按钮
-	submitButton := page.MustElement("div.submit div.d-button-content")
-	submitButton.MustClick()
-	
-	time.Sleep(3 * time.Second)
+	submitButton, err := findNamed(page, SelectorArticleSubmit)
+	if err != nil {
+		return err
+	}
+	if err := clickElement(submitButton); err != nil {
+		return err
+	}
+
+	// 等待发布请求完成
+	if err := sleepContext(page.GetContext(), 3*time.Second); err != nil {
+		return err
+	}
 	slog.Info("发布完成")
-	
+
 	return nil
 }
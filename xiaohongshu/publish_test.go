@@ -14,10 +14,11 @@ func TestPublish(t *testing.T) {
 
 	t.Skip("SKIP: 测试发布")
 
-	b := browser.NewBrowser(false)
+	b, err := browser.Launch(false)
+	require.NoError(t, err)
 	defer b.Close()
 
-	page := b.NewPage()
+	page := b.MustNewPage()
 	defer page.Close()
 
 	action, err := NewPublishImageAction(page)
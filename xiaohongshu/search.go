@@ -21,31 +21,47 @@ type SearchAction struct {
 }
 
 func NewSearchAction(page *rod.Page) *SearchAction {
-	pp := page.Timeout(60 * time.Second)
+	pp := withTimeout(page, 60*time.Second)
 
 	return &SearchAction{page: pp}
 }
 
-func (s *SearchAction) Search(ctx context.Context, keyword string) ([]Feed, error) {
+// Search 搜索笔记
+// 除了 __INITIAL_STATE__ 中的首屏结果，还会合并页面通过搜索接口加载的结果；
+// limit 大于首屏数量时向下滚动页面加载更多，最多返回 limit 条，为 0 时只返回已经加载的结果
+func (s *SearchAction) Search(ctx context.Context, keyword string, limit int) ([]Feed, error) {
 	page := s.page.Context(ctx)
 
+	// 搜索结果由页面请求搜索接口加载，在导航之前开始记录
+	api := recordAPI(page)
+	defer api.stop()
+
 	searchURL := makeSearchURL(keyword)
-	page.MustNavigate(searchURL)
-	page.MustWaitStable()
+	if err := navigate(page, searchURL); err != nil {
+		return nil, err
+	}
+	if err := waitStable(page); err != nil {
+		return nil, err
+	}
 
-	page.MustWait(`() => window.__INITIAL_STATE__ !== undefined`)
+	if err := page.Wait(rod.Eval(`() => window.__INITIAL_STATE__ !== undefined`)); err != nil {
+		return nil, classifyError(err, "等待搜索结果", ErrNavigationTimeout)
+	}
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
 
 	// 获取 window.__INITIAL_STATE__.search.feeds._value 并转换为 JSON 字符串
 	// 直接提取 feeds 数组，避免 Vue.js 响应式对象的循环引用
-	result := page.MustEval(`() => {
+	result, err := evalString(page, `() => {
 			if (window.__INITIAL_STATE__ && window.__INITIAL_STATE__.search && window.__INITIAL_STATE__.search.feeds) {
 				return JSON.stringify(window.__INITIAL_STATE__.search.feeds._value);
 			}
 			return "[]";
-		}`).String()
-
-	if result == "[]" {
-		return []Feed{}, nil
+		}`)
+	if err != nil {
+		return nil, err
 	}
 
 	// 直接解析为 Feed 数组
@@ -54,6 +70,27 @@ func (s *SearchAction) Search(ctx context.Context, keyword string) ([]Feed, erro
 		return nil, fmt.Errorf("failed to unmarshal search feeds: %w", err)
 	}
 
+	hasMore := true
+	collect := func() bool {
+		var extra []Feed
+		extra, hasMore = collectAPIFeeds(api.list(apiSearchNotes))
+		feeds = mergeFeeds(feeds, extra)
+		return !hasMore || len(feeds) >= limit
+	}
+
+	if !collect() && limit > 0 {
+		scroll := func() error { return scrollWindowToBottom(withTimeout(page, loadMoreWait)) }
+		if err := loadMore(ctx, api, apiSearchNotes, scroll, collect); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(feeds) > limit {
+		feeds = feeds[:limit]
+	}
+	if feeds == nil {
+		feeds = []Feed{}
+	}
 	return feeds, nil
 }
 
@@ -13,15 +13,16 @@ func TestSearch(t *testing.T) {
 
 	t.Skip("SKIP: 测试发布")
 
-	b := browser.NewBrowser(false)
+	b, err := browser.Launch(false)
+	require.NoError(t, err)
 	defer b.Close()
 
-	page := b.NewPage()
+	page := b.MustNewPage()
 	defer page.Close()
 
 	action := NewSearchAction(page)
 
-	feeds, err := action.Search(context.Background(), "Kimi")
+	feeds, err := action.Search(context.Background(), "Kimi", 0)
 	require.NoError(t, err)
 	require.NotEmpty(t, feeds, "feeds should not be empty")
 
@@ -0,0 +1,396 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// SelectorCandidate 逻辑元素的一个候选定位方式
+// CSS 为 CSS 选择器；Text 不为空时为正则表达式，只匹配文本满足该正则的元素
+type SelectorCandidate struct {
+	CSS  string `json:"css" yaml:"css"`
+	Text string `json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+// UnmarshalYAML 支持在覆盖文件中直接用字符串表示只有 CSS 的候选
+func (c *SelectorCandidate) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.CSS = value.Value
+		c.Text = ""
+		return nil
+	}
+
+	type plain SelectorCandidate
+	return value.Decode((*plain)(c))
+}
+
+func (c SelectorCandidate) String() string {
+	if c.Text == "" {
+		return c.CSS
+	}
+	return fmt.Sprintf("%s /%s/", c.CSS, c.Text)
+}
+
+// SelectorStat 逻辑元素的匹配统计
+type SelectorStat struct {
+	Name       string          `json:"name"`
+	Overridden bool            `json:"overridden"`
+	Candidates []CandidateStat `json:"candidates"`
+	Misses     uint64          `json:"misses"`
+	// LastMatched 最近一次匹配的候选序号，从 0 开始，-1 表示还没有匹配过
+	LastMatched   int       `json:"last_matched"`
+	LastMatchedAt time.Time `json:"last_matched_at,omitempty"`
+}
+
+// CandidateStat 单个候选的匹配次数
+type CandidateStat struct {
+	SelectorCandidate
+	Hits uint64 `json:"hits"`
+}
+
+// selectorEntry 逻辑元素当前生效的候选列表和统计
+type selectorEntry struct {
+	candidates    []SelectorCandidate
+	overridden    bool
+	hits          []uint64
+	misses        uint64
+	lastMatched   int
+	lastMatchedAt time.Time
+}
+
+// SelectorRegistry 选择器注册表
+// 页面元素以逻辑名称注册，每个名称对应按优先级排列的候选；覆盖文件中的候选会替换同名元素的默认候选
+type SelectorRegistry struct {
+	mu           sync.RWMutex
+	defaults     map[string][]SelectorCandidate
+	entries      map[string]*selectorEntry
+	overridePath string
+}
+
+// newSelectorRegistry 使用默认候选创建注册表
+func newSelectorRegistry(defaults map[string][]SelectorCandidate) *SelectorRegistry {
+	r := &SelectorRegistry{defaults: defaults}
+	r.apply(nil)
+	return r
+}
+
+// selectors 全局选择器注册表
+var selectors = newSelectorRegistry(defaultSelectors)
+
+// LoadSelectorOverrides 从 YAML 或 JSON 文件加载选择器覆盖，文件内容为逻辑名称到候选列表的映射
+func LoadSelectorOverrides(path string) error {
+	return selectors.LoadFile(path)
+}
+
+// ReloadSelectorOverrides 重新加载上一次加载的覆盖文件，没有加载过覆盖文件时恢复默认候选
+func ReloadSelectorOverrides() error {
+	return selectors.Reload()
+}
+
+// SelectorStats 所有逻辑元素的匹配统计，用于发现备用候选开始生效的元素
+func SelectorStats() []SelectorStat {
+	return selectors.Stats()
+}
+
+// LoadFile 加载覆盖文件，覆盖会替换之前加载的覆盖并清空统计
+func (r *SelectorRegistry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "读取选择器覆盖文件失败")
+	}
+
+	overrides, err := parseSelectorOverrides(data)
+	if err != nil {
+		return errors.Wrapf(err, "解析选择器覆盖文件 %s 失败", path)
+	}
+
+	for name := range overrides {
+		if _, ok := r.defaults[name]; !ok {
+			logrus.Warnf("选择器覆盖文件中的 %s 不是已知的逻辑元素", name)
+		}
+	}
+
+	r.mu.Lock()
+	r.overridePath = path
+	r.mu.Unlock()
+
+	r.apply(overrides)
+	logrus.Infof("已加载选择器覆盖文件 %s，覆盖 %d 个元素", path, len(overrides))
+	return nil
+}
+
+// Reload 重新加载覆盖文件
+func (r *SelectorRegistry) Reload() error {
+	r.mu.RLock()
+	path := r.overridePath
+	r.mu.RUnlock()
+
+	if path == "" {
+		r.apply(nil)
+		return nil
+	}
+	return r.LoadFile(path)
+}
+
+// parseSelectorOverrides 解析覆盖文件，YAML 是 JSON 的超集，两种格式都用 YAML 解析
+func parseSelectorOverrides(data []byte) (map[string][]SelectorCandidate, error) {
+	var overrides map[string][]SelectorCandidate
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, err
+	}
+
+	for name, candidates := range overrides {
+		if err := validateSelectorCandidates(name, candidates); err != nil {
+			return nil, err
+		}
+	}
+
+	return overrides, nil
+}
+
+// validateSelectorCandidates 检查候选列表不为空、css 不为空且 text 是合法的正则
+func validateSelectorCandidates(name string, candidates []SelectorCandidate) error {
+	if len(candidates) == 0 {
+		return errors.Errorf("%s 没有候选", name)
+	}
+	for i, c := range candidates {
+		if strings.TrimSpace(c.CSS) == "" {
+			return errors.Errorf("%s 的第 %d 个候选缺少 css", name, i+1)
+		}
+		if c.Text != "" {
+			if _, err := regexp.Compile(c.Text); err != nil {
+				return errors.Wrapf(err, "%s 的第 %d 个候选 text 不是合法的正则", name, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// apply 以默认候选为基础应用覆盖，并重置统计
+func (r *SelectorRegistry) apply(overrides map[string][]SelectorCandidate) {
+	entries := make(map[string]*selectorEntry, len(r.defaults)+len(overrides))
+	for name, candidates := range r.defaults {
+		entries[name] = newSelectorEntry(candidates, false)
+	}
+	for name, candidates := range overrides {
+		entries[name] = newSelectorEntry(candidates, true)
+	}
+
+	r.mu.Lock()
+	r.entries = entries
+	r.mu.Unlock()
+}
+
+func newSelectorEntry(candidates []SelectorCandidate, overridden bool) *selectorEntry {
+	return &selectorEntry{
+		candidates:  candidates,
+		overridden:  overridden,
+		hits:        make([]uint64, len(candidates)),
+		lastMatched: -1,
+	}
+}
+
+// Candidates 逻辑元素当前生效的候选
+func (r *SelectorRegistry) Candidates(name string) []SelectorCandidate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil
+	}
+	return entry.candidates
+}
+
+// recordHit 记录第 index 个候选匹配成功，非首选候选匹配时打印告警
+func (r *SelectorRegistry) recordHit(name string, index int) {
+	r.mu.Lock()
+	entry, ok := r.entries[name]
+	if !ok || index < 0 || index >= len(entry.hits) {
+		r.mu.Unlock()
+		return
+	}
+	entry.hits[index]++
+	entry.lastMatched = index
+	entry.lastMatchedAt = time.Now()
+	candidate := entry.candidates[index]
+	r.mu.Unlock()
+
+	if index > 0 {
+		logrus.Warnf("选择器 %s 的首选候选未匹配，使用了第 %d 个候选: %s", name, index+1, candidate)
+	}
+}
+
+// recordMiss 记录所有候选都没有匹配
+func (r *SelectorRegistry) recordMiss(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[name]; ok {
+		entry.misses++
+	}
+}
+
+// Stats 所有逻辑元素的匹配统计，按名称排序
+func (r *SelectorRegistry) Stats() []SelectorStat {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make([]SelectorStat, 0, len(r.entries))
+	for name, entry := range r.entries {
+		stat := SelectorStat{
+			Name:          name,
+			Overridden:    entry.overridden,
+			Candidates:    make([]CandidateStat, len(entry.candidates)),
+			Misses:        entry.misses,
+			LastMatched:   entry.lastMatched,
+			LastMatchedAt: entry.lastMatchedAt,
+		}
+		for i, c := range entry.candidates {
+			stat.Candidates[i] = CandidateStat{SelectorCandidate: c, Hits: entry.hits[i]}
+		}
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// find 在页面上立即查找匹配该候选的元素，不等待；没有匹配时返回 rod.ElementNotFoundError
+// accept 不为空时只返回满足条件的元素
+func (c SelectorCandidate) find(page *rod.Page, accept func(*rod.Element) bool) (*rod.Element, error) {
+	page = page.Sleeper(rod.NotFoundSleeper)
+
+	if c.Text != "" {
+		elem, err := page.ElementR(c.CSS, c.Text)
+		if err != nil {
+			return nil, err
+		}
+		if accept != nil && !accept(elem) {
+			return nil, &rod.ElementNotFoundError{}
+		}
+		return elem, nil
+	}
+
+	if accept == nil {
+		return page.Element(c.CSS)
+	}
+
+	elems, err := page.Elements(c.CSS)
+	if err != nil {
+		return nil, err
+	}
+	for _, elem := range elems {
+		if accept(elem) {
+			return elem, nil
+		}
+	}
+	return nil, &rod.ElementNotFoundError{}
+}
+
+// findNamed 等待逻辑元素出现，按候选优先级返回第一个匹配的元素
+// 超时返回 ErrElementNotFound，Selector 为逻辑名称
+func findNamed(page *rod.Page, name string) (*rod.Element, error) {
+	return findNamedFunc(page, name, nil)
+}
+
+// findNamedFunc 与 findNamed 相同，但只返回满足 accept 的元素
+// 等待期间页面出现验证码时，交给验证处理函数等待人工处理，处理完成后继续等待元素
+func findNamedFunc(page *rod.Page, name string, accept func(*rod.Element) bool) (*rod.Element, error) {
+	candidates := selectors.Candidates(name)
+	if len(candidates) == 0 {
+		return nil, errors.Errorf("未注册的选择器: %s", name)
+	}
+
+	matched := -1
+	var verification *Verification
+	race := page.Race()
+	if name != SelectorRiskCaptcha {
+		// 验证码盖住页面时不能继续操作下面的元素，所以优先检查
+		race = race.ElementFunc(func(p *rod.Page) (*rod.Element, error) {
+			v, err := DetectVerification(p)
+			if err != nil || v == nil {
+				return nil, &rod.ElementNotFoundError{}
+			}
+			verification = v
+			return nil, errVerificationDetected
+		})
+	}
+	for i, c := range candidates {
+		i, c := i, c
+		race = race.ElementFunc(func(p *rod.Page) (*rod.Element, error) {
+			elem, err := c.find(p, accept)
+			if err != nil && !errors.Is(err, &rod.ElementNotFoundError{}) {
+				// 覆盖文件中的选择器写错时不中断其他候选，按未匹配处理
+				logrus.Debugf("选择器 %s 的候选 %s 查找失败: %v", name, c, err)
+				return nil, &rod.ElementNotFoundError{}
+			}
+			return elem, err
+		}).Handle(func(*rod.Element) error {
+			matched = i
+			return nil
+		})
+	}
+
+	for round := 0; ; round++ {
+		elem, err := race.Do()
+		if verification != nil {
+			v := verification
+			verification = nil
+			if round == maxVerificationRounds {
+				return nil, errors.Wrapf(ErrCaptchaRequired, "验证多次后仍未通过，当前页面 %s", v.URL)
+			}
+			if err := resolveVerification(page, v); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			selectors.recordMiss(name)
+			return nil, classifyError(err, "查找元素 "+name, &ErrElementNotFound{Selector: name})
+		}
+
+		selectors.recordHit(name, matched)
+		return elem, nil
+	}
+}
+
+// findNamedWithin 最多等待 timeout 查找逻辑元素，返回的元素不受该超时限制
+func findNamedWithin(page *rod.Page, timeout time.Duration, name string, accept func(*rod.Element) bool) (*rod.Element, error) {
+	elem, err := findNamedFunc(withTimeout(page, timeout), name, accept)
+	if err != nil {
+		return nil, err
+	}
+	return elem.Context(page.GetContext()), nil
+}
+
+// findNamedNow 立即按候选优先级查找逻辑元素，不等待，用于判断元素是否存在
+// 未找到不计入未匹配统计，因为元素不存在本身就是一种正常状态
+func findNamedNow(page *rod.Page, name string, accept func(*rod.Element) bool) (*rod.Element, bool) {
+	for i, c := range selectors.Candidates(name) {
+		elem, err := c.find(page, accept)
+		if err == nil {
+			selectors.recordHit(name, i)
+			return elem, true
+		}
+	}
+	return nil, false
+}
+
+// hasText 元素文本不为空
+func hasText(elem *rod.Element) bool {
+	text, err := elem.Text()
+	return err == nil && strings.TrimSpace(text) != ""
+}
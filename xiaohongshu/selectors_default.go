@@ -0,0 +1,255 @@
+package xiaohongshu
+
+// 逻辑元素名称，覆盖文件使用相同的名称
+const (
+	// 登录
+	SelectorLoginUserChannel = "login.user_channel"
+	SelectorLoginQRCode      = "login.qrcode"
+
+	// 风控：页面内弹出的验证码
+	SelectorRiskCaptcha = "risk.captcha"
+
+	// 笔记详情页：内容区和评论回复，用于滚动加载更多评论、展开回复
+	SelectorNoteScroller    = "detail.note_scroller"
+	SelectorCommentShowMore = "comment.show_more"
+
+	// 笔记详情页：评论
+	SelectorCommentTrigger = "comment.trigger"
+	SelectorCommentInput   = "comment.input"
+	SelectorCommentSubmit  = "comment.submit"
+
+	// 笔记详情页：点赞
+	SelectorLikeButton = "like.button"
+	SelectorLikeActive = "like.active"
+	SelectorLikeCount  = "like.count"
+
+	// 笔记详情页：收藏
+	SelectorCollectButton = "collect.button"
+	SelectorCollectActive = "collect.active"
+	SelectorCollectCount  = "collect.count"
+
+	// 创作中心：图文发布
+	SelectorPublishUploadArea    = "publish.upload_area"
+	SelectorPublishImageTab      = "publish.image_tab"
+	SelectorPublishUploadInput   = "publish.upload_input"
+	SelectorPublishUploadError   = "publish.upload_error"
+	SelectorPublishTitleInput    = "publish.title_input"
+	SelectorPublishContentEditor = "publish.content_editor"
+	SelectorPublishScheduleRadio = "publish.schedule_radio"
+	SelectorPublishScheduleInput = "publish.schedule_input"
+	SelectorPublishTopicItem     = "publish.topic_item"
+	SelectorPublishSubmit        = "publish.submit"
+
+	// 创作中心：长文发布
+	SelectorArticleNewCreation   = "article.new_creation"
+	SelectorArticleEditor        = "article.editor"
+	SelectorArticleTitleInput    = "article.title_input"
+	SelectorArticleContentEditor = "article.content_editor"
+	SelectorArticleAutoFormat    = "article.auto_format"
+	SelectorArticleTemplatePanel = "article.template_panel"
+	SelectorArticleTemplate      = "article.template"
+	SelectorArticleNextStep      = "article.next_step"
+	SelectorArticleImageEntry    = "article.image_entry"
+	SelectorArticleImageInput    = "article.image_input"
+	SelectorArticleTagEditor     = "article.tag_editor"
+	SelectorArticleSubmit        = "article.submit"
+)
+
+// css 只有 CSS 选择器的候选
+func css(selectors ...string) []SelectorCandidate {
+	candidates := make([]SelectorCandidate, len(selectors))
+	for i, s := range selectors {
+		candidates[i] = SelectorCandidate{CSS: s}
+	}
+	return candidates
+}
+
+// defaultSelectors 内置的选择器，按优先级排列，越靠前越精确
+var defaultSelectors = map[string][]SelectorCandidate{
+	SelectorLoginUserChannel: css(".main-container .user .link-wrapper .channel"),
+	SelectorLoginQRCode:      css(".login-container .qrcode-img", ".qrcode img", "img.qrcode-img"),
+
+	SelectorRiskCaptcha: append(css(
+		"#red-captcha",
+		".red-captcha",
+		"[class*='captcha-container']",
+		"[class*='captcha-slider']",
+	), SelectorCandidate{CSS: "div, span, p, h1, h2, h3", Text: `^\s*安全验证\s*$`}),
+
+	SelectorNoteScroller:    css(".note-scroller", "#noteContainer .interaction-container"),
+	SelectorCommentShowMore: css(".comments-container .show-more", ".parent-comment .show-more"),
+
+	SelectorCommentTrigger: css("div.input-box div.content-edit span"),
+	SelectorCommentInput:   css("div.input-box div.content-edit p.content-input"),
+	SelectorCommentSubmit:  css("div.bottom button.submit"),
+
+	SelectorLikeButton: append(css(
+		// 小红书特定的交互容器
+		".interact-container .like-wrapper",
+		".interact-container .like-btn",
+		".interact-container button[class*='like']",
+		".interact-container div[class*='like']",
+		// 基于交互区域的精确查找
+		".note-interact .like-wrapper",
+		".note-interact .like-btn",
+		".note-interact button:first-child",
+		".note-interact div:first-child",
+		".interact-info button:first-child",
+		".interact-info div:first-child",
+		// 小红书特定的点赞按钮
+		"button[class*='like-lottie']",
+		"button[class*='like']",
+		"div[class*='like-lottie']",
+		"div[class*='like']",
+		"span[class*='like-lottie']",
+		"span[class*='like']",
+		// 基于图标的查找
+		"button svg[class*='heart']",
+		"div svg[class*='heart']",
+		"span svg[class*='heart']",
+		// 通用选择器
+		".like-btn",
+		".like-button",
+		"[data-testid*='like']",
+	),
+		// 基于文本的查找（最后尝试，因为可能不稳定）
+		SelectorCandidate{CSS: "button", Text: "点赞"},
+		SelectorCandidate{CSS: "span", Text: "点赞"},
+	),
+	SelectorLikeActive: css(
+		".interact-container .like-wrapper.active",
+		".interact-container .like-btn.active",
+		".interact-container button[class*='like'][class*='active']",
+		".interact-container div[class*='like'][class*='active']",
+		".note-interact .like-wrapper.active",
+		".note-interact .like-btn.active",
+		".interact-info button[class*='active']:first-child",
+		".interact-info div[class*='active']:first-child",
+		"button[class*='like'][class*='active']",
+		"div[class*='like'][class*='active']",
+		"span[class*='like'][class*='active']",
+		".like-btn.active",
+		".like-button.active",
+	),
+	SelectorLikeCount: css(
+		".interact-container .like-wrapper .count",
+		".interact-container .like-btn .count",
+		".interact-container button[class*='like'] .count",
+		".interact-container div[class*='like'] .count",
+		".note-interact .like-wrapper .count",
+		".note-interact .like-btn .count",
+		".interact-info button:first-child .count",
+		".interact-info div:first-child .count",
+		".like-count",
+		".like-count span",
+		"[class*='like'][class*='count']",
+		"button[class*='like'] span",
+		"div[class*='like'] span",
+	),
+
+	SelectorCollectButton: append(css(
+		// 小红书特定的交互容器
+		".interact-container .collect-wrapper",
+		".interact-container .collect-btn",
+		".interact-container button[class*='collect']",
+		".interact-container div[class*='collect']",
+		// 基于交互区域的精确查找，收藏通常是第三个按钮
+		".note-interact .collect-wrapper",
+		".note-interact .collect-btn",
+		".note-interact button:nth-child(3)",
+		".note-interact div:nth-child(3)",
+		".interact-info button:nth-child(3)",
+		".interact-info div:nth-child(3)",
+		// 小红书特定的收藏按钮
+		"button[class*='collect-lottie']",
+		"button[class*='collect']",
+		"div[class*='collect-lottie']",
+		"div[class*='collect']",
+		"span[class*='collect-lottie']",
+		"span[class*='collect']",
+		// 基于图标的查找
+		"button svg[class*='star']",
+		"div svg[class*='star']",
+		"span svg[class*='star']",
+		"button svg[class*='bookmark']",
+		"div svg[class*='bookmark']",
+		"span svg[class*='bookmark']",
+		// 通用选择器
+		".collect-btn",
+		".collect-button",
+		"[data-testid*='collect']",
+		"[data-testid*='bookmark']",
+	),
+		// 基于文本的查找（最后尝试，因为可能不稳定）
+		SelectorCandidate{CSS: "button", Text: "收藏"},
+		SelectorCandidate{CSS: "span", Text: "收藏"},
+	),
+	SelectorCollectActive: css(
+		".interact-container .collect-wrapper.active",
+		".interact-container .collect-btn.active",
+		".interact-container button[class*='collect'][class*='active']",
+		".interact-container div[class*='collect'][class*='active']",
+		".note-interact .collect-wrapper.active",
+		".note-interact .collect-btn.active",
+		".interact-info button[class*='active']:nth-child(3)",
+		".interact-info div[class*='active']:nth-child(3)",
+		"button[class*='collect'][class*='active']",
+		"div[class*='collect'][class*='active']",
+		"span[class*='collect'][class*='active']",
+		".collect-btn.active",
+		".collect-button.active",
+	),
+	SelectorCollectCount: css(
+		".interact-container .collect-wrapper .count",
+		".interact-container .collect-btn .count",
+		".interact-container button[class*='collect'] .count",
+		".interact-container div[class*='collect'] .count",
+		".note-interact .collect-wrapper .count",
+		".note-interact .collect-btn .count",
+		".interact-info button:nth-child(3) .count",
+		".interact-info div:nth-child(3) .count",
+		".collect-count",
+		".collect-count span",
+		"[class*='collect'][class*='count']",
+		"button[class*='collect'] span",
+		"div[class*='collect'] span",
+	),
+
+	SelectorPublishUploadArea:  css("div.upload-content"),
+	SelectorPublishImageTab:    {{CSS: "div.creator-tab", Text: "^上传图文$"}},
+	SelectorPublishUploadInput: css(".upload-input"),
+	SelectorPublishUploadError: css(
+		".upload-error",
+		".error-message",
+		".upload-failed",
+		"[class*='error']",
+		"[class*='fail']",
+	),
+	SelectorPublishTitleInput: css("div.d-input input"),
+	SelectorPublishContentEditor: css(
+		"div.ql-editor",
+		// 新版编辑器：通过正文占位符定位 textbox
+		"[role='textbox']:has(p[data-placeholder*='输入正文描述'])",
+	),
+	SelectorPublishScheduleRadio: {{CSS: "span.el-radio__label", Text: "^定时发布$"}},
+	SelectorPublishScheduleInput: css("input.el-input__inner[placeholder*='选择日期和时间']"),
+	SelectorPublishTopicItem: css(
+		"#creator-editor-topic-container .item.is-selected",
+		"#creator-editor-topic-container .item:first-child",
+		"#creator-editor-topic-container .item",
+	),
+	SelectorPublishSubmit: css("div.submit div.d-button-content"),
+
+	SelectorArticleNewCreation:   {{CSS: "span", Text: "新的创作"}},
+	SelectorArticleEditor:        css(".creator-container"),
+	SelectorArticleTitleInput:    css(`textarea.d-text[placeholder="输入标题"]`),
+	SelectorArticleContentEditor: css(`div.tiptap.ProseMirror[contenteditable="true"]`),
+	SelectorArticleAutoFormat:    {{CSS: "span.next-btn-text", Text: "一键排版"}},
+	SelectorArticleTemplatePanel: css(".tab-panel"),
+	SelectorArticleTemplate:      {{CSS: ".tab-panel span.template-title", Text: "^轻感明快$"}},
+	SelectorArticleNextStep:      {{CSS: "span.d-text", Text: "^下一步$"}},
+	SelectorArticleImageEntry:    css("div.entry"),
+	SelectorArticleImageInput:    css(`input[type="file"]`),
+	SelectorArticleTagEditor:     css(`div.tiptap.ProseMirror[contenteditable="true"][role="textbox"]`),
+	SelectorArticleSubmit:        css("div.submit div.d-button-content"),
+}
@@ -0,0 +1,88 @@
+package xiaohongshu
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelectorOverrides(t *testing.T) {
+	overrides, err := parseSelectorOverrides([]byte(`
+like.button:
+  - ".new-like"
+  - css: button
+    text: 点赞
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []SelectorCandidate{
+		{CSS: ".new-like"},
+		{CSS: "button", Text: "点赞"},
+	}, overrides["like.button"])
+
+	// JSON 也可以直接解析
+	overrides, err = parseSelectorOverrides([]byte(`{"publish.submit": [{"css": "button.publish"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []SelectorCandidate{{CSS: "button.publish"}}, overrides["publish.submit"])
+
+	_, err = parseSelectorOverrides([]byte(`like.button: []`))
+	assert.Error(t, err)
+
+	_, err = parseSelectorOverrides([]byte(`like.button: [{css: button, text: "("}]`))
+	assert.Error(t, err)
+}
+
+func TestSelectorRegistryOverrides(t *testing.T) {
+	r := newSelectorRegistry(map[string][]SelectorCandidate{
+		"a": css(".a1", ".a2"),
+		"b": css(".b1"),
+	})
+
+	r.recordHit("a", 1)
+	r.recordMiss("b")
+
+	path := filepath.Join(t.TempDir(), "selectors.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("b:\n  - .b2\n"), 0644))
+	require.NoError(t, r.LoadFile(path))
+
+	assert.Equal(t, css(".a1", ".a2"), r.Candidates("a"))
+	assert.Equal(t, css(".b2"), r.Candidates("b"))
+
+	stats := r.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "a", stats[0].Name)
+	assert.False(t, stats[0].Overridden)
+	assert.Equal(t, -1, stats[0].LastMatched, "加载覆盖文件后统计重置")
+	assert.True(t, stats[1].Overridden)
+
+	// 修改覆盖文件后重新加载
+	require.NoError(t, os.WriteFile(path, []byte("b:\n  - .b3\n"), 0644))
+	require.NoError(t, r.Reload())
+	assert.Equal(t, css(".b3"), r.Candidates("b"))
+}
+
+func TestSelectorRegistryStats(t *testing.T) {
+	r := newSelectorRegistry(map[string][]SelectorCandidate{
+		"a": css(".a1", ".a2"),
+	})
+
+	r.recordHit("a", 0)
+	r.recordHit("a", 1)
+	r.recordHit("a", 1)
+	r.recordMiss("a")
+
+	stats := r.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, uint64(1), stats[0].Candidates[0].Hits)
+	assert.Equal(t, uint64(2), stats[0].Candidates[1].Hits)
+	assert.Equal(t, uint64(1), stats[0].Misses)
+	assert.Equal(t, 1, stats[0].LastMatched)
+}
+
+func TestDefaultSelectorsValid(t *testing.T) {
+	for name, candidates := range defaultSelectors {
+		assert.NoError(t, validateSelectorCandidates(name, candidates))
+	}
+}
@@ -0,0 +1,152 @@
+package xiaohongshu
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// pausableTimeout 可以暂停计时的超时 context
+// 遇到验证码等待人工处理时，动作的超时暂停计时，人工处理完成后继续计时，避免动作在等待期间超时
+type pausableTimeout struct {
+	context.Context
+
+	done chan struct{}
+
+	mu        sync.Mutex
+	err       error
+	timer     *time.Timer
+	remaining time.Duration
+	deadline  time.Time
+	paused    int
+}
+
+func newPausableTimeout(parent context.Context, d time.Duration) *pausableTimeout {
+	c := &pausableTimeout{
+		Context:  parent,
+		done:     make(chan struct{}),
+		deadline: time.Now().Add(d),
+	}
+	// 持有锁再创建定时器，很短的超时在 c.timer 赋值之前触发时 finish 会等待赋值完成
+	c.mu.Lock()
+	c.timer = time.AfterFunc(d, func() { c.finish(context.DeadlineExceeded) })
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-parent.Done():
+			c.finish(parent.Err())
+		case <-c.done:
+		}
+	}()
+
+	return c
+}
+
+func (c *pausableTimeout) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *pausableTimeout) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Deadline 暂停期间没有确定的截止时间，只返回上层 context 的截止时间
+func (c *pausableTimeout) Deadline() (time.Time, bool) {
+	return c.Context.Deadline()
+}
+
+func (c *pausableTimeout) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	c.timer.Stop()
+	close(c.done)
+}
+
+// pause 暂停计时，可以嵌套调用，每次 pause 对应一次 resume
+func (c *pausableTimeout) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.paused++
+	if c.paused == 1 && c.timer.Stop() {
+		c.remaining = time.Until(c.deadline)
+	}
+}
+
+// resume 恢复计时，剩余时间从暂停时开始计算
+func (c *pausableTimeout) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil || c.paused == 0 {
+		return
+	}
+	c.paused--
+	if c.paused == 0 {
+		c.deadline = time.Now().Add(c.remaining)
+		c.timer = time.AfterFunc(c.remaining, func() { c.finish(context.DeadlineExceeded) })
+	}
+}
+
+// pageTimeouts 按页面记录尚未结束的超时，用于暂停页面上正在进行的动作的所有超时
+var pageTimeouts = struct {
+	sync.Mutex
+	m map[proto.TargetTargetID]map[*pausableTimeout]struct{}
+}{m: make(map[proto.TargetTargetID]map[*pausableTimeout]struct{})}
+
+// withTimeout 与 rod 的 page.Timeout 相同，但超时可以在等待人工验证时暂停
+func withTimeout(page *rod.Page, d time.Duration) *rod.Page {
+	c := newPausableTimeout(page.GetContext(), d)
+	id := page.TargetID
+
+	pageTimeouts.Lock()
+	if pageTimeouts.m[id] == nil {
+		pageTimeouts.m[id] = make(map[*pausableTimeout]struct{})
+	}
+	pageTimeouts.m[id][c] = struct{}{}
+	pageTimeouts.Unlock()
+
+	go func() {
+		<-c.done
+
+		pageTimeouts.Lock()
+		delete(pageTimeouts.m[id], c)
+		if len(pageTimeouts.m[id]) == 0 {
+			delete(pageTimeouts.m, id)
+		}
+		pageTimeouts.Unlock()
+	}()
+
+	return page.Context(c)
+}
+
+// pausePageTimeouts 暂停页面上所有未结束的超时，返回恢复计时的函数
+func pausePageTimeouts(page *rod.Page) func() {
+	pageTimeouts.Lock()
+	var paused []*pausableTimeout
+	for c := range pageTimeouts.m[page.TargetID] {
+		c.pause()
+		paused = append(paused, c)
+	}
+	pageTimeouts.Unlock()
+
+	return func() {
+		for _, c := range paused {
+			c.resume()
+		}
+	}
+}
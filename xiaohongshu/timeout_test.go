@@ -0,0 +1,56 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPausableTimeoutExpires(t *testing.T) {
+	c := newPausableTimeout(context.Background(), 20*time.Millisecond)
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("超时没有结束")
+	}
+	assert.ErrorIs(t, c.Err(), context.DeadlineExceeded)
+}
+
+func TestPausableTimeoutPause(t *testing.T) {
+	c := newPausableTimeout(context.Background(), 50*time.Millisecond)
+
+	c.pause()
+	c.pause()
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, c.Err(), "暂停期间不计时")
+
+	c.resume()
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, c.Err(), "嵌套暂停全部恢复后才继续计时")
+
+	c.resume()
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("恢复计时后没有超时")
+	}
+	assert.ErrorIs(t, c.Err(), context.DeadlineExceeded)
+}
+
+func TestPausableTimeoutParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	c := newPausableTimeout(parent, time.Minute)
+
+	c.pause()
+	cancel()
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("上层取消后没有结束")
+	}
+	assert.ErrorIs(t, c.Err(), context.Canceled)
+}
@@ -136,3 +136,30 @@ type Comment struct {
 	SubComments     []Comment `json:"subComments"`
 	ShowTags        []string  `json:"showTags"`
 }
+
+// ================ 用户主页相关结构体 ================
+
+// UserProfileResponse 表示用户主页数据
+type UserProfileResponse struct {
+	UserBasicInfo UserBasicInfo      `json:"userBasicInfo"`
+	Interactions  []UserInteractions `json:"interactions"`
+	Feeds         []Feed             `json:"feeds"`
+}
+
+// UserBasicInfo 表示用户主页的基本信息
+type UserBasicInfo struct {
+	Nickname   string `json:"nickname"`
+	RedID      string `json:"redId"`
+	Desc       string `json:"desc"`
+	Gender     int    `json:"gender"`
+	IPLocation string `json:"ipLocation"`
+	Images     string `json:"images"`
+	Imageb     string `json:"imageb"`
+}
+
+// UserInteractions 表示用户的关注、粉丝、获赞与收藏数
+type UserInteractions struct {
+	Type  string `json:"type"` // follows, fans, interaction
+	Name  string `json:"name"`
+	Count string `json:"count"`
+}
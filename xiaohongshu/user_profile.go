@@ -0,0 +1,80 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// UserProfileAction 表示用户主页动作
+type UserProfileAction struct {
+	page *rod.Page
+}
+
+// NewUserProfileAction 创建用户主页动作
+func NewUserProfileAction(page *rod.Page) *UserProfileAction {
+	return &UserProfileAction{page: page}
+}
+
+// GetUserProfile 获取用户主页的基本信息、互动数据和笔记列表
+func (u *UserProfileAction) GetUserProfile(ctx context.Context, userID, xsecToken string) (*UserProfileResponse, error) {
+	page := withTimeout(u.page.Context(ctx), 60*time.Second)
+
+	if err := navigate(page, makeUserProfileURL(userID, xsecToken)); err != nil {
+		return nil, err
+	}
+	if err := waitDOMStable(page); err != nil {
+		return nil, err
+	}
+	if err := sleepContext(ctx, 1*time.Second); err != nil {
+		return nil, err
+	}
+
+	// 从 window.__INITIAL_STATE__.user 中提取主页数据
+	// notes 为按 tab 分组的二维数组，第一组为用户发布的笔记
+	result, err := evalString(page, `() => {
+		const state = window.__INITIAL_STATE__;
+		if (!state || !state.user || !state.user.userPageData) {
+			return "";
+		}
+		const unwrap = (v) => (v && v._value !== undefined) ? v._value : v;
+		const pageData = unwrap(state.user.userPageData) || {};
+		const notes = unwrap(state.user.notes) || [];
+		return JSON.stringify({
+			userBasicInfo: pageData.basicInfo || {},
+			interactions: pageData.interactions || [],
+			feeds: notes[0] || []
+		});
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == "" {
+		return nil, fmt.Errorf("user profile not found for userID: %s", userID)
+	}
+
+	var response UserProfileResponse
+	if err := json.Unmarshal([]byte(result), &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user profile: %w", err)
+	}
+
+	return &response, nil
+}
+
+func makeUserProfileURL(userID, xsecToken string) string {
+	profileURL := fmt.Sprintf("https://www.xiaohongshu.com/user/profile/%s", url.PathEscape(userID))
+	if xsecToken == "" {
+		return profileURL
+	}
+
+	values := url.Values{}
+	values.Set("xsec_token", xsecToken)
+	values.Set("xsec_source", "pc_note")
+
+	return profileURL + "?" + values.Encode()
+}
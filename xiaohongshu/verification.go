@@ -0,0 +1,117 @@
+package xiaohongshu
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxVerificationRounds 一次检查中最多连续处理的验证次数，避免验证反复出现时无限等待
+const maxVerificationRounds = 3
+
+// errVerificationDetected 查找元素时发现验证码，用于中断等待
+var errVerificationDetected = errors.New("页面出现验证")
+
+// Verification 页面上出现的验证码或安全验证
+type Verification struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// VerificationHandler 遇到验证时的处理函数，通常是等待人工在浏览器中完成验证
+// 返回 nil 表示验证已完成，动作继续执行；返回错误时动作以该错误结束
+// 等待期间动作的超时暂停计时，ctx 只会因请求取消而结束
+type VerificationHandler func(ctx context.Context, page *rod.Page, v Verification) error
+
+var (
+	verificationHandlerMu sync.RWMutex
+	verificationHandler   VerificationHandler
+)
+
+// SetVerificationHandler 设置遇到验证时的处理函数，未设置时直接返回 ErrCaptchaRequired
+func SetVerificationHandler(h VerificationHandler) {
+	verificationHandlerMu.Lock()
+	defer verificationHandlerMu.Unlock()
+	verificationHandler = h
+}
+
+// skipVerificationKey context 中标记不等待人工处理验证
+type skipVerificationKey struct{}
+
+// withoutVerificationHandler 返回的 context 中遇到验证时不调用处理函数，直接返回 ErrCaptchaRequired
+func withoutVerificationHandler(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipVerificationKey{}, true)
+}
+
+func getVerificationHandler() VerificationHandler {
+	verificationHandlerMu.RLock()
+	defer verificationHandlerMu.RUnlock()
+	return verificationHandler
+}
+
+// DetectVerification 立即检查页面是否处于验证状态：被重定向到安全验证页面，或页面上弹出了滑块等验证码
+// 没有验证时返回 nil
+func DetectVerification(page *rod.Page) (*Verification, error) {
+	info, err := page.Info()
+	if err != nil {
+		return nil, classifyError(err, "获取页面信息", ErrNavigationTimeout)
+	}
+
+	if errors.Is(blockedPageError(info.URL), ErrCaptchaRequired) {
+		return &Verification{URL: info.URL, Reason: "页面跳转到安全验证"}, nil
+	}
+	if _, ok := findNamedNow(page, SelectorRiskCaptcha, isVisible); ok {
+		return &Verification{URL: info.URL, Reason: "页面弹出验证码"}, nil
+	}
+	return nil, nil
+}
+
+// resolveVerification 交给处理函数等待验证完成，等待期间暂停页面上所有动作超时
+func resolveVerification(page *rod.Page, v *Verification) error {
+	captchaErr := errors.Wrapf(ErrCaptchaRequired, "%s，当前页面 %s", v.Reason, v.URL)
+
+	h := getVerificationHandler()
+	if h == nil || page.GetContext().Value(skipVerificationKey{}) != nil {
+		return captchaErr
+	}
+
+	logrus.Warnf("检测到验证（%s），等待人工处理: %s", v.Reason, v.URL)
+
+	resume := pausePageTimeouts(page)
+	defer resume()
+
+	if err := h(page.GetContext(), page, *v); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return errors.Wrap(err, "动作已取消")
+		}
+		return errors.Wrap(err, captchaErr.Error())
+	}
+
+	logrus.Infof("验证已完成，继续执行动作")
+	return nil
+}
+
+// checkVerification 检查页面是否处于验证状态，是则等待处理完成，多次处理后仍然处于验证状态时返回 ErrCaptchaRequired
+func checkVerification(page *rod.Page) error {
+	for round := 0; ; round++ {
+		v, err := DetectVerification(page)
+		if err != nil || v == nil {
+			return err
+		}
+		if round == maxVerificationRounds {
+			return errors.Wrapf(ErrCaptchaRequired, "验证多次后仍未通过，当前页面 %s", v.URL)
+		}
+		if err := resolveVerification(page, v); err != nil {
+			return err
+		}
+	}
+}
+
+// isVisible 元素可见
+func isVisible(elem *rod.Element) bool {
+	visible, err := elem.Visible()
+	return err == nil && visible
+}